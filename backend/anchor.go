@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+
+	"weather-backend/merkle"
+)
+
+const (
+	anchorModeSubmission = "submission"
+	anchorModeBatch      = "batch"
+)
+
+var anchorDeviceID [32]byte
+
+type PendingAnchor struct {
+	SubmissionID uint64 `json:"submission_id"`
+	DeviceID     string `json:"device_id"`
+	DataHash     string `json:"data_hash"`
+}
+
+type AnchorBatch struct {
+	ID            uint64    `json:"id"`
+	Root          string    `json:"root"`
+	DataHashes    []string  `json:"data_hashes"`
+	SubmissionIDs []uint64  `json:"submission_ids"`
+	IPFSHash      string    `json:"ipfs_hash"`
+	TxJobID       uint64    `json:"tx_job_id,omitempty"`
+	TxHash        string    `json:"tx_hash,omitempty"`
+	EntryID       string    `json:"entry_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	AnchoredAt    time.Time `json:"anchored_at,omitempty"`
+}
+
+func (s *WeatherService) runAnchoring(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.Config.AnchorWindow) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.anchorPendingBatch(); err != nil {
+				log.Printf("Failed to anchor batch: %v", err)
+			}
+		}
+	}
+}
+
+func (s *WeatherService) anchorPendingBatch() error {
+	pending, err := s.Store.ListPendingAnchors()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	batch := &AnchorBatch{
+		DataHashes:    make([]string, len(pending)),
+		SubmissionIDs: make([]uint64, len(pending)),
+		CreatedAt:     time.Now(),
+	}
+
+	leaves := make([][32]byte, len(pending))
+	for i, anchor := range pending {
+		leaves[i], err = hexToBytes32(anchor.DataHash)
+		if err != nil {
+			return fmt.Errorf("submission %d has an invalid data hash: %v", anchor.SubmissionID, err)
+		}
+		batch.DataHashes[i] = anchor.DataHash
+		batch.SubmissionIDs[i] = anchor.SubmissionID
+	}
+
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return err
+	}
+	root := tree.Root()
+	batch.Root = hexutil.Encode(root[:])
+
+	batch.IPFSHash, err = s.uploadToPinata("anchor_batch.json", gin.H{
+		"root":           batch.Root,
+		"data_hashes":    batch.DataHashes,
+		"submission_ids": batch.SubmissionIDs,
+		"created_at":     batch.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload batch manifest: %v", err)
+	}
+
+	anchorJob, err := s.Chain.SubmitWeatherDataJob(0, anchorDeviceID, batch.IPFSHash, root)
+	if err != nil {
+		return err
+	}
+	anchorJob.Kind = txKindAnchorBatch
+
+	// Each submission is recorded in DeviceRegistry on its own, so the
+	// registry's submission counts, which rewards are based on, match those of
+	// per-submission anchoring. As there, devices not registered on-chain get
+	// no record job, since recordSubmission would revert.
+	jobs := []*TxJob{anchorJob}
+	onChain := make(map[string]bool)
+	for _, anchor := range pending {
+		registered, ok := onChain[anchor.DeviceID]
+		if !ok {
+			device, err := s.Store.GetDevice(anchor.DeviceID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			registered = err == nil && device.Owner != ""
+			onChain[anchor.DeviceID] = registered
+		}
+		if !registered {
+			continue
+		}
+
+		deviceID, err := deviceIDToBytes32(anchor.DeviceID)
+		if err != nil {
+			return err
+		}
+
+		recordJob, err := s.Chain.RecordSubmissionJob(anchor.SubmissionID, deviceID)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, recordJob)
+	}
+
+	// The batch and its jobs are stored together, so a failure cannot leave
+	// a batch that is never anchored.
+	s.TxQueue.Prepare(jobs...)
+	if err := s.Store.CreateAnchorBatch(batch, jobs); err != nil {
+		return err
+	}
+	s.TxQueue.Wake()
+	return nil
+}
+
+func (s *WeatherService) handleAnchoredBatch(job *TxJob, receipt *types.Receipt) {
+	batch, err := s.Store.GetAnchorBatch(job.BatchID)
+	if err != nil {
+		log.Printf("Failed to load anchor batch %d: %v", job.BatchID, err)
+		return
+	}
+
+	entryID, err := s.Chain.ParseEntryID(receipt)
+	if err != nil {
+		log.Printf("Anchor batch %d: %v", batch.ID, err)
+	} else {
+		batch.EntryID = entryID.String()
+	}
+	batch.TxHash = job.TxHash()
+	batch.AnchoredAt = time.Now()
+
+	if err := s.Store.SaveAnchorBatch(batch); err != nil {
+		log.Printf("Failed to update anchor batch %d: %v", batch.ID, err)
+	}
+}
+
+func (s *WeatherService) GetProof(c *gin.Context) {
+	dataHash := strings.ToLower(strings.TrimPrefix(c.Param("data_hash"), "0x"))
+	leaf, err := hexToBytes32(dataHash)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data hash"})
+		return
+	}
+
+	batch, err := s.Store.FindAnchorBatch(dataHash)
+	if errors.Is(err, ErrNotFound) {
+		s.respondUnbatchedProof(c, dataHash)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load anchor batch"})
+		return
+	}
+
+	index := -1
+	leaves := make([][32]byte, len(batch.DataHashes))
+	for i, hash := range batch.DataHashes {
+		leaves[i], err = hexToBytes32(hash)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Corrupt anchor batch"})
+			return
+		}
+		if hash == dataHash {
+			index = i
+		}
+	}
+
+	tree, err := merkle.New(leaves)
+	if err != nil || index < 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Corrupt anchor batch"})
+		return
+	}
+
+	proof, err := tree.Proof(index)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build proof"})
+		return
+	}
+
+	proofHex := make([]string, len(proof))
+	for i, node := range proof {
+		proofHex[i] = hexutil.Encode(node[:])
+	}
+
+	status := "pending"
+	if batch.TxJobID != 0 {
+		if job, err := s.Store.GetTxJob(batch.TxJobID); err == nil {
+			status = anchorStatus(job)
+		}
+	}
+
+	leafHash := merkle.HashLeaf(leaf)
+	c.JSON(http.StatusOK, gin.H{
+		"data_hash":  dataHash,
+		"leaf":       hexutil.Encode(leafHash[:]),
+		"leaf_index": index,
+		"proof":      proofHex,
+		"root":       batch.Root,
+		"batch_id":   batch.ID,
+		"ipfs_hash":  batch.IPFSHash,
+		"tx_hash":    batch.TxHash,
+		"entry_id":   batch.EntryID,
+		"status":     status,
+	})
+}
+
+func (s *WeatherService) respondUnbatchedProof(c *gin.Context, dataHash string) {
+	pending, err := s.Store.ListPendingAnchors()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load pending anchors"})
+		return
+	}
+
+	for _, anchor := range pending {
+		if anchor.DataHash == dataHash {
+			c.JSON(http.StatusAccepted, gin.H{
+				"data_hash":     dataHash,
+				"submission_id": anchor.SubmissionID,
+				"status":        "awaiting_batch",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "No batch contains this data hash"})
+}
+
+func anchorStatus(job *TxJob) string {
+	switch job.Status {
+	case TxConfirmed:
+		return "anchored"
+	case TxFailed:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"weather-backend/merkle"
+)
+
+func TestAnchorPendingBatch(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+
+	service := newTestService(t)
+	service.Config.AnchorMode = anchorModeBatch
+	service.Chain = chain.client
+	service.TxQueue = chain.newQueue(nil, service.Store, 5)
+	service.TxQueue.OnConfirmed(service.handleConfirmedTx)
+
+	onChain := newTestStation(t, service)
+	onChain.device.Owner = chain.auth.From.Hex()
+	if err := service.Store.SaveDevice(onChain.device); err != nil {
+		t.Fatal(err)
+	}
+	offChain := newTestStation(t, service)
+
+	submissions := make(map[uint64]*testStation)
+	var dataHashes []string
+	for _, station := range []*testStation{onChain, offChain, onChain} {
+		payload := station.sign(t, station.reading(time.Now()))
+
+		var response struct {
+			SubmissionID uint64 `json:"submission_id"`
+			Status       string `json:"status"`
+		}
+		if code := serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, &response); code != http.StatusOK {
+			t.Fatalf("submit returned %d", code)
+		}
+		if response.Status != "awaiting_batch" {
+			t.Errorf("submission status = %s, want awaiting_batch", response.Status)
+		}
+		submissions[response.SubmissionID] = station
+		dataHashes = append(dataHashes, payload.DataHash)
+	}
+
+	if err := service.anchorPendingBatch(); err != nil {
+		t.Fatalf("anchorPendingBatch: %v", err)
+	}
+
+	pending, err := service.Store.ListPendingAnchors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d submissions still await a batch", len(pending))
+	}
+
+	// One anchor, and one registry record for each submission from the device
+	// registered on-chain.
+	jobs, err := service.Store.ListPendingTxJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := make(map[uint64]bool)
+	anchors := 0
+	for _, job := range jobs {
+		switch job.Kind {
+		case txKindAnchorBatch:
+			anchors++
+		case txKindRecordSubmission:
+			if submissions[job.SubmissionID] != onChain || recorded[job.SubmissionID] {
+				t.Errorf("unexpected record job for submission %d", job.SubmissionID)
+			}
+			recorded[job.SubmissionID] = true
+		}
+	}
+	if anchors != 1 || len(recorded) != 2 {
+		t.Errorf("queued %d anchor and %d record jobs, want 1 and 2", anchors, len(recorded))
+	}
+
+	service.TxQueue.process(ctx)
+	chain.sim.Commit()
+	service.TxQueue.process(ctx)
+
+	for _, dataHash := range dataHashes {
+		var proof struct {
+			Proof  []string `json:"proof"`
+			Root   string   `json:"root"`
+			Status string   `json:"status"`
+			TxHash string   `json:"tx_hash"`
+		}
+		target := "/api/proofs/0x" + dataHash
+		if code := serve(t, service.GetProof, http.MethodGet, "/api/proofs/:data_hash", target, nil, &proof); code != http.StatusOK {
+			t.Fatalf("GET %s returned %d", target, code)
+		}
+		if proof.Status != "anchored" || proof.TxHash == "" {
+			t.Errorf("proof status = %s with tx %q, want anchored", proof.Status, proof.TxHash)
+		}
+
+		leaf, err := hexToBytes32(dataHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := hexToBytes32(proof.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		siblings := make([][32]byte, len(proof.Proof))
+		for i, node := range proof.Proof {
+			siblings[i] = [32]byte(hexutil.MustDecode(node))
+		}
+		if !merkle.Verify(leaf, siblings, root) {
+			t.Errorf("proof for %s does not verify against root %s", dataHash, proof.Root)
+		}
+	}
+
+	unknown := "/api/proofs/" + strings.Repeat("ab", 32)
+	if code := serve(t, service.GetProof, http.MethodGet, "/api/proofs/:data_hash", unknown, nil, nil); code != http.StatusNotFound {
+		t.Errorf("GET %s returned %d, want 404", unknown, code)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// statsAlpha is the weight of the newest reading in the rolling mean and
+	// variance, which roughly averages over the last 20 readings.
+	statsAlpha = 0.1
+	// clockOffsetSamples is how many recent arrival delays are kept to
+	// estimate clock drift.
+	clockOffsetSamples = 12
+	// nightSolarMax is the most solar radiation in W/m² plausible with the sun
+	// well below the horizon.
+	nightSolarMax = 50
+)
+
+// Anomaly is a temporal quality flag on a submission. Unlike a rule
+// violation, the reading is still accepted.
+type Anomaly struct {
+	Check   string `json:"check"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// DeviceStats are the per-device streaming statistics that anomalies are
+// detected against. They are updated with every accepted reading.
+type DeviceStats struct {
+	Fields        map[string]*FieldStats `json:"fields"`
+	LastTimestamp time.Time              `json:"last_timestamp"`
+	ClockOffsets  []float64              `json:"clock_offsets,omitempty"`
+	FailureStreak int                    `json:"failure_streak"`
+	CleanStreak   int                    `json:"clean_streak"`
+}
+
+type FieldStats struct {
+	Count    int     `json:"count"`
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	Last     float64 `json:"last"`
+	Repeats  int     `json:"repeats"`
+}
+
+// anomalyField is a measurement tracked for anomalies. minStdDev keeps a
+// very steady history from flagging ordinary sensor noise as a spike.
+// Flatlines are not checked for fields that legitimately hold still, such as
+// wind speed on a calm night.
+type anomalyField struct {
+	name          string
+	minStdDev     float64
+	checkFlatline bool
+}
+
+var anomalyFields = []anomalyField{
+	{"temperature", 0.3, true},
+	{"humidity", 1.0, true},
+	{"pressure", 0.3, true},
+	{"wind_speed", 2.0, false},
+}
+
+// detectAnomalies checks a reading against the device's history before the
+// reading is added to it. Backfilled readings arrive long after they were
+// taken, so they are not used to judge the device clock.
+func (s *WeatherService) detectAnomalies(device *DeviceRegistration, data WeatherData, receivedAt time.Time, backfill bool) []Anomaly {
+	anomalies := make([]Anomaly, 0)
+	stats := device.Stats
+	if stats == nil {
+		stats = &DeviceStats{}
+	}
+
+	for _, field := range anomalyFields {
+		value, _ := fieldValue(&data, field.name)
+		fieldStats := stats.Fields[field.name]
+		if fieldStats == nil {
+			continue
+		}
+
+		if fieldStats.Count >= s.Config.AnomalyWarmup {
+			stdDev := math.Max(math.Sqrt(fieldStats.Variance), field.minStdDev)
+			if deviation := math.Abs(value - fieldStats.Mean); deviation > s.Config.AnomalySpikeSigma*stdDev {
+				anomalies = append(anomalies, Anomaly{
+					Check:   "spike",
+					Field:   field.name,
+					Message: fmt.Sprintf("%s is %.1f standard deviations from its rolling mean %.4g", field.name, deviation/stdDev, fieldStats.Mean),
+				})
+			}
+		}
+
+		if field.checkFlatline && value == fieldStats.Last && fieldStats.Repeats+1 >= s.Config.AnomalyFlatlineReadings {
+			anomalies = append(anomalies, Anomaly{
+				Check:   "flatline",
+				Field:   field.name,
+				Message: fmt.Sprintf("%s has reported %g for %d readings in a row", field.name, value, fieldStats.Repeats+1),
+			})
+		}
+	}
+
+	if !stats.LastTimestamp.IsZero() && !data.Timestamp.After(stats.LastTimestamp) {
+		anomalies = append(anomalies, Anomaly{
+			Check:   "timestamp_regression",
+			Field:   "timestamp",
+			Message: fmt.Sprintf("timestamp is not after the previous reading's %s", stats.LastTimestamp.Format(time.RFC3339)),
+		})
+	}
+
+	// Readings can arrive late but never early, so the smallest recent delay
+	// estimates how far the device clock is off.
+	offsets := append(append([]float64(nil), stats.ClockOffsets...), receivedAt.Sub(data.Timestamp).Seconds())
+	if !backfill && len(offsets) >= clockOffsetSamples/2 {
+		drift := minOf(offsets)
+		if math.Abs(drift) > float64(s.Config.AnomalyMaxClockDrift) {
+			direction := "behind"
+			if drift < 0 {
+				direction = "ahead"
+			}
+			anomalies = append(anomalies, Anomaly{
+				Check:   "timestamp_drift",
+				Field:   "timestamp",
+				Message: fmt.Sprintf("device clock appears to be %.0f seconds %s", math.Abs(drift), direction),
+			})
+		}
+	}
+
+	if data.SolarRadiation != nil && *data.SolarRadiation > nightSolarMax && device.Latitude != nil && device.Longitude != nil {
+		if elevation := solarElevation(*device.Latitude, *device.Longitude, data.Timestamp); elevation < -6 {
+			anomalies = append(anomalies, Anomaly{
+				Check:   "diurnal",
+				Field:   "solar_radiation",
+				Message: fmt.Sprintf("solar_radiation is %.0f W/m² while the sun is %.0f° below the horizon", *data.SolarRadiation, -elevation),
+			})
+		}
+	}
+
+	return anomalies
+}
+
+// updateDeviceStats adds an accepted reading to the device's statistics and
+// marks the device degraded after ANOMALY_DEGRADED_AFTER failed readings in a
+// row, or healthy again after ANOMALY_RECOVER_AFTER clean ones.
+func (s *WeatherService) updateDeviceStats(deviceID string, data WeatherData, receivedAt time.Time, backfill, failed bool) {
+	err := s.Store.UpdateDevice(deviceID, func(device *DeviceRegistration) error {
+		if device.Stats == nil {
+			device.Stats = &DeviceStats{}
+		}
+		stats := device.Stats
+		if stats.Fields == nil {
+			stats.Fields = make(map[string]*FieldStats)
+		}
+
+		for _, field := range anomalyFields {
+			value, _ := fieldValue(&data, field.name)
+			fieldStats := stats.Fields[field.name]
+			if fieldStats == nil {
+				stats.Fields[field.name] = &FieldStats{Count: 1, Mean: value, Last: value}
+				continue
+			}
+
+			diff := value - fieldStats.Mean
+			increment := statsAlpha * diff
+			fieldStats.Mean += increment
+			fieldStats.Variance = (1 - statsAlpha) * (fieldStats.Variance + diff*increment)
+			fieldStats.Count++
+
+			if value == fieldStats.Last {
+				fieldStats.Repeats++
+			} else {
+				fieldStats.Repeats = 0
+			}
+			fieldStats.Last = value
+		}
+
+		if data.Timestamp.After(stats.LastTimestamp) {
+			stats.LastTimestamp = data.Timestamp
+		}
+		if !backfill {
+			stats.ClockOffsets = append(stats.ClockOffsets, receivedAt.Sub(data.Timestamp).Seconds())
+			if len(stats.ClockOffsets) > clockOffsetSamples {
+				stats.ClockOffsets = stats.ClockOffsets[len(stats.ClockOffsets)-clockOffsetSamples:]
+			}
+		}
+
+		if failed {
+			stats.FailureStreak++
+			stats.CleanStreak = 0
+		} else {
+			stats.CleanStreak++
+			stats.FailureStreak = 0
+		}
+
+		switch {
+		case !device.Degraded && stats.FailureStreak >= s.Config.AnomalyDegradedAfter:
+			device.Degraded = true
+			device.DegradedSince = receivedAt
+			log.Printf("Device %s marked degraded after %d flagged readings", device.DeviceID, stats.FailureStreak)
+		case device.Degraded && stats.CleanStreak >= s.Config.AnomalyRecoverAfter:
+			device.Degraded = false
+			device.DegradedSince = time.Time{}
+			log.Printf("Device %s recovered after %d clean readings", device.DeviceID, stats.CleanStreak)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to update statistics for device %s: %v", deviceID, err)
+	}
+}
+
+// Flagged reports whether quality control raised any concern about the
+// submission.
+func (r *SubmissionRecord) Flagged() bool {
+	return len(r.Anomalies) > 0 || (r.QC != nil && r.QC.Flag == QCSuspect)
+}
+
+// solarElevation approximates the sun's elevation in degrees, ignoring the
+// equation of time. It is accurate to a few degrees, which is enough to tell
+// day from night.
+func solarElevation(latitude, longitude float64, at time.Time) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	at = at.UTC()
+	declination := toRadians(23.44) * math.Sin(2*math.Pi*float64(284+at.YearDay())/365)
+	solarHours := float64(at.Hour()) + float64(at.Minute())/60 + longitude/15
+	hourAngle := toRadians(15 * (solarHours - 12))
+
+	lat := toRadians(latitude)
+	sinElevation := math.Sin(lat)*math.Sin(declination) + math.Cos(lat)*math.Cos(declination)*math.Cos(hourAngle)
+	return math.Asin(sinElevation) * 180 / math.Pi
+}
+
+func minOf(values []float64) float64 {
+	min := values[0]
+	for _, value := range values[1:] {
+		min = math.Min(min, value)
+	}
+	return min
+}
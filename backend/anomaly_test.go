@@ -0,0 +1,189 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// anomalyDevice registers a device and feeds it readings every five minutes
+// up to start, each received delay after it was taken. temperature gives the
+// i-th reading's temperature.
+func anomalyDevice(t *testing.T, service *WeatherService, deviceID string, start time.Time, readings int, delay time.Duration, temperature func(i int) float64) *DeviceRegistration {
+	t.Helper()
+
+	latitude, longitude := 0.0, 0.0
+	device := &DeviceRegistration{DeviceID: deviceID, Latitude: &latitude, Longitude: &longitude, IsActive: true}
+	if err := service.Store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < readings; i++ {
+		timestamp := start.Add(time.Duration(i-readings+1) * 5 * time.Minute)
+		data := WeatherData{
+			Temperature: temperature(i),
+			Humidity:    50 + float64(i%3),
+			Pressure:    1013 + float64(i%2)/10,
+			WindSpeed:   10,
+			Timestamp:   timestamp,
+		}
+		service.updateDeviceStats(deviceID, data, timestamp.Add(delay), false, false)
+	}
+
+	device, err := service.Store.GetDevice(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return device
+}
+
+func anomalyChecks(anomalies []Anomaly) []string {
+	checks := make([]string, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		checks = append(checks, anomaly.Check+" "+anomaly.Field)
+	}
+	return checks
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	service := newTestService(t)
+	service.Config.AnomalyWarmup = 5
+	service.Config.AnomalyFlatlineReadings = 4
+
+	last := time.Date(2025, 3, 20, 11, 55, 0, 0, time.UTC)
+	next := last.Add(5 * time.Minute)
+	varying := func(i int) float64 { return 20 + float64(i%5)/5 }
+	device := anomalyDevice(t, service, "steady", last, 10, 3*time.Second, varying)
+
+	solar := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name       string
+		data       WeatherData
+		receivedAt time.Time
+		backfill   bool
+		want       []string
+	}{
+		{"ordinary reading", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next}, next, false, nil},
+		{"temperature spike", WeatherData{Temperature: 35, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next}, next, false,
+			[]string{"spike temperature"}},
+		{"wind dropping within its minimum spread", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 0, Timestamp: next}, next, false, nil},
+		{"timestamp regression", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: last}, next, false,
+			[]string{"timestamp_regression timestamp"}},
+		{"one late arrival is not drift", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next}, next.Add(time.Hour), false, nil},
+		{"solar radiation at noon", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next, SolarRadiation: solar(900)}, next, false, nil},
+		{"solar radiation at midnight", WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next.Add(12 * time.Hour), SolarRadiation: solar(900)},
+			next.Add(12 * time.Hour), false, []string{"diurnal solar_radiation"}},
+	}
+
+	for _, tt := range tests {
+		got := anomalyChecks(service.detectAnomalies(device, tt.data, tt.receivedAt, tt.backfill))
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s: anomalies = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A stuck thermometer is flagged once the value repeats often enough.
+	stuck := anomalyDevice(t, service, "stuck", last, 10, 3*time.Second, func(i int) float64 { return 18 })
+	got := anomalyChecks(service.detectAnomalies(stuck, WeatherData{Temperature: 18, Humidity: 52, Pressure: 1013.1, WindSpeed: 12, Timestamp: next}, next, false))
+	if len(got) != 1 || got[0] != "flatline temperature" {
+		t.Errorf("stuck thermometer: anomalies = %v, want a temperature flatline", got)
+	}
+}
+
+func TestDetectClockDrift(t *testing.T) {
+	service := newTestService(t)
+	last := time.Date(2025, 3, 20, 11, 55, 0, 0, time.UTC)
+	next := last.Add(5 * time.Minute)
+	varying := func(i int) float64 { return 20 + float64(i%5)/5 }
+
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		backfill bool
+		want     string
+	}{
+		{"on time", 3 * time.Second, false, ""},
+		{"clock behind", 10 * time.Minute, false, "device clock appears to be 600 seconds behind"},
+		{"clock ahead", -10 * time.Minute, false, "device clock appears to be 600 seconds ahead"},
+		{"backfill", 10 * time.Minute, true, ""},
+	}
+
+	for i, tt := range tests {
+		device := anomalyDevice(t, service, string(rune('a'+i)), last, clockOffsetSamples, tt.delay, varying)
+		data := WeatherData{Temperature: 20.3, Humidity: 52, Pressure: 1013.1, WindSpeed: 10, Timestamp: next}
+
+		message := ""
+		for _, anomaly := range service.detectAnomalies(device, data, next.Add(tt.delay), tt.backfill) {
+			if anomaly.Check == "timestamp_drift" {
+				message = anomaly.Message
+			}
+		}
+		if message != tt.want {
+			t.Errorf("%s: drift %q, want %q", tt.name, message, tt.want)
+		}
+	}
+}
+
+func TestDegradedDevice(t *testing.T) {
+	service := newTestService(t)
+	service.Config.AnomalyDegradedAfter = 3
+	service.Config.AnomalyRecoverAfter = 4
+
+	device := &DeviceRegistration{DeviceID: "flaky", IsActive: true}
+	if err := service.Store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Now()
+	feed := func(failed bool) *DeviceRegistration {
+		at = at.Add(5 * time.Minute)
+		service.updateDeviceStats("flaky", WeatherData{Temperature: 20, Timestamp: at}, at, false, failed)
+		device, err := service.Store.GetDevice("flaky")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return device
+	}
+
+	// A clean reading resets the failure streak.
+	for _, failed := range []bool{true, true, false, true, true} {
+		if device := feed(failed); device.Degraded {
+			t.Fatalf("degraded after a streak of %d", device.Stats.FailureStreak)
+		}
+	}
+	device = feed(true)
+	if !device.Degraded || !device.DegradedSince.Equal(at) {
+		t.Fatalf("after 3 flagged readings in a row: degraded %v since %v, want degraded since %v", device.Degraded, device.DegradedSince, at)
+	}
+
+	for i := 1; i < 4; i++ {
+		if device := feed(false); !device.Degraded {
+			t.Fatalf("recovered after %d clean readings, want 4", i)
+		}
+	}
+	if device := feed(false); device.Degraded || !device.DegradedSince.IsZero() {
+		t.Errorf("after 4 clean readings: degraded %v since %v, want recovered", device.Degraded, device.DegradedSince)
+	}
+}
+
+func TestSolarElevation(t *testing.T) {
+	equinox := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                string
+		latitude, longitude float64
+		at                  time.Time
+		min, max            float64
+	}{
+		{"equator at noon", 0, 0, equinox, 80, 90},
+		{"equator at midnight", 0, 0, equinox.Add(12 * time.Hour), -90, -80},
+		{"Oslo at local noon in June", 59.9, 10.75, time.Date(2025, 6, 21, 11, 17, 0, 0, time.UTC), 50, 56},
+		{"noon further east", 0, 90, equinox.Add(-6 * time.Hour), 80, 90},
+	}
+
+	for _, tt := range tests {
+		if elevation := solarElevation(tt.latitude, tt.longitude, tt.at); elevation < tt.min || elevation > tt.max || math.IsNaN(elevation) {
+			t.Errorf("%s: elevation %.1f°, want %v..%v", tt.name, elevation, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *WeatherService) SubmitBatch(c *gin.Context) {
+	var items []json.RawMessage
+	if err := c.ShouldBindJSON(&items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload: expected an array of submissions"})
+		return
+	}
+
+	if len(items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Batch is empty"})
+		return
+	}
+	if len(items) > s.Config.BatchMaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":    "Batch exceeds maximum size",
+			"max_size": s.Config.BatchMaxSize,
+		})
+		return
+	}
+
+	results := make([]gin.H, 0, len(items))
+	accepted := 0
+	haltCode := 0
+	limited := make(map[string]bool)
+
+	for i, item := range items {
+		var code int
+		var body gin.H
+
+		var payload SubmissionPayload
+		decodeErr := json.Unmarshal(item, &payload)
+		deviceID, _ := normalizeDeviceID(payload.WeatherData.DeviceID)
+
+		// Items are processed in order so sequence numbers stay monotonic. Once
+		// an item fails for a transient reason, later items it would overtake
+		// are left for the client to resend rather than accepted out of order:
+		// the same device's after a rate limit, every device's after a server
+		// error.
+		switch {
+		case decodeErr != nil:
+			code, body = http.StatusBadRequest, gin.H{"error": "Invalid payload"}
+		case haltCode != 0:
+			code, body = haltCode, gin.H{"error": "Not processed: an earlier item in the batch failed"}
+		case limited[deviceID]:
+			code, body = http.StatusTooManyRequests, gin.H{"error": "Not processed: an earlier item from this device was rate limited"}
+		default:
+			code, body = s.processBatchItem(payload)
+			if code == http.StatusTooManyRequests {
+				limited[deviceID] = true
+			} else if code >= http.StatusInternalServerError {
+				haltCode = code
+			}
+		}
+
+		if code == http.StatusOK {
+			accepted++
+		}
+		body["index"] = i
+		body["code"] = code
+		results = append(results, body)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":  results,
+		"accepted": accepted,
+		"rejected": len(items) - accepted,
+	})
+}
+
+func (s *WeatherService) processBatchItem(payload SubmissionPayload) (int, gin.H) {
+	// Readings within the live window count against the normal rate limit.
+	// Older signed readings are backfill: they are accepted up to
+	// BACKFILL_MAX_AGE and count against their own per-device allowance.
+	if liveMaxAge := s.Rules.LiveMaxAge(); time.Since(payload.WeatherData.Timestamp) <= liveMaxAge {
+		return s.processSubmission(payload, liveMaxAge, false)
+	}
+
+	return s.processSubmission(payload, time.Duration(s.Config.BackfillMaxAge)*time.Second, true)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-protocol"
+)
+
+type batchResponse struct {
+	Results []struct {
+		Code  int    `json:"code"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+func submitBatch(t *testing.T, service *WeatherService, items []SubmissionPayload) batchResponse {
+	t.Helper()
+
+	var response batchResponse
+	if code := serve(t, service.SubmitBatch, http.MethodPost, "/api/submit/batch", "/api/submit/batch", items, &response); code != http.StatusOK {
+		t.Fatalf("batch returned %d", code)
+	}
+	if len(response.Results) != len(items) {
+		t.Fatalf("batch returned %d results for %d items", len(response.Results), len(items))
+	}
+	return response
+}
+
+func TestSubmitBatchBackfillLimit(t *testing.T) {
+	service := newTestService(t)
+	service.Config.BackfillMaxPerWindow = 2
+	station := newTestStation(t, service)
+	victim := newTestStation(t, service)
+
+	backfilled := time.Now().Add(-2 * service.Rules.LiveMaxAge())
+
+	// Unsigned backfill in the victim's name does not use up its allowance.
+	var junk []SubmissionPayload
+	for i := 0; i < 3; i++ {
+		junk = append(junk, SubmissionPayload{WeatherData: victim.reading(backfilled), SigVersion: protocol.SigVersionV1})
+	}
+	if response := submitBatch(t, service, junk); response.Accepted != 0 {
+		t.Fatalf("accepted %d unsigned items", response.Accepted)
+	}
+
+	var signed []SubmissionPayload
+	for i := 0; i < 2; i++ {
+		signed = append(signed, victim.sign(t, victim.reading(backfilled.Add(time.Duration(i)*time.Minute))))
+	}
+	if response := submitBatch(t, service, signed); response.Accepted != 2 {
+		t.Errorf("victim's signed backfill: accepted %d of 2: %+v", response.Accepted, response.Results)
+	}
+
+	// Spelling the device ID differently does not get a fresh allowance.
+	var items []SubmissionPayload
+	for i, deviceID := range []string{station.device.DeviceID, "0x" + strings.ToUpper(station.device.DeviceID), "0x" + station.device.DeviceID} {
+		data := station.reading(backfilled.Add(time.Duration(i) * time.Minute))
+		data.DeviceID = deviceID
+		items = append(items, station.sign(t, data))
+	}
+	response := submitBatch(t, service, items)
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := response.Results[i].Code; got != want {
+			t.Errorf("item %d returned %d (%s), want %d", i, got, response.Results[i].Error, want)
+		}
+	}
+}
+
+func TestSubmitBatchRateOfChangeOutOfOrder(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+
+	base := time.Now().Add(-3 * time.Hour)
+	reading := func(offset time.Duration, temperature float64) SubmissionPayload {
+		data := station.reading(base.Add(offset))
+		data.Temperature = temperature
+		return station.sign(t, data)
+	}
+
+	// The third reading fills the gap between the first two, so it is compared
+	// with the first, 15 minutes earlier. Against the second, taken 45 minutes
+	// after it, its 8 °C difference would be too large.
+	response := submitBatch(t, service, []SubmissionPayload{
+		reading(0, 20),
+		reading(time.Hour, 29),
+		reading(15*time.Minute, 21),
+	})
+	if response.Accepted != 3 {
+		t.Errorf("accepted %d of 3 readings: %+v", response.Accepted, response.Results)
+	}
+}
+
+func TestSubmitBatchRateLimitedDevice(t *testing.T) {
+	service := newTestService(t)
+	service.Config.BackfillMaxPerWindow = 1
+	limited := newTestStation(t, service)
+	other := newTestStation(t, service)
+
+	backfilled := time.Now().Add(-2 * service.Rules.LiveMaxAge())
+	item := func(station *testStation, offset time.Duration) SubmissionPayload {
+		return station.sign(t, station.reading(backfilled.Add(offset)))
+	}
+
+	// Once a device is rate limited its later items are skipped, but other
+	// devices' items are still processed.
+	response := submitBatch(t, service, []SubmissionPayload{
+		item(limited, 0),
+		item(limited, time.Minute),
+		item(other, 0),
+		item(limited, 2*time.Minute),
+	})
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusTooManyRequests} {
+		if got := response.Results[i].Code; got != want {
+			t.Errorf("item %d returned %d (%s), want %d", i, got, response.Results[i].Error, want)
+		}
+	}
+	if !strings.Contains(response.Results[3].Error, "earlier item from this device") {
+		t.Errorf("skipped item error = %q", response.Results[3].Error)
+	}
+}
@@ -0,0 +1,1339 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// DeviceRegistryDevice is an auto generated low-level Go binding around an user-defined struct.
+type DeviceRegistryDevice struct {
+	Owner            common.Address
+	PublicKey        string
+	RegistrationTime *big.Int
+	IsActive         bool
+	LastSubmission   *big.Int
+	TotalSubmissions *big.Int
+}
+
+// DeviceRegistryMetaData contains all meta data concerning the DeviceRegistry contract.
+var DeviceRegistryMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"OwnableInvalidOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"OwnableUnauthorizedAccount\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true}],\"name\":\"DeviceActivated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true}],\"name\":\"DeviceDeactivated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true},{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\",\"indexed\":true},{\"internalType\":\"string\",\"name\":\"publicKey\",\"type\":\"string\",\"indexed\":false}],\"name\":\"DeviceRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true},{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\",\"indexed\":false}],\"name\":\"SubmissionRecorded\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"activateDevice\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"allDevices\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"deactivateDevice\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"devices\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"publicKey\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"registrationTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"lastSubmission\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"totalSubmissions\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"getDevice\",\"outputs\":[{\"internalType\":\"structDeviceRegistry.Device\",\"name\":\"\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"publicKey\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"registrationTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"lastSubmission\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"totalSubmissions\",\"type\":\"uint256\"}]}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"getOwnerDevices\",\"outputs\":[{\"internalType\":\"bytes32[]\",\"name\":\"\",\"type\":\"bytes32[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getTotalDevices\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"isDeviceActive\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"ownerDevices\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"recordSubmission\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"publicKey\",\"type\":\"string\"}],\"name\":\"registerDevice\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// DeviceRegistryABI is the input ABI used to generate the binding from.
+// Deprecated: Use DeviceRegistryMetaData.ABI instead.
+var DeviceRegistryABI = DeviceRegistryMetaData.ABI
+
+// DeviceRegistry is an auto generated Go binding around an Ethereum contract.
+type DeviceRegistry struct {
+	DeviceRegistryCaller     // Read-only binding to the contract
+	DeviceRegistryTransactor // Write-only binding to the contract
+	DeviceRegistryFilterer   // Log filterer for contract events
+}
+
+// DeviceRegistryCaller is an auto generated read-only Go binding around an Ethereum contract.
+type DeviceRegistryCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DeviceRegistryTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type DeviceRegistryTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DeviceRegistryFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type DeviceRegistryFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DeviceRegistrySession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type DeviceRegistrySession struct {
+	Contract     *DeviceRegistry   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// DeviceRegistryCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type DeviceRegistryCallerSession struct {
+	Contract *DeviceRegistryCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// DeviceRegistryTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type DeviceRegistryTransactorSession struct {
+	Contract     *DeviceRegistryTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// DeviceRegistryRaw is an auto generated low-level Go binding around an Ethereum contract.
+type DeviceRegistryRaw struct {
+	Contract *DeviceRegistry // Generic contract binding to access the raw methods on
+}
+
+// DeviceRegistryCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type DeviceRegistryCallerRaw struct {
+	Contract *DeviceRegistryCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// DeviceRegistryTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type DeviceRegistryTransactorRaw struct {
+	Contract *DeviceRegistryTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewDeviceRegistry creates a new instance of DeviceRegistry, bound to a specific deployed contract.
+func NewDeviceRegistry(address common.Address, backend bind.ContractBackend) (*DeviceRegistry, error) {
+	contract, err := bindDeviceRegistry(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistry{DeviceRegistryCaller: DeviceRegistryCaller{contract: contract}, DeviceRegistryTransactor: DeviceRegistryTransactor{contract: contract}, DeviceRegistryFilterer: DeviceRegistryFilterer{contract: contract}}, nil
+}
+
+// NewDeviceRegistryCaller creates a new read-only instance of DeviceRegistry, bound to a specific deployed contract.
+func NewDeviceRegistryCaller(address common.Address, caller bind.ContractCaller) (*DeviceRegistryCaller, error) {
+	contract, err := bindDeviceRegistry(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryCaller{contract: contract}, nil
+}
+
+// NewDeviceRegistryTransactor creates a new write-only instance of DeviceRegistry, bound to a specific deployed contract.
+func NewDeviceRegistryTransactor(address common.Address, transactor bind.ContractTransactor) (*DeviceRegistryTransactor, error) {
+	contract, err := bindDeviceRegistry(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryTransactor{contract: contract}, nil
+}
+
+// NewDeviceRegistryFilterer creates a new log filterer instance of DeviceRegistry, bound to a specific deployed contract.
+func NewDeviceRegistryFilterer(address common.Address, filterer bind.ContractFilterer) (*DeviceRegistryFilterer, error) {
+	contract, err := bindDeviceRegistry(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryFilterer{contract: contract}, nil
+}
+
+// bindDeviceRegistry binds a generic wrapper to an already deployed contract.
+func bindDeviceRegistry(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := DeviceRegistryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DeviceRegistry *DeviceRegistryRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DeviceRegistry.Contract.DeviceRegistryCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DeviceRegistry *DeviceRegistryRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.DeviceRegistryTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DeviceRegistry *DeviceRegistryRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.DeviceRegistryTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DeviceRegistry *DeviceRegistryCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DeviceRegistry.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DeviceRegistry *DeviceRegistryTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DeviceRegistry *DeviceRegistryTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.contract.Transact(opts, method, params...)
+}
+
+// AllDevices is a free data retrieval call binding the contract method 0xe84db8b8.
+//
+// Solidity: function allDevices(uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistryCaller) AllDevices(opts *bind.CallOpts, arg0 *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "allDevices", arg0)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// AllDevices is a free data retrieval call binding the contract method 0xe84db8b8.
+//
+// Solidity: function allDevices(uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistrySession) AllDevices(arg0 *big.Int) ([32]byte, error) {
+	return _DeviceRegistry.Contract.AllDevices(&_DeviceRegistry.CallOpts, arg0)
+}
+
+// AllDevices is a free data retrieval call binding the contract method 0xe84db8b8.
+//
+// Solidity: function allDevices(uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistryCallerSession) AllDevices(arg0 *big.Int) ([32]byte, error) {
+	return _DeviceRegistry.Contract.AllDevices(&_DeviceRegistry.CallOpts, arg0)
+}
+
+// Devices is a free data retrieval call binding the contract method 0xc5c400de.
+//
+// Solidity: function devices(bytes32 ) view returns(address owner, string publicKey, uint256 registrationTime, bool isActive, uint256 lastSubmission, uint256 totalSubmissions)
+func (_DeviceRegistry *DeviceRegistryCaller) Devices(opts *bind.CallOpts, arg0 [32]byte) (struct {
+	Owner            common.Address
+	PublicKey        string
+	RegistrationTime *big.Int
+	IsActive         bool
+	LastSubmission   *big.Int
+	TotalSubmissions *big.Int
+}, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "devices", arg0)
+
+	outstruct := new(struct {
+		Owner            common.Address
+		PublicKey        string
+		RegistrationTime *big.Int
+		IsActive         bool
+		LastSubmission   *big.Int
+		TotalSubmissions *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Owner = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.PublicKey = *abi.ConvertType(out[1], new(string)).(*string)
+	outstruct.RegistrationTime = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.IsActive = *abi.ConvertType(out[3], new(bool)).(*bool)
+	outstruct.LastSubmission = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
+	outstruct.TotalSubmissions = *abi.ConvertType(out[5], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// Devices is a free data retrieval call binding the contract method 0xc5c400de.
+//
+// Solidity: function devices(bytes32 ) view returns(address owner, string publicKey, uint256 registrationTime, bool isActive, uint256 lastSubmission, uint256 totalSubmissions)
+func (_DeviceRegistry *DeviceRegistrySession) Devices(arg0 [32]byte) (struct {
+	Owner            common.Address
+	PublicKey        string
+	RegistrationTime *big.Int
+	IsActive         bool
+	LastSubmission   *big.Int
+	TotalSubmissions *big.Int
+}, error) {
+	return _DeviceRegistry.Contract.Devices(&_DeviceRegistry.CallOpts, arg0)
+}
+
+// Devices is a free data retrieval call binding the contract method 0xc5c400de.
+//
+// Solidity: function devices(bytes32 ) view returns(address owner, string publicKey, uint256 registrationTime, bool isActive, uint256 lastSubmission, uint256 totalSubmissions)
+func (_DeviceRegistry *DeviceRegistryCallerSession) Devices(arg0 [32]byte) (struct {
+	Owner            common.Address
+	PublicKey        string
+	RegistrationTime *big.Int
+	IsActive         bool
+	LastSubmission   *big.Int
+	TotalSubmissions *big.Int
+}, error) {
+	return _DeviceRegistry.Contract.Devices(&_DeviceRegistry.CallOpts, arg0)
+}
+
+// GetDevice is a free data retrieval call binding the contract method 0x6a7f745e.
+//
+// Solidity: function getDevice(bytes32 deviceId) view returns((address,string,uint256,bool,uint256,uint256))
+func (_DeviceRegistry *DeviceRegistryCaller) GetDevice(opts *bind.CallOpts, deviceId [32]byte) (DeviceRegistryDevice, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "getDevice", deviceId)
+
+	if err != nil {
+		return *new(DeviceRegistryDevice), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(DeviceRegistryDevice)).(*DeviceRegistryDevice)
+
+	return out0, err
+
+}
+
+// GetDevice is a free data retrieval call binding the contract method 0x6a7f745e.
+//
+// Solidity: function getDevice(bytes32 deviceId) view returns((address,string,uint256,bool,uint256,uint256))
+func (_DeviceRegistry *DeviceRegistrySession) GetDevice(deviceId [32]byte) (DeviceRegistryDevice, error) {
+	return _DeviceRegistry.Contract.GetDevice(&_DeviceRegistry.CallOpts, deviceId)
+}
+
+// GetDevice is a free data retrieval call binding the contract method 0x6a7f745e.
+//
+// Solidity: function getDevice(bytes32 deviceId) view returns((address,string,uint256,bool,uint256,uint256))
+func (_DeviceRegistry *DeviceRegistryCallerSession) GetDevice(deviceId [32]byte) (DeviceRegistryDevice, error) {
+	return _DeviceRegistry.Contract.GetDevice(&_DeviceRegistry.CallOpts, deviceId)
+}
+
+// GetOwnerDevices is a free data retrieval call binding the contract method 0xbfb4493f.
+//
+// Solidity: function getOwnerDevices(address owner) view returns(bytes32[])
+func (_DeviceRegistry *DeviceRegistryCaller) GetOwnerDevices(opts *bind.CallOpts, owner common.Address) ([][32]byte, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "getOwnerDevices", owner)
+
+	if err != nil {
+		return *new([][32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([][32]byte)).(*[][32]byte)
+
+	return out0, err
+
+}
+
+// GetOwnerDevices is a free data retrieval call binding the contract method 0xbfb4493f.
+//
+// Solidity: function getOwnerDevices(address owner) view returns(bytes32[])
+func (_DeviceRegistry *DeviceRegistrySession) GetOwnerDevices(owner common.Address) ([][32]byte, error) {
+	return _DeviceRegistry.Contract.GetOwnerDevices(&_DeviceRegistry.CallOpts, owner)
+}
+
+// GetOwnerDevices is a free data retrieval call binding the contract method 0xbfb4493f.
+//
+// Solidity: function getOwnerDevices(address owner) view returns(bytes32[])
+func (_DeviceRegistry *DeviceRegistryCallerSession) GetOwnerDevices(owner common.Address) ([][32]byte, error) {
+	return _DeviceRegistry.Contract.GetOwnerDevices(&_DeviceRegistry.CallOpts, owner)
+}
+
+// GetTotalDevices is a free data retrieval call binding the contract method 0xdc61d161.
+//
+// Solidity: function getTotalDevices() view returns(uint256)
+func (_DeviceRegistry *DeviceRegistryCaller) GetTotalDevices(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "getTotalDevices")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetTotalDevices is a free data retrieval call binding the contract method 0xdc61d161.
+//
+// Solidity: function getTotalDevices() view returns(uint256)
+func (_DeviceRegistry *DeviceRegistrySession) GetTotalDevices() (*big.Int, error) {
+	return _DeviceRegistry.Contract.GetTotalDevices(&_DeviceRegistry.CallOpts)
+}
+
+// GetTotalDevices is a free data retrieval call binding the contract method 0xdc61d161.
+//
+// Solidity: function getTotalDevices() view returns(uint256)
+func (_DeviceRegistry *DeviceRegistryCallerSession) GetTotalDevices() (*big.Int, error) {
+	return _DeviceRegistry.Contract.GetTotalDevices(&_DeviceRegistry.CallOpts)
+}
+
+// IsDeviceActive is a free data retrieval call binding the contract method 0x5ec8fe6b.
+//
+// Solidity: function isDeviceActive(bytes32 deviceId) view returns(bool)
+func (_DeviceRegistry *DeviceRegistryCaller) IsDeviceActive(opts *bind.CallOpts, deviceId [32]byte) (bool, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "isDeviceActive", deviceId)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// IsDeviceActive is a free data retrieval call binding the contract method 0x5ec8fe6b.
+//
+// Solidity: function isDeviceActive(bytes32 deviceId) view returns(bool)
+func (_DeviceRegistry *DeviceRegistrySession) IsDeviceActive(deviceId [32]byte) (bool, error) {
+	return _DeviceRegistry.Contract.IsDeviceActive(&_DeviceRegistry.CallOpts, deviceId)
+}
+
+// IsDeviceActive is a free data retrieval call binding the contract method 0x5ec8fe6b.
+//
+// Solidity: function isDeviceActive(bytes32 deviceId) view returns(bool)
+func (_DeviceRegistry *DeviceRegistryCallerSession) IsDeviceActive(deviceId [32]byte) (bool, error) {
+	return _DeviceRegistry.Contract.IsDeviceActive(&_DeviceRegistry.CallOpts, deviceId)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_DeviceRegistry *DeviceRegistryCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_DeviceRegistry *DeviceRegistrySession) Owner() (common.Address, error) {
+	return _DeviceRegistry.Contract.Owner(&_DeviceRegistry.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_DeviceRegistry *DeviceRegistryCallerSession) Owner() (common.Address, error) {
+	return _DeviceRegistry.Contract.Owner(&_DeviceRegistry.CallOpts)
+}
+
+// OwnerDevices is a free data retrieval call binding the contract method 0xe46b4786.
+//
+// Solidity: function ownerDevices(address , uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistryCaller) OwnerDevices(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _DeviceRegistry.contract.Call(opts, &out, "ownerDevices", arg0, arg1)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// OwnerDevices is a free data retrieval call binding the contract method 0xe46b4786.
+//
+// Solidity: function ownerDevices(address , uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistrySession) OwnerDevices(arg0 common.Address, arg1 *big.Int) ([32]byte, error) {
+	return _DeviceRegistry.Contract.OwnerDevices(&_DeviceRegistry.CallOpts, arg0, arg1)
+}
+
+// OwnerDevices is a free data retrieval call binding the contract method 0xe46b4786.
+//
+// Solidity: function ownerDevices(address , uint256 ) view returns(bytes32)
+func (_DeviceRegistry *DeviceRegistryCallerSession) OwnerDevices(arg0 common.Address, arg1 *big.Int) ([32]byte, error) {
+	return _DeviceRegistry.Contract.OwnerDevices(&_DeviceRegistry.CallOpts, arg0, arg1)
+}
+
+// ActivateDevice is a paid mutator transaction binding the contract method 0x568f9ce1.
+//
+// Solidity: function activateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) ActivateDevice(opts *bind.TransactOpts, deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "activateDevice", deviceId)
+}
+
+// ActivateDevice is a paid mutator transaction binding the contract method 0x568f9ce1.
+//
+// Solidity: function activateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistrySession) ActivateDevice(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.ActivateDevice(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// ActivateDevice is a paid mutator transaction binding the contract method 0x568f9ce1.
+//
+// Solidity: function activateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) ActivateDevice(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.ActivateDevice(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// DeactivateDevice is a paid mutator transaction binding the contract method 0x0766e845.
+//
+// Solidity: function deactivateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) DeactivateDevice(opts *bind.TransactOpts, deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "deactivateDevice", deviceId)
+}
+
+// DeactivateDevice is a paid mutator transaction binding the contract method 0x0766e845.
+//
+// Solidity: function deactivateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistrySession) DeactivateDevice(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.DeactivateDevice(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// DeactivateDevice is a paid mutator transaction binding the contract method 0x0766e845.
+//
+// Solidity: function deactivateDevice(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) DeactivateDevice(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.DeactivateDevice(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// RecordSubmission is a paid mutator transaction binding the contract method 0x89764b74.
+//
+// Solidity: function recordSubmission(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) RecordSubmission(opts *bind.TransactOpts, deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "recordSubmission", deviceId)
+}
+
+// RecordSubmission is a paid mutator transaction binding the contract method 0x89764b74.
+//
+// Solidity: function recordSubmission(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistrySession) RecordSubmission(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RecordSubmission(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// RecordSubmission is a paid mutator transaction binding the contract method 0x89764b74.
+//
+// Solidity: function recordSubmission(bytes32 deviceId) returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) RecordSubmission(deviceId [32]byte) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RecordSubmission(&_DeviceRegistry.TransactOpts, deviceId)
+}
+
+// RegisterDevice is a paid mutator transaction binding the contract method 0x84e3c5c9.
+//
+// Solidity: function registerDevice(bytes32 deviceId, string publicKey) returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) RegisterDevice(opts *bind.TransactOpts, deviceId [32]byte, publicKey string) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "registerDevice", deviceId, publicKey)
+}
+
+// RegisterDevice is a paid mutator transaction binding the contract method 0x84e3c5c9.
+//
+// Solidity: function registerDevice(bytes32 deviceId, string publicKey) returns()
+func (_DeviceRegistry *DeviceRegistrySession) RegisterDevice(deviceId [32]byte, publicKey string) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RegisterDevice(&_DeviceRegistry.TransactOpts, deviceId, publicKey)
+}
+
+// RegisterDevice is a paid mutator transaction binding the contract method 0x84e3c5c9.
+//
+// Solidity: function registerDevice(bytes32 deviceId, string publicKey) returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) RegisterDevice(deviceId [32]byte, publicKey string) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RegisterDevice(&_DeviceRegistry.TransactOpts, deviceId, publicKey)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) RenounceOwnership(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "renounceOwnership")
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_DeviceRegistry *DeviceRegistrySession) RenounceOwnership() (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RenounceOwnership(&_DeviceRegistry.TransactOpts)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) RenounceOwnership() (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.RenounceOwnership(&_DeviceRegistry.TransactOpts)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_DeviceRegistry *DeviceRegistryTransactor) TransferOwnership(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _DeviceRegistry.contract.Transact(opts, "transferOwnership", newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_DeviceRegistry *DeviceRegistrySession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.TransferOwnership(&_DeviceRegistry.TransactOpts, newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_DeviceRegistry *DeviceRegistryTransactorSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _DeviceRegistry.Contract.TransferOwnership(&_DeviceRegistry.TransactOpts, newOwner)
+}
+
+// DeviceRegistryDeviceActivatedIterator is returned from FilterDeviceActivated and is used to iterate over the raw logs and unpacked data for DeviceActivated events raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceActivatedIterator struct {
+	Event *DeviceRegistryDeviceActivated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DeviceRegistryDeviceActivatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DeviceRegistryDeviceActivated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DeviceRegistryDeviceActivated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DeviceRegistryDeviceActivatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DeviceRegistryDeviceActivatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DeviceRegistryDeviceActivated represents a DeviceActivated event raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceActivated struct {
+	DeviceId [32]byte
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeviceActivated is a free log retrieval operation binding the contract event 0xf88d777bd2714787b6f9dc2fd4b7ed3f966999c3dd99710e89a7c625921a0986.
+//
+// Solidity: event DeviceActivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) FilterDeviceActivated(opts *bind.FilterOpts, deviceId [][32]byte) (*DeviceRegistryDeviceActivatedIterator, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.FilterLogs(opts, "DeviceActivated", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryDeviceActivatedIterator{contract: _DeviceRegistry.contract, event: "DeviceActivated", logs: logs, sub: sub}, nil
+}
+
+// WatchDeviceActivated is a free log subscription operation binding the contract event 0xf88d777bd2714787b6f9dc2fd4b7ed3f966999c3dd99710e89a7c625921a0986.
+//
+// Solidity: event DeviceActivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) WatchDeviceActivated(opts *bind.WatchOpts, sink chan<- *DeviceRegistryDeviceActivated, deviceId [][32]byte) (event.Subscription, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.WatchLogs(opts, "DeviceActivated", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DeviceRegistryDeviceActivated)
+				if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceActivated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeviceActivated is a log parse operation binding the contract event 0xf88d777bd2714787b6f9dc2fd4b7ed3f966999c3dd99710e89a7c625921a0986.
+//
+// Solidity: event DeviceActivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) ParseDeviceActivated(log types.Log) (*DeviceRegistryDeviceActivated, error) {
+	event := new(DeviceRegistryDeviceActivated)
+	if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceActivated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// DeviceRegistryDeviceDeactivatedIterator is returned from FilterDeviceDeactivated and is used to iterate over the raw logs and unpacked data for DeviceDeactivated events raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceDeactivatedIterator struct {
+	Event *DeviceRegistryDeviceDeactivated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DeviceRegistryDeviceDeactivatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DeviceRegistryDeviceDeactivated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DeviceRegistryDeviceDeactivated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DeviceRegistryDeviceDeactivatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DeviceRegistryDeviceDeactivatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DeviceRegistryDeviceDeactivated represents a DeviceDeactivated event raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceDeactivated struct {
+	DeviceId [32]byte
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeviceDeactivated is a free log retrieval operation binding the contract event 0xec64f74fb9dcf8bcbdeff95120de869ffec917590b3015e3b71c0791867dc08c.
+//
+// Solidity: event DeviceDeactivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) FilterDeviceDeactivated(opts *bind.FilterOpts, deviceId [][32]byte) (*DeviceRegistryDeviceDeactivatedIterator, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.FilterLogs(opts, "DeviceDeactivated", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryDeviceDeactivatedIterator{contract: _DeviceRegistry.contract, event: "DeviceDeactivated", logs: logs, sub: sub}, nil
+}
+
+// WatchDeviceDeactivated is a free log subscription operation binding the contract event 0xec64f74fb9dcf8bcbdeff95120de869ffec917590b3015e3b71c0791867dc08c.
+//
+// Solidity: event DeviceDeactivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) WatchDeviceDeactivated(opts *bind.WatchOpts, sink chan<- *DeviceRegistryDeviceDeactivated, deviceId [][32]byte) (event.Subscription, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.WatchLogs(opts, "DeviceDeactivated", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DeviceRegistryDeviceDeactivated)
+				if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceDeactivated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeviceDeactivated is a log parse operation binding the contract event 0xec64f74fb9dcf8bcbdeff95120de869ffec917590b3015e3b71c0791867dc08c.
+//
+// Solidity: event DeviceDeactivated(bytes32 indexed deviceId)
+func (_DeviceRegistry *DeviceRegistryFilterer) ParseDeviceDeactivated(log types.Log) (*DeviceRegistryDeviceDeactivated, error) {
+	event := new(DeviceRegistryDeviceDeactivated)
+	if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceDeactivated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// DeviceRegistryDeviceRegisteredIterator is returned from FilterDeviceRegistered and is used to iterate over the raw logs and unpacked data for DeviceRegistered events raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceRegisteredIterator struct {
+	Event *DeviceRegistryDeviceRegistered // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DeviceRegistryDeviceRegisteredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DeviceRegistryDeviceRegistered)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DeviceRegistryDeviceRegistered)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DeviceRegistryDeviceRegisteredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DeviceRegistryDeviceRegisteredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DeviceRegistryDeviceRegistered represents a DeviceRegistered event raised by the DeviceRegistry contract.
+type DeviceRegistryDeviceRegistered struct {
+	DeviceId  [32]byte
+	Owner     common.Address
+	PublicKey string
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeviceRegistered is a free log retrieval operation binding the contract event 0x74747c2b921a830d02614cb59ab3906f077d27ad25f84dab131e6259d7734be3.
+//
+// Solidity: event DeviceRegistered(bytes32 indexed deviceId, address indexed owner, string publicKey)
+func (_DeviceRegistry *DeviceRegistryFilterer) FilterDeviceRegistered(opts *bind.FilterOpts, deviceId [][32]byte, owner []common.Address) (*DeviceRegistryDeviceRegisteredIterator, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.FilterLogs(opts, "DeviceRegistered", deviceIdRule, ownerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryDeviceRegisteredIterator{contract: _DeviceRegistry.contract, event: "DeviceRegistered", logs: logs, sub: sub}, nil
+}
+
+// WatchDeviceRegistered is a free log subscription operation binding the contract event 0x74747c2b921a830d02614cb59ab3906f077d27ad25f84dab131e6259d7734be3.
+//
+// Solidity: event DeviceRegistered(bytes32 indexed deviceId, address indexed owner, string publicKey)
+func (_DeviceRegistry *DeviceRegistryFilterer) WatchDeviceRegistered(opts *bind.WatchOpts, sink chan<- *DeviceRegistryDeviceRegistered, deviceId [][32]byte, owner []common.Address) (event.Subscription, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.WatchLogs(opts, "DeviceRegistered", deviceIdRule, ownerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DeviceRegistryDeviceRegistered)
+				if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceRegistered", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeviceRegistered is a log parse operation binding the contract event 0x74747c2b921a830d02614cb59ab3906f077d27ad25f84dab131e6259d7734be3.
+//
+// Solidity: event DeviceRegistered(bytes32 indexed deviceId, address indexed owner, string publicKey)
+func (_DeviceRegistry *DeviceRegistryFilterer) ParseDeviceRegistered(log types.Log) (*DeviceRegistryDeviceRegistered, error) {
+	event := new(DeviceRegistryDeviceRegistered)
+	if err := _DeviceRegistry.contract.UnpackLog(event, "DeviceRegistered", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// DeviceRegistryOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the DeviceRegistry contract.
+type DeviceRegistryOwnershipTransferredIterator struct {
+	Event *DeviceRegistryOwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DeviceRegistryOwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DeviceRegistryOwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DeviceRegistryOwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DeviceRegistryOwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DeviceRegistryOwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DeviceRegistryOwnershipTransferred represents a OwnershipTransferred event raised by the DeviceRegistry contract.
+type DeviceRegistryOwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_DeviceRegistry *DeviceRegistryFilterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*DeviceRegistryOwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistryOwnershipTransferredIterator{contract: _DeviceRegistry.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_DeviceRegistry *DeviceRegistryFilterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *DeviceRegistryOwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DeviceRegistryOwnershipTransferred)
+				if err := _DeviceRegistry.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_DeviceRegistry *DeviceRegistryFilterer) ParseOwnershipTransferred(log types.Log) (*DeviceRegistryOwnershipTransferred, error) {
+	event := new(DeviceRegistryOwnershipTransferred)
+	if err := _DeviceRegistry.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// DeviceRegistrySubmissionRecordedIterator is returned from FilterSubmissionRecorded and is used to iterate over the raw logs and unpacked data for SubmissionRecorded events raised by the DeviceRegistry contract.
+type DeviceRegistrySubmissionRecordedIterator struct {
+	Event *DeviceRegistrySubmissionRecorded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DeviceRegistrySubmissionRecordedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DeviceRegistrySubmissionRecorded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DeviceRegistrySubmissionRecorded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DeviceRegistrySubmissionRecordedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DeviceRegistrySubmissionRecordedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DeviceRegistrySubmissionRecorded represents a SubmissionRecorded event raised by the DeviceRegistry contract.
+type DeviceRegistrySubmissionRecorded struct {
+	DeviceId  [32]byte
+	Timestamp *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterSubmissionRecorded is a free log retrieval operation binding the contract event 0x8037e9cc96313c1d42de635ebc04b4a1a6687e8390fb23aa00e4e709044de717.
+//
+// Solidity: event SubmissionRecorded(bytes32 indexed deviceId, uint256 timestamp)
+func (_DeviceRegistry *DeviceRegistryFilterer) FilterSubmissionRecorded(opts *bind.FilterOpts, deviceId [][32]byte) (*DeviceRegistrySubmissionRecordedIterator, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.FilterLogs(opts, "SubmissionRecorded", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRegistrySubmissionRecordedIterator{contract: _DeviceRegistry.contract, event: "SubmissionRecorded", logs: logs, sub: sub}, nil
+}
+
+// WatchSubmissionRecorded is a free log subscription operation binding the contract event 0x8037e9cc96313c1d42de635ebc04b4a1a6687e8390fb23aa00e4e709044de717.
+//
+// Solidity: event SubmissionRecorded(bytes32 indexed deviceId, uint256 timestamp)
+func (_DeviceRegistry *DeviceRegistryFilterer) WatchSubmissionRecorded(opts *bind.WatchOpts, sink chan<- *DeviceRegistrySubmissionRecorded, deviceId [][32]byte) (event.Subscription, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _DeviceRegistry.contract.WatchLogs(opts, "SubmissionRecorded", deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DeviceRegistrySubmissionRecorded)
+				if err := _DeviceRegistry.contract.UnpackLog(event, "SubmissionRecorded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSubmissionRecorded is a log parse operation binding the contract event 0x8037e9cc96313c1d42de635ebc04b4a1a6687e8390fb23aa00e4e709044de717.
+//
+// Solidity: event SubmissionRecorded(bytes32 indexed deviceId, uint256 timestamp)
+func (_DeviceRegistry *DeviceRegistryFilterer) ParseSubmissionRecorded(log types.Log) (*DeviceRegistrySubmissionRecorded, error) {
+	event := new(DeviceRegistrySubmissionRecorded)
+	if err := _DeviceRegistry.contract.UnpackLog(event, "SubmissionRecorded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
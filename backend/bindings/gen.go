@@ -0,0 +1,5 @@
+package bindings
+
+//go:generate abigen --abi abi/DeviceRegistry.abi.json --pkg bindings --type DeviceRegistry --out device_registry.go
+//go:generate abigen --abi abi/RewardManager.abi.json --pkg bindings --type RewardManager --out reward_manager.go
+//go:generate abigen --abi abi/WeatherData.abi.json --pkg bindings --type WeatherData --out weather_data.go
@@ -0,0 +1,1163 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// RewardManagerMetaData contains all meta data concerning the RewardManager contract.
+var RewardManagerMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_weatherToken\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_deviceRegistry\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"OwnableInvalidOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"OwnableUnauthorizedAccount\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true},{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"baseReward\",\"type\":\"uint256\",\"indexed\":false},{\"internalType\":\"uint256\",\"name\":\"bonusMultiplier\",\"type\":\"uint256\",\"indexed\":false},{\"internalType\":\"uint256\",\"name\":\"bonusThreshold\",\"type\":\"uint256\",\"indexed\":false}],\"name\":\"RewardConfigUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true},{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\",\"indexed\":true},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}],\"name\":\"RewardDistributed\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"baseReward\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"bonusMultiplier\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"bonusThreshold\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"calculateReward\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"dailyRewardLimit\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"dailyRewardsDistributed\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"deviceEarnings\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"deviceRegistry\",\"outputs\":[{\"internalType\":\"contractDeviceRegistry\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"distributeReward\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getCurrentDayRewards\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"day\",\"type\":\"uint256\"}],\"name\":\"getDailyRewardsDistributed\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"getDeviceEarnings\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"lastRewardTime\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_baseReward\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_bonusMultiplier\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_bonusThreshold\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_dailyRewardLimit\",\"type\":\"uint256\"}],\"name\":\"updateRewardConfig\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"weatherToken\",\"outputs\":[{\"internalType\":\"contractIERC20\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"withdrawTokens\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// RewardManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use RewardManagerMetaData.ABI instead.
+var RewardManagerABI = RewardManagerMetaData.ABI
+
+// RewardManager is an auto generated Go binding around an Ethereum contract.
+type RewardManager struct {
+	RewardManagerCaller     // Read-only binding to the contract
+	RewardManagerTransactor // Write-only binding to the contract
+	RewardManagerFilterer   // Log filterer for contract events
+}
+
+// RewardManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type RewardManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RewardManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type RewardManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RewardManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type RewardManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RewardManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type RewardManagerSession struct {
+	Contract     *RewardManager    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// RewardManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type RewardManagerCallerSession struct {
+	Contract *RewardManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// RewardManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type RewardManagerTransactorSession struct {
+	Contract     *RewardManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// RewardManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type RewardManagerRaw struct {
+	Contract *RewardManager // Generic contract binding to access the raw methods on
+}
+
+// RewardManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type RewardManagerCallerRaw struct {
+	Contract *RewardManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// RewardManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type RewardManagerTransactorRaw struct {
+	Contract *RewardManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewRewardManager creates a new instance of RewardManager, bound to a specific deployed contract.
+func NewRewardManager(address common.Address, backend bind.ContractBackend) (*RewardManager, error) {
+	contract, err := bindRewardManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManager{RewardManagerCaller: RewardManagerCaller{contract: contract}, RewardManagerTransactor: RewardManagerTransactor{contract: contract}, RewardManagerFilterer: RewardManagerFilterer{contract: contract}}, nil
+}
+
+// NewRewardManagerCaller creates a new read-only instance of RewardManager, bound to a specific deployed contract.
+func NewRewardManagerCaller(address common.Address, caller bind.ContractCaller) (*RewardManagerCaller, error) {
+	contract, err := bindRewardManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerCaller{contract: contract}, nil
+}
+
+// NewRewardManagerTransactor creates a new write-only instance of RewardManager, bound to a specific deployed contract.
+func NewRewardManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*RewardManagerTransactor, error) {
+	contract, err := bindRewardManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerTransactor{contract: contract}, nil
+}
+
+// NewRewardManagerFilterer creates a new log filterer instance of RewardManager, bound to a specific deployed contract.
+func NewRewardManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*RewardManagerFilterer, error) {
+	contract, err := bindRewardManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerFilterer{contract: contract}, nil
+}
+
+// bindRewardManager binds a generic wrapper to an already deployed contract.
+func bindRewardManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := RewardManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RewardManager *RewardManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RewardManager.Contract.RewardManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RewardManager *RewardManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RewardManager.Contract.RewardManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RewardManager *RewardManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RewardManager.Contract.RewardManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RewardManager *RewardManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RewardManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RewardManager *RewardManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RewardManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RewardManager *RewardManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RewardManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// BaseReward is a free data retrieval call binding the contract method 0x76ad03bc.
+//
+// Solidity: function baseReward() view returns(uint256)
+func (_RewardManager *RewardManagerCaller) BaseReward(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "baseReward")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BaseReward is a free data retrieval call binding the contract method 0x76ad03bc.
+//
+// Solidity: function baseReward() view returns(uint256)
+func (_RewardManager *RewardManagerSession) BaseReward() (*big.Int, error) {
+	return _RewardManager.Contract.BaseReward(&_RewardManager.CallOpts)
+}
+
+// BaseReward is a free data retrieval call binding the contract method 0x76ad03bc.
+//
+// Solidity: function baseReward() view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) BaseReward() (*big.Int, error) {
+	return _RewardManager.Contract.BaseReward(&_RewardManager.CallOpts)
+}
+
+// BonusMultiplier is a free data retrieval call binding the contract method 0xa8b973a1.
+//
+// Solidity: function bonusMultiplier() view returns(uint256)
+func (_RewardManager *RewardManagerCaller) BonusMultiplier(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "bonusMultiplier")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BonusMultiplier is a free data retrieval call binding the contract method 0xa8b973a1.
+//
+// Solidity: function bonusMultiplier() view returns(uint256)
+func (_RewardManager *RewardManagerSession) BonusMultiplier() (*big.Int, error) {
+	return _RewardManager.Contract.BonusMultiplier(&_RewardManager.CallOpts)
+}
+
+// BonusMultiplier is a free data retrieval call binding the contract method 0xa8b973a1.
+//
+// Solidity: function bonusMultiplier() view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) BonusMultiplier() (*big.Int, error) {
+	return _RewardManager.Contract.BonusMultiplier(&_RewardManager.CallOpts)
+}
+
+// BonusThreshold is a free data retrieval call binding the contract method 0xbc4eaa3e.
+//
+// Solidity: function bonusThreshold() view returns(uint256)
+func (_RewardManager *RewardManagerCaller) BonusThreshold(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "bonusThreshold")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BonusThreshold is a free data retrieval call binding the contract method 0xbc4eaa3e.
+//
+// Solidity: function bonusThreshold() view returns(uint256)
+func (_RewardManager *RewardManagerSession) BonusThreshold() (*big.Int, error) {
+	return _RewardManager.Contract.BonusThreshold(&_RewardManager.CallOpts)
+}
+
+// BonusThreshold is a free data retrieval call binding the contract method 0xbc4eaa3e.
+//
+// Solidity: function bonusThreshold() view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) BonusThreshold() (*big.Int, error) {
+	return _RewardManager.Contract.BonusThreshold(&_RewardManager.CallOpts)
+}
+
+// CalculateReward is a free data retrieval call binding the contract method 0x2aea50fe.
+//
+// Solidity: function calculateReward(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) CalculateReward(opts *bind.CallOpts, deviceId [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "calculateReward", deviceId)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CalculateReward is a free data retrieval call binding the contract method 0x2aea50fe.
+//
+// Solidity: function calculateReward(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerSession) CalculateReward(deviceId [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.CalculateReward(&_RewardManager.CallOpts, deviceId)
+}
+
+// CalculateReward is a free data retrieval call binding the contract method 0x2aea50fe.
+//
+// Solidity: function calculateReward(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) CalculateReward(deviceId [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.CalculateReward(&_RewardManager.CallOpts, deviceId)
+}
+
+// DailyRewardLimit is a free data retrieval call binding the contract method 0x91aa50aa.
+//
+// Solidity: function dailyRewardLimit() view returns(uint256)
+func (_RewardManager *RewardManagerCaller) DailyRewardLimit(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "dailyRewardLimit")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// DailyRewardLimit is a free data retrieval call binding the contract method 0x91aa50aa.
+//
+// Solidity: function dailyRewardLimit() view returns(uint256)
+func (_RewardManager *RewardManagerSession) DailyRewardLimit() (*big.Int, error) {
+	return _RewardManager.Contract.DailyRewardLimit(&_RewardManager.CallOpts)
+}
+
+// DailyRewardLimit is a free data retrieval call binding the contract method 0x91aa50aa.
+//
+// Solidity: function dailyRewardLimit() view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) DailyRewardLimit() (*big.Int, error) {
+	return _RewardManager.Contract.DailyRewardLimit(&_RewardManager.CallOpts)
+}
+
+// DailyRewardsDistributed is a free data retrieval call binding the contract method 0xe4056ba9.
+//
+// Solidity: function dailyRewardsDistributed(uint256 ) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) DailyRewardsDistributed(opts *bind.CallOpts, arg0 *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "dailyRewardsDistributed", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// DailyRewardsDistributed is a free data retrieval call binding the contract method 0xe4056ba9.
+//
+// Solidity: function dailyRewardsDistributed(uint256 ) view returns(uint256)
+func (_RewardManager *RewardManagerSession) DailyRewardsDistributed(arg0 *big.Int) (*big.Int, error) {
+	return _RewardManager.Contract.DailyRewardsDistributed(&_RewardManager.CallOpts, arg0)
+}
+
+// DailyRewardsDistributed is a free data retrieval call binding the contract method 0xe4056ba9.
+//
+// Solidity: function dailyRewardsDistributed(uint256 ) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) DailyRewardsDistributed(arg0 *big.Int) (*big.Int, error) {
+	return _RewardManager.Contract.DailyRewardsDistributed(&_RewardManager.CallOpts, arg0)
+}
+
+// DeviceEarnings is a free data retrieval call binding the contract method 0x5fc39ffa.
+//
+// Solidity: function deviceEarnings(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) DeviceEarnings(opts *bind.CallOpts, arg0 [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "deviceEarnings", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// DeviceEarnings is a free data retrieval call binding the contract method 0x5fc39ffa.
+//
+// Solidity: function deviceEarnings(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerSession) DeviceEarnings(arg0 [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.DeviceEarnings(&_RewardManager.CallOpts, arg0)
+}
+
+// DeviceEarnings is a free data retrieval call binding the contract method 0x5fc39ffa.
+//
+// Solidity: function deviceEarnings(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) DeviceEarnings(arg0 [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.DeviceEarnings(&_RewardManager.CallOpts, arg0)
+}
+
+// DeviceRegistry is a free data retrieval call binding the contract method 0x5efb870a.
+//
+// Solidity: function deviceRegistry() view returns(address)
+func (_RewardManager *RewardManagerCaller) DeviceRegistry(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "deviceRegistry")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// DeviceRegistry is a free data retrieval call binding the contract method 0x5efb870a.
+//
+// Solidity: function deviceRegistry() view returns(address)
+func (_RewardManager *RewardManagerSession) DeviceRegistry() (common.Address, error) {
+	return _RewardManager.Contract.DeviceRegistry(&_RewardManager.CallOpts)
+}
+
+// DeviceRegistry is a free data retrieval call binding the contract method 0x5efb870a.
+//
+// Solidity: function deviceRegistry() view returns(address)
+func (_RewardManager *RewardManagerCallerSession) DeviceRegistry() (common.Address, error) {
+	return _RewardManager.Contract.DeviceRegistry(&_RewardManager.CallOpts)
+}
+
+// GetCurrentDayRewards is a free data retrieval call binding the contract method 0x872940d9.
+//
+// Solidity: function getCurrentDayRewards() view returns(uint256)
+func (_RewardManager *RewardManagerCaller) GetCurrentDayRewards(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "getCurrentDayRewards")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetCurrentDayRewards is a free data retrieval call binding the contract method 0x872940d9.
+//
+// Solidity: function getCurrentDayRewards() view returns(uint256)
+func (_RewardManager *RewardManagerSession) GetCurrentDayRewards() (*big.Int, error) {
+	return _RewardManager.Contract.GetCurrentDayRewards(&_RewardManager.CallOpts)
+}
+
+// GetCurrentDayRewards is a free data retrieval call binding the contract method 0x872940d9.
+//
+// Solidity: function getCurrentDayRewards() view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) GetCurrentDayRewards() (*big.Int, error) {
+	return _RewardManager.Contract.GetCurrentDayRewards(&_RewardManager.CallOpts)
+}
+
+// GetDailyRewardsDistributed is a free data retrieval call binding the contract method 0x2146149f.
+//
+// Solidity: function getDailyRewardsDistributed(uint256 day) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) GetDailyRewardsDistributed(opts *bind.CallOpts, day *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "getDailyRewardsDistributed", day)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetDailyRewardsDistributed is a free data retrieval call binding the contract method 0x2146149f.
+//
+// Solidity: function getDailyRewardsDistributed(uint256 day) view returns(uint256)
+func (_RewardManager *RewardManagerSession) GetDailyRewardsDistributed(day *big.Int) (*big.Int, error) {
+	return _RewardManager.Contract.GetDailyRewardsDistributed(&_RewardManager.CallOpts, day)
+}
+
+// GetDailyRewardsDistributed is a free data retrieval call binding the contract method 0x2146149f.
+//
+// Solidity: function getDailyRewardsDistributed(uint256 day) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) GetDailyRewardsDistributed(day *big.Int) (*big.Int, error) {
+	return _RewardManager.Contract.GetDailyRewardsDistributed(&_RewardManager.CallOpts, day)
+}
+
+// GetDeviceEarnings is a free data retrieval call binding the contract method 0x5aea9a36.
+//
+// Solidity: function getDeviceEarnings(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) GetDeviceEarnings(opts *bind.CallOpts, deviceId [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "getDeviceEarnings", deviceId)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetDeviceEarnings is a free data retrieval call binding the contract method 0x5aea9a36.
+//
+// Solidity: function getDeviceEarnings(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerSession) GetDeviceEarnings(deviceId [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.GetDeviceEarnings(&_RewardManager.CallOpts, deviceId)
+}
+
+// GetDeviceEarnings is a free data retrieval call binding the contract method 0x5aea9a36.
+//
+// Solidity: function getDeviceEarnings(bytes32 deviceId) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) GetDeviceEarnings(deviceId [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.GetDeviceEarnings(&_RewardManager.CallOpts, deviceId)
+}
+
+// LastRewardTime is a free data retrieval call binding the contract method 0xcbd8018b.
+//
+// Solidity: function lastRewardTime(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerCaller) LastRewardTime(opts *bind.CallOpts, arg0 [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "lastRewardTime", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// LastRewardTime is a free data retrieval call binding the contract method 0xcbd8018b.
+//
+// Solidity: function lastRewardTime(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerSession) LastRewardTime(arg0 [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.LastRewardTime(&_RewardManager.CallOpts, arg0)
+}
+
+// LastRewardTime is a free data retrieval call binding the contract method 0xcbd8018b.
+//
+// Solidity: function lastRewardTime(bytes32 ) view returns(uint256)
+func (_RewardManager *RewardManagerCallerSession) LastRewardTime(arg0 [32]byte) (*big.Int, error) {
+	return _RewardManager.Contract.LastRewardTime(&_RewardManager.CallOpts, arg0)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_RewardManager *RewardManagerCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_RewardManager *RewardManagerSession) Owner() (common.Address, error) {
+	return _RewardManager.Contract.Owner(&_RewardManager.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_RewardManager *RewardManagerCallerSession) Owner() (common.Address, error) {
+	return _RewardManager.Contract.Owner(&_RewardManager.CallOpts)
+}
+
+// WeatherToken is a free data retrieval call binding the contract method 0xa460cc54.
+//
+// Solidity: function weatherToken() view returns(address)
+func (_RewardManager *RewardManagerCaller) WeatherToken(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _RewardManager.contract.Call(opts, &out, "weatherToken")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// WeatherToken is a free data retrieval call binding the contract method 0xa460cc54.
+//
+// Solidity: function weatherToken() view returns(address)
+func (_RewardManager *RewardManagerSession) WeatherToken() (common.Address, error) {
+	return _RewardManager.Contract.WeatherToken(&_RewardManager.CallOpts)
+}
+
+// WeatherToken is a free data retrieval call binding the contract method 0xa460cc54.
+//
+// Solidity: function weatherToken() view returns(address)
+func (_RewardManager *RewardManagerCallerSession) WeatherToken() (common.Address, error) {
+	return _RewardManager.Contract.WeatherToken(&_RewardManager.CallOpts)
+}
+
+// DistributeReward is a paid mutator transaction binding the contract method 0xd7ba4e33.
+//
+// Solidity: function distributeReward(bytes32 deviceId) returns()
+func (_RewardManager *RewardManagerTransactor) DistributeReward(opts *bind.TransactOpts, deviceId [32]byte) (*types.Transaction, error) {
+	return _RewardManager.contract.Transact(opts, "distributeReward", deviceId)
+}
+
+// DistributeReward is a paid mutator transaction binding the contract method 0xd7ba4e33.
+//
+// Solidity: function distributeReward(bytes32 deviceId) returns()
+func (_RewardManager *RewardManagerSession) DistributeReward(deviceId [32]byte) (*types.Transaction, error) {
+	return _RewardManager.Contract.DistributeReward(&_RewardManager.TransactOpts, deviceId)
+}
+
+// DistributeReward is a paid mutator transaction binding the contract method 0xd7ba4e33.
+//
+// Solidity: function distributeReward(bytes32 deviceId) returns()
+func (_RewardManager *RewardManagerTransactorSession) DistributeReward(deviceId [32]byte) (*types.Transaction, error) {
+	return _RewardManager.Contract.DistributeReward(&_RewardManager.TransactOpts, deviceId)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_RewardManager *RewardManagerTransactor) RenounceOwnership(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RewardManager.contract.Transact(opts, "renounceOwnership")
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_RewardManager *RewardManagerSession) RenounceOwnership() (*types.Transaction, error) {
+	return _RewardManager.Contract.RenounceOwnership(&_RewardManager.TransactOpts)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_RewardManager *RewardManagerTransactorSession) RenounceOwnership() (*types.Transaction, error) {
+	return _RewardManager.Contract.RenounceOwnership(&_RewardManager.TransactOpts)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_RewardManager *RewardManagerTransactor) TransferOwnership(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _RewardManager.contract.Transact(opts, "transferOwnership", newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_RewardManager *RewardManagerSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _RewardManager.Contract.TransferOwnership(&_RewardManager.TransactOpts, newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_RewardManager *RewardManagerTransactorSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _RewardManager.Contract.TransferOwnership(&_RewardManager.TransactOpts, newOwner)
+}
+
+// UpdateRewardConfig is a paid mutator transaction binding the contract method 0xca1abd46.
+//
+// Solidity: function updateRewardConfig(uint256 _baseReward, uint256 _bonusMultiplier, uint256 _bonusThreshold, uint256 _dailyRewardLimit) returns()
+func (_RewardManager *RewardManagerTransactor) UpdateRewardConfig(opts *bind.TransactOpts, _baseReward *big.Int, _bonusMultiplier *big.Int, _bonusThreshold *big.Int, _dailyRewardLimit *big.Int) (*types.Transaction, error) {
+	return _RewardManager.contract.Transact(opts, "updateRewardConfig", _baseReward, _bonusMultiplier, _bonusThreshold, _dailyRewardLimit)
+}
+
+// UpdateRewardConfig is a paid mutator transaction binding the contract method 0xca1abd46.
+//
+// Solidity: function updateRewardConfig(uint256 _baseReward, uint256 _bonusMultiplier, uint256 _bonusThreshold, uint256 _dailyRewardLimit) returns()
+func (_RewardManager *RewardManagerSession) UpdateRewardConfig(_baseReward *big.Int, _bonusMultiplier *big.Int, _bonusThreshold *big.Int, _dailyRewardLimit *big.Int) (*types.Transaction, error) {
+	return _RewardManager.Contract.UpdateRewardConfig(&_RewardManager.TransactOpts, _baseReward, _bonusMultiplier, _bonusThreshold, _dailyRewardLimit)
+}
+
+// UpdateRewardConfig is a paid mutator transaction binding the contract method 0xca1abd46.
+//
+// Solidity: function updateRewardConfig(uint256 _baseReward, uint256 _bonusMultiplier, uint256 _bonusThreshold, uint256 _dailyRewardLimit) returns()
+func (_RewardManager *RewardManagerTransactorSession) UpdateRewardConfig(_baseReward *big.Int, _bonusMultiplier *big.Int, _bonusThreshold *big.Int, _dailyRewardLimit *big.Int) (*types.Transaction, error) {
+	return _RewardManager.Contract.UpdateRewardConfig(&_RewardManager.TransactOpts, _baseReward, _bonusMultiplier, _bonusThreshold, _dailyRewardLimit)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x315a095d.
+//
+// Solidity: function withdrawTokens(uint256 amount) returns()
+func (_RewardManager *RewardManagerTransactor) WithdrawTokens(opts *bind.TransactOpts, amount *big.Int) (*types.Transaction, error) {
+	return _RewardManager.contract.Transact(opts, "withdrawTokens", amount)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x315a095d.
+//
+// Solidity: function withdrawTokens(uint256 amount) returns()
+func (_RewardManager *RewardManagerSession) WithdrawTokens(amount *big.Int) (*types.Transaction, error) {
+	return _RewardManager.Contract.WithdrawTokens(&_RewardManager.TransactOpts, amount)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x315a095d.
+//
+// Solidity: function withdrawTokens(uint256 amount) returns()
+func (_RewardManager *RewardManagerTransactorSession) WithdrawTokens(amount *big.Int) (*types.Transaction, error) {
+	return _RewardManager.Contract.WithdrawTokens(&_RewardManager.TransactOpts, amount)
+}
+
+// RewardManagerOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the RewardManager contract.
+type RewardManagerOwnershipTransferredIterator struct {
+	Event *RewardManagerOwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RewardManagerOwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RewardManagerOwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RewardManagerOwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RewardManagerOwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RewardManagerOwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RewardManagerOwnershipTransferred represents a OwnershipTransferred event raised by the RewardManager contract.
+type RewardManagerOwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_RewardManager *RewardManagerFilterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*RewardManagerOwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _RewardManager.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerOwnershipTransferredIterator{contract: _RewardManager.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_RewardManager *RewardManagerFilterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *RewardManagerOwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _RewardManager.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RewardManagerOwnershipTransferred)
+				if err := _RewardManager.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_RewardManager *RewardManagerFilterer) ParseOwnershipTransferred(log types.Log) (*RewardManagerOwnershipTransferred, error) {
+	event := new(RewardManagerOwnershipTransferred)
+	if err := _RewardManager.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RewardManagerRewardConfigUpdatedIterator is returned from FilterRewardConfigUpdated and is used to iterate over the raw logs and unpacked data for RewardConfigUpdated events raised by the RewardManager contract.
+type RewardManagerRewardConfigUpdatedIterator struct {
+	Event *RewardManagerRewardConfigUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RewardManagerRewardConfigUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RewardManagerRewardConfigUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RewardManagerRewardConfigUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RewardManagerRewardConfigUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RewardManagerRewardConfigUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RewardManagerRewardConfigUpdated represents a RewardConfigUpdated event raised by the RewardManager contract.
+type RewardManagerRewardConfigUpdated struct {
+	BaseReward      *big.Int
+	BonusMultiplier *big.Int
+	BonusThreshold  *big.Int
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterRewardConfigUpdated is a free log retrieval operation binding the contract event 0x81376037ed87033d6b5601a7c8806a8a7a2649525c23fe62315eb82c39c7e247.
+//
+// Solidity: event RewardConfigUpdated(uint256 baseReward, uint256 bonusMultiplier, uint256 bonusThreshold)
+func (_RewardManager *RewardManagerFilterer) FilterRewardConfigUpdated(opts *bind.FilterOpts) (*RewardManagerRewardConfigUpdatedIterator, error) {
+
+	logs, sub, err := _RewardManager.contract.FilterLogs(opts, "RewardConfigUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerRewardConfigUpdatedIterator{contract: _RewardManager.contract, event: "RewardConfigUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchRewardConfigUpdated is a free log subscription operation binding the contract event 0x81376037ed87033d6b5601a7c8806a8a7a2649525c23fe62315eb82c39c7e247.
+//
+// Solidity: event RewardConfigUpdated(uint256 baseReward, uint256 bonusMultiplier, uint256 bonusThreshold)
+func (_RewardManager *RewardManagerFilterer) WatchRewardConfigUpdated(opts *bind.WatchOpts, sink chan<- *RewardManagerRewardConfigUpdated) (event.Subscription, error) {
+
+	logs, sub, err := _RewardManager.contract.WatchLogs(opts, "RewardConfigUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RewardManagerRewardConfigUpdated)
+				if err := _RewardManager.contract.UnpackLog(event, "RewardConfigUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRewardConfigUpdated is a log parse operation binding the contract event 0x81376037ed87033d6b5601a7c8806a8a7a2649525c23fe62315eb82c39c7e247.
+//
+// Solidity: event RewardConfigUpdated(uint256 baseReward, uint256 bonusMultiplier, uint256 bonusThreshold)
+func (_RewardManager *RewardManagerFilterer) ParseRewardConfigUpdated(log types.Log) (*RewardManagerRewardConfigUpdated, error) {
+	event := new(RewardManagerRewardConfigUpdated)
+	if err := _RewardManager.contract.UnpackLog(event, "RewardConfigUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RewardManagerRewardDistributedIterator is returned from FilterRewardDistributed and is used to iterate over the raw logs and unpacked data for RewardDistributed events raised by the RewardManager contract.
+type RewardManagerRewardDistributedIterator struct {
+	Event *RewardManagerRewardDistributed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RewardManagerRewardDistributedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RewardManagerRewardDistributed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RewardManagerRewardDistributed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RewardManagerRewardDistributedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RewardManagerRewardDistributedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RewardManagerRewardDistributed represents a RewardDistributed event raised by the RewardManager contract.
+type RewardManagerRewardDistributed struct {
+	DeviceId  [32]byte
+	Recipient common.Address
+	Amount    *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterRewardDistributed is a free log retrieval operation binding the contract event 0x8fa6abe1348f6561b9a60363efc5793e820b343dce49d00e37d01644d9268a77.
+//
+// Solidity: event RewardDistributed(bytes32 indexed deviceId, address indexed recipient, uint256 amount)
+func (_RewardManager *RewardManagerFilterer) FilterRewardDistributed(opts *bind.FilterOpts, deviceId [][32]byte, recipient []common.Address) (*RewardManagerRewardDistributedIterator, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+
+	logs, sub, err := _RewardManager.contract.FilterLogs(opts, "RewardDistributed", deviceIdRule, recipientRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardManagerRewardDistributedIterator{contract: _RewardManager.contract, event: "RewardDistributed", logs: logs, sub: sub}, nil
+}
+
+// WatchRewardDistributed is a free log subscription operation binding the contract event 0x8fa6abe1348f6561b9a60363efc5793e820b343dce49d00e37d01644d9268a77.
+//
+// Solidity: event RewardDistributed(bytes32 indexed deviceId, address indexed recipient, uint256 amount)
+func (_RewardManager *RewardManagerFilterer) WatchRewardDistributed(opts *bind.WatchOpts, sink chan<- *RewardManagerRewardDistributed, deviceId [][32]byte, recipient []common.Address) (event.Subscription, error) {
+
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+
+	logs, sub, err := _RewardManager.contract.WatchLogs(opts, "RewardDistributed", deviceIdRule, recipientRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RewardManagerRewardDistributed)
+				if err := _RewardManager.contract.UnpackLog(event, "RewardDistributed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRewardDistributed is a log parse operation binding the contract event 0x8fa6abe1348f6561b9a60363efc5793e820b343dce49d00e37d01644d9268a77.
+//
+// Solidity: event RewardDistributed(bytes32 indexed deviceId, address indexed recipient, uint256 amount)
+func (_RewardManager *RewardManagerFilterer) ParseRewardDistributed(log types.Log) (*RewardManagerRewardDistributed, error) {
+	event := new(RewardManagerRewardDistributed)
+	if err := _RewardManager.contract.UnpackLog(event, "RewardDistributed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
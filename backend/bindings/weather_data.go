@@ -0,0 +1,1014 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// WeatherDataWeatherEntry is an auto generated low-level Go binding around an user-defined struct.
+type WeatherDataWeatherEntry struct {
+	DeviceId  [32]byte
+	IpfsHash  string
+	Timestamp *big.Int
+	DataHash  [32]byte
+	Verified  bool
+}
+
+// WeatherDataMetaData contains all meta data concerning the WeatherData contract.
+var WeatherDataMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"OwnableInvalidOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"OwnableUnauthorizedAccount\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"entryId\",\"type\":\"uint256\",\"indexed\":true}],\"name\":\"DataVerified\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true},{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"entryId\",\"type\":\"uint256\",\"indexed\":true},{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\",\"indexed\":true},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\",\"indexed\":false},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\",\"indexed\":false},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\",\"indexed\":false}],\"name\":\"WeatherDataSubmitted\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"deviceSubmissions\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"}],\"name\":\"getDeviceSubmissions\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"}],\"name\":\"getEntriesByTimeRange\",\"outputs\":[{\"internalType\":\"structWeatherData.WeatherEntry[]\",\"name\":\"\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"},{\"internalType\":\"bool\",\"name\":\"verified\",\"type\":\"bool\"}]}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"count\",\"type\":\"uint256\"}],\"name\":\"getLatestEntries\",\"outputs\":[{\"internalType\":\"structWeatherData.WeatherEntry[]\",\"name\":\"\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"},{\"internalType\":\"bool\",\"name\":\"verified\",\"type\":\"bool\"}]}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"entryId\",\"type\":\"uint256\"}],\"name\":\"getWeatherEntry\",\"outputs\":[{\"internalType\":\"structWeatherData.WeatherEntry\",\"name\":\"\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"},{\"internalType\":\"bool\",\"name\":\"verified\",\"type\":\"bool\"}]}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"}],\"name\":\"submitWeatherData\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalEntries\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"usedIPFSHashes\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"weatherEntries\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"deviceId\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"ipfsHash\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"},{\"internalType\":\"bool\",\"name\":\"verified\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// WeatherDataABI is the input ABI used to generate the binding from.
+// Deprecated: Use WeatherDataMetaData.ABI instead.
+var WeatherDataABI = WeatherDataMetaData.ABI
+
+// WeatherData is an auto generated Go binding around an Ethereum contract.
+type WeatherData struct {
+	WeatherDataCaller     // Read-only binding to the contract
+	WeatherDataTransactor // Write-only binding to the contract
+	WeatherDataFilterer   // Log filterer for contract events
+}
+
+// WeatherDataCaller is an auto generated read-only Go binding around an Ethereum contract.
+type WeatherDataCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WeatherDataTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type WeatherDataTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WeatherDataFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type WeatherDataFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WeatherDataSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type WeatherDataSession struct {
+	Contract     *WeatherData      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// WeatherDataCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type WeatherDataCallerSession struct {
+	Contract *WeatherDataCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// WeatherDataTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type WeatherDataTransactorSession struct {
+	Contract     *WeatherDataTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// WeatherDataRaw is an auto generated low-level Go binding around an Ethereum contract.
+type WeatherDataRaw struct {
+	Contract *WeatherData // Generic contract binding to access the raw methods on
+}
+
+// WeatherDataCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type WeatherDataCallerRaw struct {
+	Contract *WeatherDataCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// WeatherDataTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type WeatherDataTransactorRaw struct {
+	Contract *WeatherDataTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewWeatherData creates a new instance of WeatherData, bound to a specific deployed contract.
+func NewWeatherData(address common.Address, backend bind.ContractBackend) (*WeatherData, error) {
+	contract, err := bindWeatherData(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherData{WeatherDataCaller: WeatherDataCaller{contract: contract}, WeatherDataTransactor: WeatherDataTransactor{contract: contract}, WeatherDataFilterer: WeatherDataFilterer{contract: contract}}, nil
+}
+
+// NewWeatherDataCaller creates a new read-only instance of WeatherData, bound to a specific deployed contract.
+func NewWeatherDataCaller(address common.Address, caller bind.ContractCaller) (*WeatherDataCaller, error) {
+	contract, err := bindWeatherData(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataCaller{contract: contract}, nil
+}
+
+// NewWeatherDataTransactor creates a new write-only instance of WeatherData, bound to a specific deployed contract.
+func NewWeatherDataTransactor(address common.Address, transactor bind.ContractTransactor) (*WeatherDataTransactor, error) {
+	contract, err := bindWeatherData(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataTransactor{contract: contract}, nil
+}
+
+// NewWeatherDataFilterer creates a new log filterer instance of WeatherData, bound to a specific deployed contract.
+func NewWeatherDataFilterer(address common.Address, filterer bind.ContractFilterer) (*WeatherDataFilterer, error) {
+	contract, err := bindWeatherData(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataFilterer{contract: contract}, nil
+}
+
+// bindWeatherData binds a generic wrapper to an already deployed contract.
+func bindWeatherData(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := WeatherDataMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_WeatherData *WeatherDataRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _WeatherData.Contract.WeatherDataCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_WeatherData *WeatherDataRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _WeatherData.Contract.WeatherDataTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_WeatherData *WeatherDataRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _WeatherData.Contract.WeatherDataTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_WeatherData *WeatherDataCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _WeatherData.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_WeatherData *WeatherDataTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _WeatherData.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_WeatherData *WeatherDataTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _WeatherData.Contract.contract.Transact(opts, method, params...)
+}
+
+// DeviceSubmissions is a free data retrieval call binding the contract method 0xf1ea7dfe.
+//
+// Solidity: function deviceSubmissions(bytes32 , uint256 ) view returns(uint256)
+func (_WeatherData *WeatherDataCaller) DeviceSubmissions(opts *bind.CallOpts, arg0 [32]byte, arg1 *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "deviceSubmissions", arg0, arg1)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// DeviceSubmissions is a free data retrieval call binding the contract method 0xf1ea7dfe.
+//
+// Solidity: function deviceSubmissions(bytes32 , uint256 ) view returns(uint256)
+func (_WeatherData *WeatherDataSession) DeviceSubmissions(arg0 [32]byte, arg1 *big.Int) (*big.Int, error) {
+	return _WeatherData.Contract.DeviceSubmissions(&_WeatherData.CallOpts, arg0, arg1)
+}
+
+// DeviceSubmissions is a free data retrieval call binding the contract method 0xf1ea7dfe.
+//
+// Solidity: function deviceSubmissions(bytes32 , uint256 ) view returns(uint256)
+func (_WeatherData *WeatherDataCallerSession) DeviceSubmissions(arg0 [32]byte, arg1 *big.Int) (*big.Int, error) {
+	return _WeatherData.Contract.DeviceSubmissions(&_WeatherData.CallOpts, arg0, arg1)
+}
+
+// GetDeviceSubmissions is a free data retrieval call binding the contract method 0x87cc371a.
+//
+// Solidity: function getDeviceSubmissions(bytes32 deviceId) view returns(uint256[])
+func (_WeatherData *WeatherDataCaller) GetDeviceSubmissions(opts *bind.CallOpts, deviceId [32]byte) ([]*big.Int, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "getDeviceSubmissions", deviceId)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// GetDeviceSubmissions is a free data retrieval call binding the contract method 0x87cc371a.
+//
+// Solidity: function getDeviceSubmissions(bytes32 deviceId) view returns(uint256[])
+func (_WeatherData *WeatherDataSession) GetDeviceSubmissions(deviceId [32]byte) ([]*big.Int, error) {
+	return _WeatherData.Contract.GetDeviceSubmissions(&_WeatherData.CallOpts, deviceId)
+}
+
+// GetDeviceSubmissions is a free data retrieval call binding the contract method 0x87cc371a.
+//
+// Solidity: function getDeviceSubmissions(bytes32 deviceId) view returns(uint256[])
+func (_WeatherData *WeatherDataCallerSession) GetDeviceSubmissions(deviceId [32]byte) ([]*big.Int, error) {
+	return _WeatherData.Contract.GetDeviceSubmissions(&_WeatherData.CallOpts, deviceId)
+}
+
+// GetEntriesByTimeRange is a free data retrieval call binding the contract method 0x74dd75ab.
+//
+// Solidity: function getEntriesByTimeRange(uint256 startTime, uint256 endTime) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataCaller) GetEntriesByTimeRange(opts *bind.CallOpts, startTime *big.Int, endTime *big.Int) ([]WeatherDataWeatherEntry, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "getEntriesByTimeRange", startTime, endTime)
+
+	if err != nil {
+		return *new([]WeatherDataWeatherEntry), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]WeatherDataWeatherEntry)).(*[]WeatherDataWeatherEntry)
+
+	return out0, err
+
+}
+
+// GetEntriesByTimeRange is a free data retrieval call binding the contract method 0x74dd75ab.
+//
+// Solidity: function getEntriesByTimeRange(uint256 startTime, uint256 endTime) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataSession) GetEntriesByTimeRange(startTime *big.Int, endTime *big.Int) ([]WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetEntriesByTimeRange(&_WeatherData.CallOpts, startTime, endTime)
+}
+
+// GetEntriesByTimeRange is a free data retrieval call binding the contract method 0x74dd75ab.
+//
+// Solidity: function getEntriesByTimeRange(uint256 startTime, uint256 endTime) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataCallerSession) GetEntriesByTimeRange(startTime *big.Int, endTime *big.Int) ([]WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetEntriesByTimeRange(&_WeatherData.CallOpts, startTime, endTime)
+}
+
+// GetLatestEntries is a free data retrieval call binding the contract method 0x3d813822.
+//
+// Solidity: function getLatestEntries(uint256 count) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataCaller) GetLatestEntries(opts *bind.CallOpts, count *big.Int) ([]WeatherDataWeatherEntry, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "getLatestEntries", count)
+
+	if err != nil {
+		return *new([]WeatherDataWeatherEntry), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]WeatherDataWeatherEntry)).(*[]WeatherDataWeatherEntry)
+
+	return out0, err
+
+}
+
+// GetLatestEntries is a free data retrieval call binding the contract method 0x3d813822.
+//
+// Solidity: function getLatestEntries(uint256 count) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataSession) GetLatestEntries(count *big.Int) ([]WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetLatestEntries(&_WeatherData.CallOpts, count)
+}
+
+// GetLatestEntries is a free data retrieval call binding the contract method 0x3d813822.
+//
+// Solidity: function getLatestEntries(uint256 count) view returns((bytes32,string,uint256,bytes32,bool)[])
+func (_WeatherData *WeatherDataCallerSession) GetLatestEntries(count *big.Int) ([]WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetLatestEntries(&_WeatherData.CallOpts, count)
+}
+
+// GetWeatherEntry is a free data retrieval call binding the contract method 0x5400a049.
+//
+// Solidity: function getWeatherEntry(uint256 entryId) view returns((bytes32,string,uint256,bytes32,bool))
+func (_WeatherData *WeatherDataCaller) GetWeatherEntry(opts *bind.CallOpts, entryId *big.Int) (WeatherDataWeatherEntry, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "getWeatherEntry", entryId)
+
+	if err != nil {
+		return *new(WeatherDataWeatherEntry), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(WeatherDataWeatherEntry)).(*WeatherDataWeatherEntry)
+
+	return out0, err
+
+}
+
+// GetWeatherEntry is a free data retrieval call binding the contract method 0x5400a049.
+//
+// Solidity: function getWeatherEntry(uint256 entryId) view returns((bytes32,string,uint256,bytes32,bool))
+func (_WeatherData *WeatherDataSession) GetWeatherEntry(entryId *big.Int) (WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetWeatherEntry(&_WeatherData.CallOpts, entryId)
+}
+
+// GetWeatherEntry is a free data retrieval call binding the contract method 0x5400a049.
+//
+// Solidity: function getWeatherEntry(uint256 entryId) view returns((bytes32,string,uint256,bytes32,bool))
+func (_WeatherData *WeatherDataCallerSession) GetWeatherEntry(entryId *big.Int) (WeatherDataWeatherEntry, error) {
+	return _WeatherData.Contract.GetWeatherEntry(&_WeatherData.CallOpts, entryId)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_WeatherData *WeatherDataCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_WeatherData *WeatherDataSession) Owner() (common.Address, error) {
+	return _WeatherData.Contract.Owner(&_WeatherData.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_WeatherData *WeatherDataCallerSession) Owner() (common.Address, error) {
+	return _WeatherData.Contract.Owner(&_WeatherData.CallOpts)
+}
+
+// TotalEntries is a free data retrieval call binding the contract method 0x7fef036e.
+//
+// Solidity: function totalEntries() view returns(uint256)
+func (_WeatherData *WeatherDataCaller) TotalEntries(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "totalEntries")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalEntries is a free data retrieval call binding the contract method 0x7fef036e.
+//
+// Solidity: function totalEntries() view returns(uint256)
+func (_WeatherData *WeatherDataSession) TotalEntries() (*big.Int, error) {
+	return _WeatherData.Contract.TotalEntries(&_WeatherData.CallOpts)
+}
+
+// TotalEntries is a free data retrieval call binding the contract method 0x7fef036e.
+//
+// Solidity: function totalEntries() view returns(uint256)
+func (_WeatherData *WeatherDataCallerSession) TotalEntries() (*big.Int, error) {
+	return _WeatherData.Contract.TotalEntries(&_WeatherData.CallOpts)
+}
+
+// UsedIPFSHashes is a free data retrieval call binding the contract method 0x462f4a3e.
+//
+// Solidity: function usedIPFSHashes(string ) view returns(bool)
+func (_WeatherData *WeatherDataCaller) UsedIPFSHashes(opts *bind.CallOpts, arg0 string) (bool, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "usedIPFSHashes", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// UsedIPFSHashes is a free data retrieval call binding the contract method 0x462f4a3e.
+//
+// Solidity: function usedIPFSHashes(string ) view returns(bool)
+func (_WeatherData *WeatherDataSession) UsedIPFSHashes(arg0 string) (bool, error) {
+	return _WeatherData.Contract.UsedIPFSHashes(&_WeatherData.CallOpts, arg0)
+}
+
+// UsedIPFSHashes is a free data retrieval call binding the contract method 0x462f4a3e.
+//
+// Solidity: function usedIPFSHashes(string ) view returns(bool)
+func (_WeatherData *WeatherDataCallerSession) UsedIPFSHashes(arg0 string) (bool, error) {
+	return _WeatherData.Contract.UsedIPFSHashes(&_WeatherData.CallOpts, arg0)
+}
+
+// WeatherEntries is a free data retrieval call binding the contract method 0xe3251408.
+//
+// Solidity: function weatherEntries(uint256 ) view returns(bytes32 deviceId, string ipfsHash, uint256 timestamp, bytes32 dataHash, bool verified)
+func (_WeatherData *WeatherDataCaller) WeatherEntries(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	DeviceId  [32]byte
+	IpfsHash  string
+	Timestamp *big.Int
+	DataHash  [32]byte
+	Verified  bool
+}, error) {
+	var out []interface{}
+	err := _WeatherData.contract.Call(opts, &out, "weatherEntries", arg0)
+
+	outstruct := new(struct {
+		DeviceId  [32]byte
+		IpfsHash  string
+		Timestamp *big.Int
+		DataHash  [32]byte
+		Verified  bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.DeviceId = *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+	outstruct.IpfsHash = *abi.ConvertType(out[1], new(string)).(*string)
+	outstruct.Timestamp = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.DataHash = *abi.ConvertType(out[3], new([32]byte)).(*[32]byte)
+	outstruct.Verified = *abi.ConvertType(out[4], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// WeatherEntries is a free data retrieval call binding the contract method 0xe3251408.
+//
+// Solidity: function weatherEntries(uint256 ) view returns(bytes32 deviceId, string ipfsHash, uint256 timestamp, bytes32 dataHash, bool verified)
+func (_WeatherData *WeatherDataSession) WeatherEntries(arg0 *big.Int) (struct {
+	DeviceId  [32]byte
+	IpfsHash  string
+	Timestamp *big.Int
+	DataHash  [32]byte
+	Verified  bool
+}, error) {
+	return _WeatherData.Contract.WeatherEntries(&_WeatherData.CallOpts, arg0)
+}
+
+// WeatherEntries is a free data retrieval call binding the contract method 0xe3251408.
+//
+// Solidity: function weatherEntries(uint256 ) view returns(bytes32 deviceId, string ipfsHash, uint256 timestamp, bytes32 dataHash, bool verified)
+func (_WeatherData *WeatherDataCallerSession) WeatherEntries(arg0 *big.Int) (struct {
+	DeviceId  [32]byte
+	IpfsHash  string
+	Timestamp *big.Int
+	DataHash  [32]byte
+	Verified  bool
+}, error) {
+	return _WeatherData.Contract.WeatherEntries(&_WeatherData.CallOpts, arg0)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_WeatherData *WeatherDataTransactor) RenounceOwnership(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _WeatherData.contract.Transact(opts, "renounceOwnership")
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_WeatherData *WeatherDataSession) RenounceOwnership() (*types.Transaction, error) {
+	return _WeatherData.Contract.RenounceOwnership(&_WeatherData.TransactOpts)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_WeatherData *WeatherDataTransactorSession) RenounceOwnership() (*types.Transaction, error) {
+	return _WeatherData.Contract.RenounceOwnership(&_WeatherData.TransactOpts)
+}
+
+// SubmitWeatherData is a paid mutator transaction binding the contract method 0x3a20423a.
+//
+// Solidity: function submitWeatherData(bytes32 deviceId, string ipfsHash, bytes32 dataHash) returns(uint256)
+func (_WeatherData *WeatherDataTransactor) SubmitWeatherData(opts *bind.TransactOpts, deviceId [32]byte, ipfsHash string, dataHash [32]byte) (*types.Transaction, error) {
+	return _WeatherData.contract.Transact(opts, "submitWeatherData", deviceId, ipfsHash, dataHash)
+}
+
+// SubmitWeatherData is a paid mutator transaction binding the contract method 0x3a20423a.
+//
+// Solidity: function submitWeatherData(bytes32 deviceId, string ipfsHash, bytes32 dataHash) returns(uint256)
+func (_WeatherData *WeatherDataSession) SubmitWeatherData(deviceId [32]byte, ipfsHash string, dataHash [32]byte) (*types.Transaction, error) {
+	return _WeatherData.Contract.SubmitWeatherData(&_WeatherData.TransactOpts, deviceId, ipfsHash, dataHash)
+}
+
+// SubmitWeatherData is a paid mutator transaction binding the contract method 0x3a20423a.
+//
+// Solidity: function submitWeatherData(bytes32 deviceId, string ipfsHash, bytes32 dataHash) returns(uint256)
+func (_WeatherData *WeatherDataTransactorSession) SubmitWeatherData(deviceId [32]byte, ipfsHash string, dataHash [32]byte) (*types.Transaction, error) {
+	return _WeatherData.Contract.SubmitWeatherData(&_WeatherData.TransactOpts, deviceId, ipfsHash, dataHash)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_WeatherData *WeatherDataTransactor) TransferOwnership(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _WeatherData.contract.Transact(opts, "transferOwnership", newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_WeatherData *WeatherDataSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _WeatherData.Contract.TransferOwnership(&_WeatherData.TransactOpts, newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_WeatherData *WeatherDataTransactorSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _WeatherData.Contract.TransferOwnership(&_WeatherData.TransactOpts, newOwner)
+}
+
+// WeatherDataDataVerifiedIterator is returned from FilterDataVerified and is used to iterate over the raw logs and unpacked data for DataVerified events raised by the WeatherData contract.
+type WeatherDataDataVerifiedIterator struct {
+	Event *WeatherDataDataVerified // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *WeatherDataDataVerifiedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(WeatherDataDataVerified)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(WeatherDataDataVerified)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *WeatherDataDataVerifiedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *WeatherDataDataVerifiedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// WeatherDataDataVerified represents a DataVerified event raised by the WeatherData contract.
+type WeatherDataDataVerified struct {
+	EntryId *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterDataVerified is a free log retrieval operation binding the contract event 0xbaa01a83b8bf8ba5c980b540b40f20627440253b09406e6a36271f1dc8a2c6ce.
+//
+// Solidity: event DataVerified(uint256 indexed entryId)
+func (_WeatherData *WeatherDataFilterer) FilterDataVerified(opts *bind.FilterOpts, entryId []*big.Int) (*WeatherDataDataVerifiedIterator, error) {
+
+	var entryIdRule []interface{}
+	for _, entryIdItem := range entryId {
+		entryIdRule = append(entryIdRule, entryIdItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.FilterLogs(opts, "DataVerified", entryIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataDataVerifiedIterator{contract: _WeatherData.contract, event: "DataVerified", logs: logs, sub: sub}, nil
+}
+
+// WatchDataVerified is a free log subscription operation binding the contract event 0xbaa01a83b8bf8ba5c980b540b40f20627440253b09406e6a36271f1dc8a2c6ce.
+//
+// Solidity: event DataVerified(uint256 indexed entryId)
+func (_WeatherData *WeatherDataFilterer) WatchDataVerified(opts *bind.WatchOpts, sink chan<- *WeatherDataDataVerified, entryId []*big.Int) (event.Subscription, error) {
+
+	var entryIdRule []interface{}
+	for _, entryIdItem := range entryId {
+		entryIdRule = append(entryIdRule, entryIdItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.WatchLogs(opts, "DataVerified", entryIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(WeatherDataDataVerified)
+				if err := _WeatherData.contract.UnpackLog(event, "DataVerified", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDataVerified is a log parse operation binding the contract event 0xbaa01a83b8bf8ba5c980b540b40f20627440253b09406e6a36271f1dc8a2c6ce.
+//
+// Solidity: event DataVerified(uint256 indexed entryId)
+func (_WeatherData *WeatherDataFilterer) ParseDataVerified(log types.Log) (*WeatherDataDataVerified, error) {
+	event := new(WeatherDataDataVerified)
+	if err := _WeatherData.contract.UnpackLog(event, "DataVerified", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// WeatherDataOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the WeatherData contract.
+type WeatherDataOwnershipTransferredIterator struct {
+	Event *WeatherDataOwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *WeatherDataOwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(WeatherDataOwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(WeatherDataOwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *WeatherDataOwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *WeatherDataOwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// WeatherDataOwnershipTransferred represents a OwnershipTransferred event raised by the WeatherData contract.
+type WeatherDataOwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_WeatherData *WeatherDataFilterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*WeatherDataOwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataOwnershipTransferredIterator{contract: _WeatherData.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_WeatherData *WeatherDataFilterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *WeatherDataOwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(WeatherDataOwnershipTransferred)
+				if err := _WeatherData.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_WeatherData *WeatherDataFilterer) ParseOwnershipTransferred(log types.Log) (*WeatherDataOwnershipTransferred, error) {
+	event := new(WeatherDataOwnershipTransferred)
+	if err := _WeatherData.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// WeatherDataWeatherDataSubmittedIterator is returned from FilterWeatherDataSubmitted and is used to iterate over the raw logs and unpacked data for WeatherDataSubmitted events raised by the WeatherData contract.
+type WeatherDataWeatherDataSubmittedIterator struct {
+	Event *WeatherDataWeatherDataSubmitted // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *WeatherDataWeatherDataSubmittedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(WeatherDataWeatherDataSubmitted)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(WeatherDataWeatherDataSubmitted)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *WeatherDataWeatherDataSubmittedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *WeatherDataWeatherDataSubmittedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// WeatherDataWeatherDataSubmitted represents a WeatherDataSubmitted event raised by the WeatherData contract.
+type WeatherDataWeatherDataSubmitted struct {
+	EntryId   *big.Int
+	DeviceId  [32]byte
+	IpfsHash  string
+	DataHash  [32]byte
+	Timestamp *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterWeatherDataSubmitted is a free log retrieval operation binding the contract event 0x1153011c95419a83455c384ffc9a6e4fd808b6bc0c2ffe11e4de51c6dd28a5f4.
+//
+// Solidity: event WeatherDataSubmitted(uint256 indexed entryId, bytes32 indexed deviceId, string ipfsHash, bytes32 dataHash, uint256 timestamp)
+func (_WeatherData *WeatherDataFilterer) FilterWeatherDataSubmitted(opts *bind.FilterOpts, entryId []*big.Int, deviceId [][32]byte) (*WeatherDataWeatherDataSubmittedIterator, error) {
+
+	var entryIdRule []interface{}
+	for _, entryIdItem := range entryId {
+		entryIdRule = append(entryIdRule, entryIdItem)
+	}
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.FilterLogs(opts, "WeatherDataSubmitted", entryIdRule, deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherDataWeatherDataSubmittedIterator{contract: _WeatherData.contract, event: "WeatherDataSubmitted", logs: logs, sub: sub}, nil
+}
+
+// WatchWeatherDataSubmitted is a free log subscription operation binding the contract event 0x1153011c95419a83455c384ffc9a6e4fd808b6bc0c2ffe11e4de51c6dd28a5f4.
+//
+// Solidity: event WeatherDataSubmitted(uint256 indexed entryId, bytes32 indexed deviceId, string ipfsHash, bytes32 dataHash, uint256 timestamp)
+func (_WeatherData *WeatherDataFilterer) WatchWeatherDataSubmitted(opts *bind.WatchOpts, sink chan<- *WeatherDataWeatherDataSubmitted, entryId []*big.Int, deviceId [][32]byte) (event.Subscription, error) {
+
+	var entryIdRule []interface{}
+	for _, entryIdItem := range entryId {
+		entryIdRule = append(entryIdRule, entryIdItem)
+	}
+	var deviceIdRule []interface{}
+	for _, deviceIdItem := range deviceId {
+		deviceIdRule = append(deviceIdRule, deviceIdItem)
+	}
+
+	logs, sub, err := _WeatherData.contract.WatchLogs(opts, "WeatherDataSubmitted", entryIdRule, deviceIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(WeatherDataWeatherDataSubmitted)
+				if err := _WeatherData.contract.UnpackLog(event, "WeatherDataSubmitted", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWeatherDataSubmitted is a log parse operation binding the contract event 0x1153011c95419a83455c384ffc9a6e4fd808b6bc0c2ffe11e4de51c6dd28a5f4.
+//
+// Solidity: event WeatherDataSubmitted(uint256 indexed entryId, bytes32 indexed deviceId, string ipfsHash, bytes32 dataHash, uint256 timestamp)
+func (_WeatherData *WeatherDataFilterer) ParseWeatherDataSubmitted(log types.Log) (*WeatherDataWeatherDataSubmitted, error) {
+	event := new(WeatherDataWeatherDataSubmitted)
+	if err := _WeatherData.contract.UnpackLog(event, "WeatherDataSubmitted", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
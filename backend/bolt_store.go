@@ -0,0 +1,755 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	submissionsBucket       = []byte("submissions")
+	deviceSubmissionsBucket = []byte("device_submissions")
+	deviceTimelineBucket    = []byte("device_timeline")
+	submissionHashesBucket  = []byte("submission_hashes")
+	devicesBucket           = []byte("devices")
+	txJobsBucket            = []byte("tx_jobs")
+	pendingTxJobsBucket     = []byte("pending_tx_jobs")
+	submissionTxJobsBucket  = []byte("submission_tx_jobs")
+	pendingAnchorsBucket    = []byte("pending_anchors")
+	anchorBatchesBucket     = []byte("anchor_batches")
+	anchorIndexBucket       = []byte("anchor_index")
+	rewardsBucket           = []byte("rewards")
+	deviceRewardsBucket     = []byte("device_rewards")
+	reputationBucket        = []byte("reputation_history")
+	metaBucket              = []byte("meta")
+)
+
+var indexerCheckpointKey = []byte("indexer_checkpoint")
+
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		// Databases from before the timeline index get it built from the
+		// submissions they already hold.
+		buildTimeline := tx.Bucket(deviceTimelineBucket) == nil
+
+		for _, name := range [][]byte{
+			submissionsBucket, deviceSubmissionsBucket, deviceTimelineBucket, submissionHashesBucket, devicesBucket,
+			txJobsBucket, pendingTxJobsBucket, submissionTxJobsBucket,
+			pendingAnchorsBucket, anchorBatchesBucket, anchorIndexBucket,
+			rewardsBucket, deviceRewardsBucket, reputationBucket, metaBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		if buildTimeline {
+			return tx.Bucket(submissionsBucket).ForEach(func(k, v []byte) error {
+				var record SubmissionRecord
+				if err := json.Unmarshal(v, &record); err != nil {
+					return err
+				}
+				return putTimeline(tx, &record)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %v", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) SaveSubmission(record *SubmissionRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putSubmission(tx, record)
+	})
+}
+
+func (s *BoltStore) AcceptSubmission(record *SubmissionRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(submissionHashesBucket).Get([]byte(record.DataHash)) != nil {
+			return ErrDuplicateSubmission
+		}
+
+		device, err := getDevice(tx, record.DeviceID)
+		if err != nil {
+			return err
+		}
+		if record.Sequence <= device.LastSequence {
+			return ErrStaleSequence
+		}
+
+		if err := putSubmission(tx, record); err != nil {
+			return err
+		}
+
+		device.LastSubmission = record.ReceivedAt
+		device.LastSequence = record.Sequence
+		device.TotalSubmissions++
+		return putDevice(tx, device)
+	})
+}
+
+func (s *BoltStore) GetSubmission(id uint64) (*SubmissionRecord, error) {
+	var record SubmissionRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(submissionsBucket).Get(itob(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *BoltStore) ListSubmissions(filter SubmissionFilter) ([]SubmissionRecord, error) {
+	records := make([]SubmissionRecord, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		submissions := tx.Bucket(submissionsBucket)
+
+		keys := submissions
+		if filter.DeviceID != "" {
+			keys = tx.Bucket(deviceSubmissionsBucket).Bucket([]byte(filter.DeviceID))
+			if keys == nil {
+				return nil
+			}
+		}
+
+		reputations := make(map[string]float64)
+		c := keys.Cursor()
+		for k, _ := c.Last(); k != nil; k, _ = c.Prev() {
+			if filter.Limit > 0 && len(records) >= filter.Limit {
+				break
+			}
+
+			data := submissions.Get(k)
+			if data == nil {
+				continue
+			}
+
+			var record SubmissionRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			if record.ReceivedAt.Before(filter.ReceivedSince) {
+				break
+			}
+			if filter.ExcludeFlagged && record.Flagged() {
+				continue
+			}
+			if filter.MinReputation > 0 {
+				score, ok := reputations[record.DeviceID]
+				if !ok {
+					if device, err := getDevice(tx, record.DeviceID); err == nil && device.Reputation != nil {
+						score = device.Reputation.Score
+					}
+					reputations[record.DeviceID] = score
+				}
+				if score < filter.MinReputation {
+					continue
+				}
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+// FindPreviousSubmission returns the device's reading with the latest
+// timestamp before the given one, whatever order the readings arrived in.
+func (s *BoltStore) FindPreviousSubmission(deviceID string, before time.Time) (*SubmissionRecord, error) {
+	var record SubmissionRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		timeline := tx.Bucket(deviceTimelineBucket).Bucket([]byte(deviceID))
+		if timeline == nil {
+			return ErrNotFound
+		}
+
+		c := timeline.Cursor()
+		k, _ := c.Seek(timelineKey(before, 0))
+		if k == nil {
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+		if k == nil {
+			return ErrNotFound
+		}
+		return getJSON(tx.Bucket(submissionsBucket), k[8:], &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListDeviceSubmissionsBetween returns the device's readings taken from from
+// to to inclusive, oldest first, reading only that range of the timeline.
+func (s *BoltStore) ListDeviceSubmissionsBetween(deviceID string, from, to time.Time) ([]SubmissionRecord, error) {
+	records := make([]SubmissionRecord, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		timeline := tx.Bucket(deviceTimelineBucket).Bucket([]byte(deviceID))
+		if timeline == nil {
+			return nil
+		}
+
+		submissions := tx.Bucket(submissionsBucket)
+		end := timelineKey(to, math.MaxUint64)
+		c := timeline.Cursor()
+		for k, _ := c.Seek(timelineKey(from, 0)); k != nil && bytes.Compare(k, end) <= 0; k, _ = c.Next() {
+			var record SubmissionRecord
+			if err := getJSON(submissions, k[8:], &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+func (s *BoltStore) FindSubmissionByHash(dataHash string) (*SubmissionRecord, error) {
+	var record SubmissionRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(submissionHashesBucket).Get([]byte(dataHash))
+		if id == nil {
+			return ErrNotFound
+		}
+		return getJSON(tx.Bucket(submissionsBucket), id, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *BoltStore) SaveDevice(device *DeviceRegistration) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putDevice(tx, device)
+	})
+}
+
+func (s *BoltStore) GetDevice(deviceID string) (*DeviceRegistration, error) {
+	var device *DeviceRegistration
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		device, err = getDevice(tx, deviceID)
+		return err
+	})
+	return device, err
+}
+
+func (s *BoltStore) ListDevices() ([]DeviceRegistration, error) {
+	devices := make([]DeviceRegistration, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(devicesBucket).ForEach(func(_, data []byte) error {
+			var device DeviceRegistration
+			if err := json.Unmarshal(data, &device); err != nil {
+				return err
+			}
+			devices = append(devices, device)
+			return nil
+		})
+	})
+
+	return devices, err
+}
+
+func (s *BoltStore) UpdateDevice(deviceID string, update func(device *DeviceRegistration) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		device, err := getDevice(tx, deviceID)
+		if err != nil {
+			return err
+		}
+		if err := update(device); err != nil {
+			return err
+		}
+		return putDevice(tx, device)
+	})
+}
+
+func (s *BoltStore) SaveTxJob(job *TxJob) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putTxJob(tx, job)
+	})
+}
+
+func (s *BoltStore) GetTxJob(id uint64) (*TxJob, error) {
+	var job TxJob
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(txJobsBucket).Get(itob(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *BoltStore) ListPendingTxJobs() ([]TxJob, error) {
+	return s.listTxJobs(func(tx *bolt.Tx) *bolt.Bucket {
+		return tx.Bucket(pendingTxJobsBucket)
+	})
+}
+
+func (s *BoltStore) ListSubmissionTxJobs(submissionID uint64) ([]TxJob, error) {
+	return s.listTxJobs(func(tx *bolt.Tx) *bolt.Bucket {
+		return tx.Bucket(submissionTxJobsBucket).Bucket(itob(submissionID))
+	})
+}
+
+func (s *BoltStore) listTxJobs(index func(tx *bolt.Tx) *bolt.Bucket) ([]TxJob, error) {
+	jobs := make([]TxJob, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		keys := index(tx)
+		if keys == nil {
+			return nil
+		}
+
+		all := tx.Bucket(txJobsBucket)
+		return keys.ForEach(func(k, _ []byte) error {
+			data := all.Get(k)
+			if data == nil {
+				return nil
+			}
+
+			var job TxJob
+			if err := json.Unmarshal(data, &job); err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+			return nil
+		})
+	})
+
+	return jobs, err
+}
+
+func (s *BoltStore) AddPendingAnchor(anchor PendingAnchor) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(anchor)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(pendingAnchorsBucket).Put(itob(anchor.SubmissionID), data)
+	})
+}
+
+func (s *BoltStore) ListPendingAnchors() ([]PendingAnchor, error) {
+	anchors := make([]PendingAnchor, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(pendingAnchorsBucket).ForEach(func(_, data []byte) error {
+			var anchor PendingAnchor
+			if err := json.Unmarshal(data, &anchor); err != nil {
+				return err
+			}
+			anchors = append(anchors, anchor)
+			return nil
+		})
+	})
+
+	return anchors, err
+}
+
+// CreateAnchorBatch stores a batch together with the transaction jobs that
+// anchor it, the first of which becomes the batch's TxJobID, and removes its
+// submissions from the pending anchors.
+func (s *BoltStore) CreateAnchorBatch(batch *AnchorBatch, jobs []*TxJob) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		batches := tx.Bucket(anchorBatchesBucket)
+
+		id, err := batches.NextSequence()
+		if err != nil {
+			return err
+		}
+		batch.ID = id
+
+		for _, job := range jobs {
+			job.BatchID = batch.ID
+			if err := putTxJob(tx, job); err != nil {
+				return err
+			}
+		}
+		if len(jobs) > 0 {
+			batch.TxJobID = jobs[0].ID
+		}
+
+		if err := putJSON(batches, itob(batch.ID), batch); err != nil {
+			return err
+		}
+
+		pending := tx.Bucket(pendingAnchorsBucket)
+		index := tx.Bucket(anchorIndexBucket)
+		submissions := tx.Bucket(submissionsBucket)
+
+		for i, submissionID := range batch.SubmissionIDs {
+			key := itob(submissionID)
+			if err := pending.Delete(key); err != nil {
+				return err
+			}
+			if err := index.Put([]byte(batch.DataHashes[i]), itob(batch.ID)); err != nil {
+				return err
+			}
+
+			data := submissions.Get(key)
+			if data == nil {
+				continue
+			}
+
+			var record SubmissionRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			record.BatchID = batch.ID
+			if err := putJSON(submissions, key, record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *BoltStore) SaveAnchorBatch(batch *AnchorBatch) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(anchorBatchesBucket), itob(batch.ID), batch)
+	})
+}
+
+func (s *BoltStore) GetAnchorBatch(id uint64) (*AnchorBatch, error) {
+	var batch AnchorBatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(anchorBatchesBucket), itob(id), &batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *BoltStore) FindAnchorBatch(dataHash string) (*AnchorBatch, error) {
+	var batch AnchorBatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(anchorIndexBucket).Get([]byte(dataHash))
+		if id == nil {
+			return ErrNotFound
+		}
+		return getJSON(tx.Bucket(anchorBatchesBucket), id, &batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *BoltStore) SaveReward(reward *RewardRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		rewards := tx.Bucket(rewardsBucket)
+
+		if reward.ID == 0 {
+			id, err := rewards.NextSequence()
+			if err != nil {
+				return err
+			}
+			reward.ID = id
+		}
+
+		if err := putJSON(rewards, itob(reward.ID), reward); err != nil {
+			return err
+		}
+
+		index, err := tx.Bucket(deviceRewardsBucket).CreateBucketIfNotExists([]byte(reward.DeviceID))
+		if err != nil {
+			return err
+		}
+		return index.Put(itob(uint64(reward.Period)), itob(reward.ID))
+	})
+}
+
+func (s *BoltStore) GetReward(id uint64) (*RewardRecord, error) {
+	var reward RewardRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(rewardsBucket), itob(id), &reward)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+func (s *BoltStore) FindReward(deviceID string, period int64) (*RewardRecord, error) {
+	var reward RewardRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(deviceRewardsBucket).Bucket([]byte(deviceID))
+		if index == nil {
+			return ErrNotFound
+		}
+
+		id := index.Get(itob(uint64(period)))
+		if id == nil {
+			return ErrNotFound
+		}
+		return getJSON(tx.Bucket(rewardsBucket), id, &reward)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+func (s *BoltStore) ListDeviceRewards(deviceID string) ([]RewardRecord, error) {
+	rewards := make([]RewardRecord, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(deviceRewardsBucket).Bucket([]byte(deviceID))
+		if index == nil {
+			return nil
+		}
+
+		all := tx.Bucket(rewardsBucket)
+		c := index.Cursor()
+		for k, id := c.Last(); k != nil; k, id = c.Prev() {
+			var reward RewardRecord
+			if err := getJSON(all, id, &reward); err != nil {
+				return err
+			}
+			rewards = append(rewards, reward)
+		}
+		return nil
+	})
+
+	return rewards, err
+}
+
+// SaveReputation appends to the device's reputation history and makes the
+// snapshot its current reputation.
+func (s *BoltStore) SaveReputation(reputation *Reputation) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		device, err := getDevice(tx, reputation.DeviceID)
+		if err != nil {
+			return err
+		}
+
+		history, err := tx.Bucket(reputationBucket).CreateBucketIfNotExists([]byte(reputation.DeviceID))
+		if err != nil {
+			return err
+		}
+		if err := putJSON(history, itob(uint64(reputation.ComputedAt.UnixNano())), reputation); err != nil {
+			return err
+		}
+
+		device.Reputation = reputation
+		return putDevice(tx, device)
+	})
+}
+
+func (s *BoltStore) ListReputationHistory(deviceID string, limit int) ([]Reputation, error) {
+	reputations := make([]Reputation, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		history := tx.Bucket(reputationBucket).Bucket([]byte(deviceID))
+		if history == nil {
+			return nil
+		}
+
+		c := history.Cursor()
+		for k, v := c.Last(); k != nil && len(reputations) < limit; k, v = c.Prev() {
+			var reputation Reputation
+			if err := json.Unmarshal(v, &reputation); err != nil {
+				return err
+			}
+			reputations = append(reputations, reputation)
+		}
+		return nil
+	})
+
+	return reputations, err
+}
+
+func (s *BoltStore) GetIndexerCheckpoint() (*IndexerCheckpoint, error) {
+	var checkpoint IndexerCheckpoint
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(metaBucket), indexerCheckpointKey, &checkpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (s *BoltStore) SaveIndexerCheckpoint(checkpoint *IndexerCheckpoint) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(metaBucket), indexerCheckpointKey, checkpoint)
+	})
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+func getJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	data := bucket.Get(key)
+	if data == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, value)
+}
+
+func putSubmission(tx *bolt.Tx, record *SubmissionRecord) error {
+	submissions := tx.Bucket(submissionsBucket)
+
+	if record.ID == 0 {
+		id, err := submissions.NextSequence()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+	}
+
+	if err := putJSON(submissions, itob(record.ID), record); err != nil {
+		return err
+	}
+
+	if record.DataHash != "" {
+		if err := tx.Bucket(submissionHashesBucket).Put([]byte(record.DataHash), itob(record.ID)); err != nil {
+			return err
+		}
+	}
+
+	index, err := tx.Bucket(deviceSubmissionsBucket).CreateBucketIfNotExists([]byte(record.DeviceID))
+	if err != nil {
+		return err
+	}
+	if err := index.Put(itob(record.ID), nil); err != nil {
+		return err
+	}
+	return putTimeline(tx, record)
+}
+
+// putTimeline indexes a submission under its device by reading timestamp, so
+// readings can be found by when they were taken rather than when they
+// arrived.
+func putTimeline(tx *bolt.Tx, record *SubmissionRecord) error {
+	timeline, err := tx.Bucket(deviceTimelineBucket).CreateBucketIfNotExists([]byte(record.DeviceID))
+	if err != nil {
+		return err
+	}
+	return timeline.Put(timelineKey(record.Timestamp, record.ID), nil)
+}
+
+// timelineKey orders by timestamp, then by ID. Flipping the sign bit keeps
+// timestamps before 1970 in order.
+func timelineKey(timestamp time.Time, id uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(timestamp.UnixNano())^1<<63)
+	binary.BigEndian.PutUint64(key[8:], id)
+	return key
+}
+
+func getDevice(tx *bolt.Tx, deviceID string) (*DeviceRegistration, error) {
+	data := tx.Bucket(devicesBucket).Get([]byte(deviceID))
+	if data == nil {
+		return nil, ErrNotFound
+	}
+
+	var device DeviceRegistration
+	if err := json.Unmarshal(data, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func putDevice(tx *bolt.Tx, device *DeviceRegistration) error {
+	data, err := json.Marshal(device)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(devicesBucket).Put([]byte(device.DeviceID), data)
+}
+
+func putTxJob(tx *bolt.Tx, job *TxJob) error {
+	jobs := tx.Bucket(txJobsBucket)
+
+	if job.ID == 0 {
+		id, err := jobs.NextSequence()
+		if err != nil {
+			return err
+		}
+		job.ID = id
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	key := itob(job.ID)
+	if err := jobs.Put(key, data); err != nil {
+		return err
+	}
+
+	pending := tx.Bucket(pendingTxJobsBucket)
+	if job.Done() {
+		err = pending.Delete(key)
+	} else {
+		err = pending.Put(key, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if job.SubmissionID == 0 {
+		return nil
+	}
+
+	index, err := tx.Bucket(submissionTxJobsBucket).CreateBucketIfNotExists(itob(job.SubmissionID))
+	if err != nil {
+		return err
+	}
+	return index.Put(key, nil)
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
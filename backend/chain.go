@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"weather-backend/bindings"
+)
+
+const (
+	txKindSubmitWeatherData = "submit_weather_data"
+	txKindRecordSubmission  = "record_submission"
+	txKindAnchorBatch       = "anchor_batch"
+	txKindDistributeReward  = "distribute_reward"
+)
+
+type ChainBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+type ChainClient struct {
+	backend            ChainBackend
+	weatherDataAddr    common.Address
+	deviceRegistryAddr common.Address
+	rewardManagerAddr  common.Address
+	weatherData        *bindings.WeatherData
+	deviceRegistry     *bindings.DeviceRegistry
+	rewardManager      *bindings.RewardManager
+	weatherDataABI     *abi.ABI
+	deviceRegistryABI  *abi.ABI
+	rewardManagerABI   *abi.ABI
+}
+
+func NewChainClient(backend ChainBackend, weatherDataAddr, deviceRegistryAddr, rewardManagerAddr common.Address) (*ChainClient, error) {
+	weatherData, err := bindings.NewWeatherData(weatherDataAddr, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind WeatherData contract: %v", err)
+	}
+
+	deviceRegistry, err := bindings.NewDeviceRegistry(deviceRegistryAddr, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DeviceRegistry contract: %v", err)
+	}
+
+	weatherDataABI, err := bindings.WeatherDataMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse WeatherData ABI: %v", err)
+	}
+
+	deviceRegistryABI, err := bindings.DeviceRegistryMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DeviceRegistry ABI: %v", err)
+	}
+
+	client := &ChainClient{
+		backend:            backend,
+		weatherDataAddr:    weatherDataAddr,
+		deviceRegistryAddr: deviceRegistryAddr,
+		rewardManagerAddr:  rewardManagerAddr,
+		weatherData:        weatherData,
+		deviceRegistry:     deviceRegistry,
+		weatherDataABI:     weatherDataABI,
+		deviceRegistryABI:  deviceRegistryABI,
+	}
+
+	if rewardManagerAddr != (common.Address{}) {
+		client.rewardManager, err = bindings.NewRewardManager(rewardManagerAddr, backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind RewardManager contract: %v", err)
+		}
+
+		client.rewardManagerABI, err = bindings.RewardManagerMetaData.GetAbi()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RewardManager ABI: %v", err)
+		}
+	}
+
+	return client, nil
+}
+
+func (c *ChainClient) HasRewardManager() bool {
+	return c.rewardManager != nil
+}
+
+func (c *ChainClient) SubmitWeatherDataJob(submissionID uint64, deviceID [32]byte, ipfsHash string, dataHash [32]byte) (*TxJob, error) {
+	data, err := c.weatherDataABI.Pack("submitWeatherData", deviceID, ipfsHash, dataHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode submitWeatherData call: %v", err)
+	}
+
+	return &TxJob{
+		Kind:         txKindSubmitWeatherData,
+		SubmissionID: submissionID,
+		To:           c.weatherDataAddr,
+		Data:         data,
+	}, nil
+}
+
+func (c *ChainClient) RecordSubmissionJob(submissionID uint64, deviceID [32]byte) (*TxJob, error) {
+	data, err := c.deviceRegistryABI.Pack("recordSubmission", deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recordSubmission call: %v", err)
+	}
+
+	return &TxJob{
+		Kind:         txKindRecordSubmission,
+		SubmissionID: submissionID,
+		To:           c.deviceRegistryAddr,
+		Data:         data,
+	}, nil
+}
+
+func (c *ChainClient) ParseEntryID(receipt *types.Receipt) (*big.Int, error) {
+	for _, log := range receipt.Logs {
+		if log.Address != c.weatherDataAddr {
+			continue
+		}
+
+		event, err := c.weatherData.ParseWeatherDataSubmitted(*log)
+		if err == nil {
+			return event.EntryId, nil
+		}
+	}
+
+	return nil, fmt.Errorf("WeatherDataSubmitted event not found in transaction %s", receipt.TxHash.Hex())
+}
+
+func (c *ChainClient) DistributeRewardJob(rewardID uint64, deviceID [32]byte) (*TxJob, error) {
+	data, err := c.rewardManagerABI.Pack("distributeReward", deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode distributeReward call: %v", err)
+	}
+
+	return &TxJob{
+		Kind:     txKindDistributeReward,
+		RewardID: rewardID,
+		To:       c.rewardManagerAddr,
+		Data:     data,
+	}, nil
+}
+
+func (c *ChainClient) RemainingDailyRewards(ctx context.Context) (*big.Int, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	limit, err := c.rewardManager.DailyRewardLimit(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dailyRewardLimit: %v", err)
+	}
+
+	distributed, err := c.rewardManager.GetCurrentDayRewards(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read getCurrentDayRewards: %v", err)
+	}
+
+	return new(big.Int).Sub(limit, distributed), nil
+}
+
+func (c *ChainClient) CalculateReward(ctx context.Context, deviceID [32]byte) (*big.Int, error) {
+	amount, err := c.rewardManager.CalculateReward(&bind.CallOpts{Context: ctx}, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calculateReward: %v", err)
+	}
+	return amount, nil
+}
+
+func (c *ChainClient) ParseRewardAmount(receipt *types.Receipt) (*big.Int, error) {
+	for _, log := range receipt.Logs {
+		if log.Address != c.rewardManagerAddr {
+			continue
+		}
+
+		event, err := c.rewardManager.ParseRewardDistributed(*log)
+		if err == nil {
+			return event.Amount, nil
+		}
+	}
+
+	return nil, fmt.Errorf("RewardDistributed event not found in transaction %s", receipt.TxHash.Hex())
+}
+
+func (c *ChainClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.backend.HeaderByNumber(ctx, number)
+}
+
+func (c *ChainClient) FilterLogs(ctx context.Context, fromBlock, toBlock uint64) ([]types.Log, error) {
+	addresses := []common.Address{c.deviceRegistryAddr, c.weatherDataAddr}
+	if c.rewardManager != nil {
+		addresses = append(addresses, c.rewardManagerAddr)
+	}
+
+	return c.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+	})
+}
+
+func (c *ChainClient) EventName(log types.Log) string {
+	if len(log.Topics) == 0 {
+		return ""
+	}
+
+	var contractABI *abi.ABI
+	switch log.Address {
+	case c.deviceRegistryAddr:
+		contractABI = c.deviceRegistryABI
+	case c.weatherDataAddr:
+		contractABI = c.weatherDataABI
+	case c.rewardManagerAddr:
+		contractABI = c.rewardManagerABI
+	}
+	if contractABI == nil {
+		return ""
+	}
+
+	event, err := contractABI.EventByID(log.Topics[0])
+	if err != nil {
+		return ""
+	}
+	return event.Name
+}
+
+func (c *ChainClient) ParseWeatherDataSubmitted(log types.Log) (*bindings.WeatherDataWeatherDataSubmitted, error) {
+	return c.weatherData.ParseWeatherDataSubmitted(log)
+}
+
+func (c *ChainClient) GetDevice(ctx context.Context, deviceID [32]byte) (bindings.DeviceRegistryDevice, error) {
+	return c.deviceRegistry.GetDevice(&bind.CallOpts{Context: ctx}, deviceID)
+}
+
+func (c *ChainClient) DeviceEarnings(ctx context.Context, deviceID [32]byte) (*big.Int, error) {
+	return c.rewardManager.DeviceEarnings(&bind.CallOpts{Context: ctx}, deviceID)
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/program"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+
+	"weather-backend/bindings"
+)
+
+// The bindings are generated from the ABIs alone, so the test deploys stub
+// contracts behind them. weatherDataStub emits WeatherDataSubmitted with an
+// incrementing entry ID for every call, as submitWeatherData does.
+func weatherDataStub(event abi.Event) []byte {
+	runtime := program.New().
+		// entryId = totalEntries++
+		Push(0).Op(vm.SLOAD, vm.DUP1).Push(1).Op(vm.ADD).Push(0).Op(vm.SSTORE).
+		// Log data is (ipfsHash, dataHash, block.timestamp), with the string
+		// copied from the calldata.
+		Push(0x60).Push(0).Op(vm.MSTORE).
+		Push(0x44).Op(vm.CALLDATALOAD).Push(0x20).Op(vm.MSTORE).
+		Op(vm.TIMESTAMP).Push(0x40).Op(vm.MSTORE).
+		Push(0x64).Op(vm.CALLDATASIZE, vm.SUB).Push(0x64).Push(0x60).Op(vm.CALLDATACOPY).
+		// Topics are the event ID, entryId and deviceId.
+		Push(4).Op(vm.CALLDATALOAD, vm.SWAP1).Push(event.ID).
+		Push(4).Op(vm.CALLDATASIZE, vm.SUB).Push(0).Op(vm.LOG3, vm.STOP)
+
+	return program.New().ReturnViaCodeCopy(runtime.Bytes()).Bytes()
+}
+
+// deviceRegistryStub reverts recordSubmission for every device but the one it
+// is deployed with, as the registry does for devices that are not active.
+func deviceRegistryStub(active [32]byte) []byte {
+	revert := program.New().Push(0).Push(0).Op(vm.REVERT).Bytes()
+
+	runtime := program.New().Push(4).Op(vm.CALLDATALOAD, vm.SLOAD)
+	ok := runtime.Size() + 3 + len(revert) // past PUSH1, JUMPI and the revert
+	runtime.Push(ok).Op(vm.JUMPI).Append(revert).Op(vm.JUMPDEST, vm.STOP)
+
+	return program.New().Sstore(active[:], 1).ReturnViaCodeCopy(runtime.Bytes()).Bytes()
+}
+
+// stubMethod is the body a stub runs for one of its contract's methods.
+type stubMethod struct {
+	name string
+	body *program.Program
+}
+
+// dispatchStub returns the creation code of a contract that runs the body
+// registered for the calldata's selector and reverts for any other. Entries
+// have a fixed size so the body offsets are known up front.
+func dispatchStub(parsed *abi.ABI, methods []stubMethod) []byte {
+	const entrySize = 11 // DUP1, PUSH4 selector, EQ, PUSH2 dest, JUMPI
+	runtime := program.New().Push(0).Op(vm.CALLDATALOAD).Push(0xe0).Op(vm.SHR)
+	revert := program.New().Push(0).Push(0).Op(vm.REVERT).Bytes()
+
+	dest := runtime.Size() + entrySize*len(methods) + len(revert)
+	var bodies []byte
+	for _, method := range methods {
+		runtime.Op(vm.DUP1, vm.PUSH4).Append(parsed.Methods[method.name].ID).
+			Op(vm.EQ, vm.PUSH2).Append([]byte{byte(dest >> 8), byte(dest)}).Op(vm.JUMPI)
+		body := append([]byte{byte(vm.JUMPDEST)}, method.body.Bytes()...)
+		bodies = append(bodies, body...)
+		dest += len(body)
+	}
+	runtime.Append(revert).Append(bodies)
+
+	return program.New().ReturnViaCodeCopy(runtime.Bytes()).Bytes()
+}
+
+// rewardManagerStub pays a fixed amount per distributeReward call against a
+// fixed daily limit, emitting RewardDistributed to the caller as the reward
+// manager does to the device owner.
+func rewardManagerStub(parsed *abi.ABI, limit, amount *big.Int) []byte {
+	returnWord := func(body *program.Program) *program.Program {
+		return body.Push(0).Op(vm.MSTORE).Return(0, 32)
+	}
+
+	return dispatchStub(parsed, []stubMethod{
+		{"dailyRewardLimit", returnWord(program.New().Push(limit))},
+		{"getCurrentDayRewards", returnWord(program.New().Push(0).Op(vm.SLOAD))},
+		{"calculateReward", returnWord(program.New().Push(amount))},
+		{"distributeReward", program.New().
+			// distributed += amount
+			Push(amount).Push(0).Op(vm.SLOAD, vm.ADD).Push(0).Op(vm.SSTORE).
+			// Topics are the event ID, deviceId and the caller, the data the
+			// amount.
+			Push(amount).Push(0).Op(vm.MSTORE).
+			Op(vm.CALLER).Push(4).Op(vm.CALLDATALOAD).Push(parsed.Events["RewardDistributed"].ID).
+			Push(32).Push(0).Op(vm.LOG3, vm.STOP)},
+	})
+}
+
+// indexedRegistryStub emits SubmissionRecorded for every recordSubmission
+// call and answers getDevice with device, whatever the ID asked for.
+func indexedRegistryStub(t *testing.T, parsed *abi.ABI, device bindings.DeviceRegistryDevice) []byte {
+	t.Helper()
+
+	encoded, err := parsed.Methods["getDevice"].Outputs.Pack(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dispatchStub(parsed, []stubMethod{
+		{"getDevice", program.New().ReturnData(encoded)},
+		{"recordSubmission", program.New().
+			Op(vm.TIMESTAMP).Push(0).Op(vm.MSTORE).
+			Push(4).Op(vm.CALLDATALOAD).Push(parsed.Events["SubmissionRecorded"].ID).
+			Push(32).Push(0).Op(vm.LOG2, vm.STOP)},
+	})
+}
+
+func deployStub(t *testing.T, sim *simulated.Backend, auth *bind.TransactOpts, metadata *bind.MetaData, code []byte, params ...interface{}) common.Address {
+	t.Helper()
+
+	parsed, err := metadata.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, _, err := bind.DeployContract(auth, *parsed, code, sim.Client(), params...)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+	return address
+}
+
+// testChain is a simulated chain with the stub contracts deployed and a
+// funded account to send from.
+type testChain struct {
+	sim          *simulated.Backend
+	auth         *bind.TransactOpts
+	client       *ChainClient
+	registered   [32]byte
+	unregistered [32]byte
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, params.AllDevChainProtocolChanges.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		auth.From: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(100))},
+	})
+	t.Cleanup(func() { sim.Close() })
+
+	chain := &testChain{
+		sim:          sim,
+		auth:         auth,
+		registered:   crypto.Keccak256Hash([]byte("registered device")),
+		unregistered: crypto.Keccak256Hash([]byte("unregistered device")),
+	}
+
+	weatherDataABI, err := bindings.WeatherDataMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	weatherDataAddr := deployStub(t, sim, auth, bindings.WeatherDataMetaData,
+		weatherDataStub(weatherDataABI.Events["WeatherDataSubmitted"]))
+	deviceRegistryAddr := deployStub(t, sim, auth, bindings.DeviceRegistryMetaData,
+		deviceRegistryStub(chain.registered))
+
+	chain.client, err = NewChainClient(sim.Client(), weatherDataAddr, deviceRegistryAddr, common.Address{})
+	if err != nil {
+		t.Fatalf("NewChainClient: %v", err)
+	}
+	return chain
+}
+
+// withRewardManager deploys a reward manager paying amount per reward up to
+// limit a day, and rebinds the chain client to it.
+func (c *testChain) withRewardManager(t *testing.T, limit, amount *big.Int) {
+	t.Helper()
+
+	parsed, err := bindings.RewardManagerMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The constructor's token and registry addresses are unused by the stub.
+	address := deployStub(t, c.sim, c.auth, bindings.RewardManagerMetaData, rewardManagerStub(parsed, limit, amount),
+		common.Address{}, c.client.deviceRegistryAddr)
+
+	c.client, err = NewChainClient(c.sim.Client(), c.client.weatherDataAddr, c.client.deviceRegistryAddr, address)
+	if err != nil {
+		t.Fatalf("NewChainClient: %v", err)
+	}
+}
+
+// withIndexedRegistry replaces the device registry with one describing
+// device for every ID, and rebinds the chain client to it.
+func (c *testChain) withIndexedRegistry(t *testing.T, device bindings.DeviceRegistryDevice) {
+	t.Helper()
+
+	parsed, err := bindings.DeviceRegistryMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := deployStub(t, c.sim, c.auth, bindings.DeviceRegistryMetaData, indexedRegistryStub(t, parsed, device))
+
+	c.client, err = NewChainClient(c.sim.Client(), c.client.weatherDataAddr, address, c.client.rewardManagerAddr)
+	if err != nil {
+		t.Fatalf("NewChainClient: %v", err)
+	}
+}
+
+// newQueue returns a queue sending through backend, which defaults to the
+// simulated chain, with its jobs in store.
+func (c *testChain) newQueue(backend ChainBackend, store TxJobRepository, maxAttempts int) *TxQueue {
+	if backend == nil {
+		backend = c.sim.Client()
+	}
+	return NewTxQueue(backend, c.auth, store, &Config{
+		TxPollInterval:   1,
+		TxStuckTimeout:   120,
+		TxGasBumpPercent: 20,
+		TxMaxAttempts:    maxAttempts,
+	})
+}
+
+func newTestTxStore(t *testing.T) *BoltStore {
+	t.Helper()
+
+	store, err := NewBoltStore(filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestTxQueueSimulated(t *testing.T) {
+	ctx := context.Background()
+	testChain := newTestChain(t)
+	sim, chain, registered := testChain.sim, testChain.client, testChain.registered
+
+	store := newTestTxStore(t)
+	queue := testChain.newQueue(nil, store, 5)
+
+	entryIDs := make(map[uint64]*big.Int)
+	queue.OnConfirmed(func(job *TxJob, receipt *types.Receipt) {
+		if job.Kind != txKindSubmitWeatherData {
+			return
+		}
+		entryID, err := chain.ParseEntryID(receipt)
+		if err != nil {
+			t.Errorf("ParseEntryID for submission %d: %v", job.SubmissionID, err)
+			return
+		}
+		entryIDs[job.SubmissionID] = entryID
+	})
+
+	var jobs []*TxJob
+	for submissionID := uint64(1); submissionID <= 2; submissionID++ {
+		dataHash := crypto.Keccak256Hash([]byte{byte(submissionID)})
+		submit, err := chain.SubmitWeatherDataJob(submissionID, registered, "Qm"+strings.Repeat("x", 44), dataHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record, err := chain.RecordSubmissionJob(submissionID, registered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, submit, record)
+	}
+	rejected, err := chain.RecordSubmissionJob(3, testChain.unregistered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs = append(jobs, rejected)
+
+	if err := queue.Enqueue(jobs...); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// The first pass sends, the second picks up the receipts.
+	queue.process(ctx)
+	sim.Commit()
+	queue.process(ctx)
+
+	for _, job := range jobs {
+		stored, err := store.GetTxJob(job.ID)
+		if err != nil {
+			t.Fatalf("GetTxJob(%d): %v", job.ID, err)
+		}
+
+		if job == rejected {
+			if stored.Status != TxFailed || !strings.Contains(stored.LastError, "gas estimation failed") {
+				t.Errorf("unregistered device job is %s (%q), want failed at gas estimation", stored.Status, stored.LastError)
+			}
+			if stored.Nonce != nil {
+				t.Errorf("unregistered device job used nonce %d", *stored.Nonce)
+			}
+			continue
+		}
+
+		if stored.Status != TxConfirmed {
+			t.Errorf("job %d (%s) is %s (%q), want confirmed", stored.ID, stored.Kind, stored.Status, stored.LastError)
+		}
+		if stored.BlockNumber == 0 || stored.MinedTxHash == "" {
+			t.Errorf("job %d (%s) has no mined transaction", stored.ID, stored.Kind)
+		}
+	}
+
+	for submissionID, want := range map[uint64]int64{1: 0, 2: 1} {
+		if got := entryIDs[submissionID]; got == nil || got.Int64() != want {
+			t.Errorf("entry ID for submission %d = %v, want %d", submissionID, got, want)
+		}
+	}
+
+	pending, err := store.ListPendingTxJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d jobs still pending", len(pending))
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 )
@@ -15,6 +16,42 @@ type Config struct {
 	PinataSecretKey         string
 	RateLimitWindow         int
 	MaxSubmissionsPerWindow int
+	DatabasePath            string
+	TxPollInterval          int
+	TxStuckTimeout          int
+	TxGasBumpPercent        int
+	TxMaxAttempts           int
+	AnchorMode              string
+	AnchorWindow            int
+	RewardInterval          int
+	RewardCheckInterval     int
+	RewardMinSubmissions    int
+	RewardMaxAttempts       int
+	IndexerStartBlock       int
+	IndexerConfirmations    int
+	IndexerPollInterval     int
+	IndexerBlockRange       int
+	BatchMaxSize            int
+	BackfillMaxAge          int
+	BackfillMaxPerWindow    int
+	ValidationRulesPath     string
+	QCRadiusKm              float64
+	QCWindow                int
+	QCMinNeighbours         int
+	QCZThreshold            float64
+	QCMinScore              float64
+	AnomalyWarmup           int
+	AnomalySpikeSigma       float64
+	AnomalyFlatlineReadings int
+	AnomalyMaxClockDrift    int
+	AnomalyDegradedAfter    int
+	AnomalyRecoverAfter     int
+	ReputationInterval      int
+	ReputationWindow        int
+	ReputationMaturity      int
+	ReputationWeightRewards bool
+	ReputationMinReward     float64
+	ReputationMinData       float64
 }
 
 func LoadConfig() (*Config, error) {
@@ -28,6 +65,79 @@ func LoadConfig() (*Config, error) {
 		PinataSecretKey:         getEnvOrDefault("PINATA_SECRET_KEY", ""),
 		RateLimitWindow:         getEnvIntOrDefault("RATE_LIMIT_WINDOW", 3600),
 		MaxSubmissionsPerWindow: getEnvIntOrDefault("MAX_SUBMISSIONS_PER_WINDOW", 12),
+		DatabasePath:            getEnvOrDefault("DATABASE_PATH", "./weather.db"),
+		TxPollInterval:          getEnvIntOrDefault("TX_POLL_INTERVAL", 5),
+		TxStuckTimeout:          getEnvIntOrDefault("TX_STUCK_TIMEOUT", 120),
+		TxGasBumpPercent:        getEnvIntOrDefault("TX_GAS_BUMP_PERCENT", 20),
+		TxMaxAttempts:           getEnvIntOrDefault("TX_MAX_ATTEMPTS", 5),
+		AnchorMode:              getEnvOrDefault("ANCHOR_MODE", anchorModeSubmission),
+		AnchorWindow:            getEnvIntOrDefault("ANCHOR_WINDOW", 3600),
+		RewardInterval:          getEnvIntOrDefault("REWARD_INTERVAL", 86400),
+		RewardCheckInterval:     getEnvIntOrDefault("REWARD_CHECK_INTERVAL", 600),
+		RewardMinSubmissions:    getEnvIntOrDefault("REWARD_MIN_SUBMISSIONS", 12),
+		RewardMaxAttempts:       getEnvIntOrDefault("REWARD_MAX_ATTEMPTS", 3),
+		IndexerStartBlock:       getEnvIntOrDefault("INDEXER_START_BLOCK", -1),
+		IndexerConfirmations:    getEnvIntOrDefault("INDEXER_CONFIRMATIONS", 12),
+		IndexerPollInterval:     getEnvIntOrDefault("INDEXER_POLL_INTERVAL", 15),
+		IndexerBlockRange:       getEnvIntOrDefault("INDEXER_BLOCK_RANGE", 2000),
+		BatchMaxSize:            getEnvIntOrDefault("BATCH_MAX_SIZE", 100),
+		BackfillMaxAge:          getEnvIntOrDefault("BACKFILL_MAX_AGE", 604800),
+		BackfillMaxPerWindow:    getEnvIntOrDefault("BACKFILL_MAX_PER_WINDOW", 288),
+		ValidationRulesPath:     getEnvOrDefault("VALIDATION_RULES_PATH", ""),
+		QCRadiusKm:              getEnvFloatOrDefault("QC_RADIUS_KM", 50),
+		QCWindow:                getEnvIntOrDefault("QC_WINDOW", 1800),
+		QCMinNeighbours:         getEnvIntOrDefault("QC_MIN_NEIGHBOURS", 3),
+		QCZThreshold:            getEnvFloatOrDefault("QC_Z_THRESHOLD", 3.5),
+		QCMinScore:              getEnvFloatOrDefault("QC_MIN_SCORE", 0.5),
+		AnomalyWarmup:           getEnvIntOrDefault("ANOMALY_WARMUP", 12),
+		AnomalySpikeSigma:       getEnvFloatOrDefault("ANOMALY_SPIKE_SIGMA", 6),
+		AnomalyFlatlineReadings: getEnvIntOrDefault("ANOMALY_FLATLINE_READINGS", 24),
+		AnomalyMaxClockDrift:    getEnvIntOrDefault("ANOMALY_MAX_CLOCK_DRIFT", 120),
+		AnomalyDegradedAfter:    getEnvIntOrDefault("ANOMALY_DEGRADED_AFTER", 6),
+		AnomalyRecoverAfter:     getEnvIntOrDefault("ANOMALY_RECOVER_AFTER", 12),
+		ReputationInterval:      getEnvIntOrDefault("REPUTATION_INTERVAL", 3600),
+		ReputationWindow:        getEnvIntOrDefault("REPUTATION_WINDOW", 604800),
+		ReputationMaturity:      getEnvIntOrDefault("REPUTATION_MATURITY", 2592000),
+		ReputationWeightRewards: getEnvOrDefault("REPUTATION_WEIGHT_REWARDS", "false") == "true",
+		ReputationMinReward:     getEnvFloatOrDefault("REPUTATION_MIN_REWARD", 0.3),
+		ReputationMinData:       getEnvFloatOrDefault("REPUTATION_MIN_DATA", 0),
+	}
+
+	if config.AnchorMode != anchorModeSubmission && config.AnchorMode != anchorModeBatch {
+		return nil, fmt.Errorf("invalid ANCHOR_MODE %q: expected %q or %q", config.AnchorMode, anchorModeSubmission, anchorModeBatch)
+	}
+
+	// Nodes only replace a pending transaction whose gas price is at least
+	// 10% higher.
+	if config.TxGasBumpPercent < 10 {
+		return nil, fmt.Errorf("invalid TX_GAS_BUMP_PERCENT %d: must be at least 10", config.TxGasBumpPercent)
+	}
+
+	if config.TxPollInterval <= 0 {
+		return nil, fmt.Errorf("TX_POLL_INTERVAL must be positive")
+	}
+
+	if config.AnchorWindow <= 0 {
+		return nil, fmt.Errorf("ANCHOR_WINDOW must be positive")
+	}
+
+	if config.RewardCheckInterval <= 0 {
+		return nil, fmt.Errorf("REWARD_CHECK_INTERVAL must be positive")
+	}
+
+	if config.IndexerPollInterval <= 0 {
+		return nil, fmt.Errorf("INDEXER_POLL_INTERVAL must be positive")
+	}
+
+	if config.RateLimitWindow <= 0 || config.MaxSubmissionsPerWindow <= 0 {
+		return nil, fmt.Errorf("RATE_LIMIT_WINDOW and MAX_SUBMISSIONS_PER_WINDOW must be positive")
+	}
+	if config.ReputationInterval <= 0 || config.ReputationWindow <= 0 || config.ReputationMaturity <= 0 {
+		return nil, fmt.Errorf("REPUTATION_INTERVAL, REPUTATION_WINDOW and REPUTATION_MATURITY must be positive")
+	}
+
+	if config.QCMinNeighbours < 1 {
+		return nil, fmt.Errorf("invalid QC_MIN_NEIGHBOURS %d: must be at least 1", config.QCMinNeighbours)
 	}
 
 	return config, nil
@@ -48,3 +158,12 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
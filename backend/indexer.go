@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type IndexerCheckpoint struct {
+	Block     uint64    `json:"block"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *WeatherService) runIndexer(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.Config.IndexerPollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.indexChain(ctx); err != nil {
+			log.Printf("Chain indexer pass failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WeatherService) indexChain(ctx context.Context) error {
+	head, err := s.Chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch chain head: %v", err)
+	}
+
+	confirmations := uint64(s.Config.IndexerConfirmations)
+	if head.Number.Uint64() < confirmations {
+		return nil
+	}
+	safeBlock := head.Number.Uint64() - confirmations
+
+	next, err := s.nextIndexerBlock(ctx, safeBlock)
+	if err != nil {
+		return err
+	}
+
+	for next <= safeBlock {
+		to := next + uint64(s.Config.IndexerBlockRange) - 1
+		if to > safeBlock {
+			to = safeBlock
+		}
+
+		if err := s.indexRange(ctx, next, to); err != nil {
+			return fmt.Errorf("failed to index blocks %d-%d: %v", next, to, err)
+		}
+
+		header, err := s.Chain.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+		if err != nil {
+			return fmt.Errorf("failed to fetch block %d: %v", to, err)
+		}
+
+		checkpoint := &IndexerCheckpoint{Block: to, Hash: header.Hash().Hex(), UpdatedAt: time.Now()}
+		if err := s.Store.SaveIndexerCheckpoint(checkpoint); err != nil {
+			return err
+		}
+		next = to + 1
+	}
+
+	return nil
+}
+
+// nextIndexerBlock returns the first block of the next pass. Without a
+// checkpoint it is INDEXER_START_BLOCK, or the current safe block when that
+// is unset, so a fresh backend does not scan the chain from genesis.
+func (s *WeatherService) nextIndexerBlock(ctx context.Context, safeBlock uint64) (uint64, error) {
+	start := safeBlock
+	if s.Config.IndexerStartBlock >= 0 {
+		start = uint64(s.Config.IndexerStartBlock)
+	}
+
+	checkpoint, err := s.Store.GetIndexerCheckpoint()
+	if errors.Is(err, ErrNotFound) {
+		return start, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := s.Chain.HeaderByNumber(ctx, new(big.Int).SetUint64(checkpoint.Block))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch checkpoint block %d: %v", checkpoint.Block, err)
+	}
+	if header.Hash().Hex() == checkpoint.Hash {
+		return checkpoint.Block + 1, nil
+	}
+
+	rewind := uint64(s.Config.IndexerConfirmations)
+	if rewind == 0 {
+		rewind = 1
+	}
+	next := start
+	if checkpoint.Block > start+rewind {
+		next = checkpoint.Block - rewind
+	}
+	log.Printf("Reorg detected at block %d, re-indexing from block %d", checkpoint.Block, next)
+	return next, nil
+}
+
+func (s *WeatherService) indexRange(ctx context.Context, from, to uint64) error {
+	logs, err := s.Chain.FilterLogs(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	touched := make(map[[32]byte]bool)
+	rewarded := make(map[[32]byte]bool)
+	for _, entry := range logs {
+		if entry.Removed {
+			continue
+		}
+
+		switch s.Chain.EventName(entry) {
+		case "DeviceRegistered", "DeviceActivated", "DeviceDeactivated", "SubmissionRecorded":
+			if len(entry.Topics) > 1 {
+				touched[entry.Topics[1]] = true
+			}
+		case "RewardDistributed":
+			if len(entry.Topics) > 1 {
+				rewarded[entry.Topics[1]] = true
+			}
+		case "WeatherDataSubmitted":
+			if err := s.indexWeatherDataSubmitted(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	for deviceID := range touched {
+		if err := s.syncDevice(ctx, deviceID); err != nil {
+			return err
+		}
+	}
+
+	for deviceID := range rewarded {
+		if err := s.syncDeviceEarnings(ctx, deviceID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *WeatherService) indexWeatherDataSubmitted(entry types.Log) error {
+	event, err := s.Chain.ParseWeatherDataSubmitted(entry)
+	if err != nil {
+		return fmt.Errorf("failed to decode WeatherDataSubmitted: %v", err)
+	}
+	if event.DeviceId == anchorDeviceID {
+		return nil
+	}
+
+	record, err := s.Store.FindSubmissionByHash(common.Bytes2Hex(event.DataHash[:]))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	record.EntryID = event.EntryId.String()
+	record.TxHash = entry.TxHash.Hex()
+	return s.Store.SaveSubmission(record)
+}
+
+// syncDevice copies a device's current on-chain state into the store. State
+// is read at the latest block rather than the indexed one, so the indexer
+// works against a full node without archive history.
+func (s *WeatherService) syncDevice(ctx context.Context, deviceID common.Hash) error {
+	onChain, err := s.Chain.GetDevice(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to read device %s: %v", deviceID.Hex(), err)
+	}
+	if onChain.RegistrationTime == nil || onChain.RegistrationTime.Sign() == 0 {
+		return nil
+	}
+
+	// Anyone can register a device ID on-chain, so only entries whose key
+	// derives to the ID are trusted. Rotation keeps the ID but happens off
+	// chain, so the on-chain key is always the one the device registered with.
+	id := bytes32ToDeviceID(deviceID)
+	_, publicKeyBytes, err := parsePublicKey(onChain.PublicKey)
+	if err != nil || deriveDeviceID(publicKeyBytes) != id {
+		log.Printf("Ignoring on-chain device %s: public key does not derive to the device ID", id)
+		return nil
+	}
+
+	sync := func(device *DeviceRegistration) error {
+		device.Owner = onChain.Owner.Hex()
+		device.IsActive = onChain.IsActive
+		device.OnChainSubmissions = onChain.TotalSubmissions.Uint64()
+		if onChain.LastSubmission.Sign() > 0 {
+			device.LastOnChainSubmission = time.Unix(onChain.LastSubmission.Int64(), 0)
+		}
+		return nil
+	}
+
+	err = s.Store.UpdateDevice(id, sync)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	device := &DeviceRegistration{
+		DeviceID:         id,
+		PublicKey:        hex.EncodeToString(publicKeyBytes),
+		RegistrationTime: time.Unix(onChain.RegistrationTime.Int64(), 0),
+	}
+	sync(device)
+	return s.Store.SaveDevice(device)
+}
+
+func (s *WeatherService) syncDeviceEarnings(ctx context.Context, deviceID common.Hash) error {
+	if !s.Chain.HasRewardManager() {
+		return nil
+	}
+
+	earnings, err := s.Chain.DeviceEarnings(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to read earnings for device %s: %v", deviceID.Hex(), err)
+	}
+
+	err = s.Store.UpdateDevice(bytes32ToDeviceID(deviceID), func(device *DeviceRegistration) error {
+		device.TotalRewards = earnings.String()
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"weather-backend/bindings"
+)
+
+func TestIndexChain(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+	service.Config.IndexerStartBlock = 0
+	service.Config.IndexerConfirmations = 1
+	service.Config.IndexerBlockRange = 2
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+	deviceID := deriveDeviceID(publicKey)
+
+	chain := newTestChain(t)
+	chain.withIndexedRegistry(t, bindings.DeviceRegistryDevice{
+		Owner:            chain.auth.From,
+		PublicKey:        hex.EncodeToString(publicKey),
+		RegistrationTime: big.NewInt(1700000000),
+		IsActive:         true,
+		LastSubmission:   big.NewInt(1700000100),
+		TotalSubmissions: big.NewInt(7),
+	})
+	service.Chain = chain.client
+
+	dataHash := crypto.Keccak256Hash([]byte("reading"))
+	record := &SubmissionRecord{
+		WeatherData: WeatherData{DeviceID: deviceID, Timestamp: time.Now()},
+		DataHash:    common.Bytes2Hex(dataHash[:]),
+		ReceivedAt:  time.Now(),
+	}
+	if err := service.Store.SaveSubmission(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// The device is registered from a wallet, so the backend only learns of it
+	// from the chain. The registry stub describes the same device for an ID
+	// its key does not derive to, as anyone registering a copied key could.
+	device, err := deviceIDToBytes32(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostor, err := deviceIDToBytes32("00112233445566778899aabbccddeeff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submit, err := chain.client.SubmitWeatherDataJob(record.ID, device, "QmTest", dataHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := chain.client.RecordSubmissionJob(record.ID, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := chain.client.RecordSubmissionJob(record.ID, impostor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txStore := newTestTxStore(t)
+	queue := chain.newQueue(nil, txStore, 5)
+	if err := queue.Enqueue(submit, recorded, copied); err != nil {
+		t.Fatal(err)
+	}
+	queue.process(ctx)
+	chain.sim.Commit()
+	queue.process(ctx)
+
+	// Nothing is indexed until the block has INDEXER_CONFIRMATIONS on top.
+	if err := service.indexChain(ctx); err != nil {
+		t.Fatalf("indexChain: %v", err)
+	}
+	if _, err := service.Store.GetDevice(deviceID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("device indexed before it was confirmed (err %v)", err)
+	}
+
+	chain.sim.Commit()
+	if err := service.indexChain(ctx); err != nil {
+		t.Fatalf("indexChain: %v", err)
+	}
+
+	indexed, err := service.Store.GetDevice(deviceID)
+	if err != nil {
+		t.Fatalf("device was not indexed: %v", err)
+	}
+	if indexed.Owner != chain.auth.From.Hex() || !indexed.IsActive || indexed.PublicKey != hex.EncodeToString(publicKey) {
+		t.Errorf("indexed device = %+v, want the on-chain owner, key and active flag", indexed)
+	}
+	if indexed.OnChainSubmissions != 7 || !indexed.LastOnChainSubmission.Equal(time.Unix(1700000100, 0)) {
+		t.Errorf("indexed device has %d submissions, last at %v, want 7 at %v",
+			indexed.OnChainSubmissions, indexed.LastOnChainSubmission, time.Unix(1700000100, 0))
+	}
+
+	if _, err := service.Store.GetDevice(bytes32ToDeviceID(impostor)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("device whose key does not derive to its ID was indexed (err %v)", err)
+	}
+
+	stored, err := service.Store.GetSubmission(record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitJob, err := txStore.GetTxJob(submit.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.EntryID != "0" || stored.TxHash != submitJob.MinedTxHash {
+		t.Errorf("submission has entry %q in tx %s, want entry 0 in tx %s", stored.EntryID, stored.TxHash, submitJob.MinedTxHash)
+	}
+
+	head, err := chain.sim.Client().HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := service.Store.GetIndexerCheckpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.Block != head.Number.Uint64()-1 {
+		t.Errorf("checkpoint at block %d, want %d", checkpoint.Block, head.Number.Uint64()-1)
+	}
+}
+
+func TestNextIndexerBlock(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+	service.Config.IndexerConfirmations = 3
+
+	chain := newTestChain(t)
+	service.Chain = chain.client
+	for i := 0; i < 10; i++ {
+		chain.sim.Commit()
+	}
+
+	const safeBlock = 9
+
+	// Without a checkpoint a fresh backend starts at the safe block, or at
+	// INDEXER_START_BLOCK when it is set.
+	service.Config.IndexerStartBlock = -1
+	if next, err := service.nextIndexerBlock(ctx, safeBlock); err != nil || next != safeBlock {
+		t.Errorf("without a checkpoint next = %d (%v), want %d", next, err, safeBlock)
+	}
+	service.Config.IndexerStartBlock = 2
+	if next, err := service.nextIndexerBlock(ctx, safeBlock); err != nil || next != 2 {
+		t.Errorf("without a checkpoint and INDEXER_START_BLOCK=2 next = %d (%v), want 2", next, err)
+	}
+
+	header, err := chain.sim.Client().HeaderByNumber(ctx, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint IndexerCheckpoint
+		want       uint64
+	}{
+		{"checkpoint on the chain", IndexerCheckpoint{Block: 5, Hash: header.Hash().Hex()}, 6},
+		// A checkpoint whose block hash changed was reorged out, so the
+		// indexer rewinds by INDEXER_CONFIRMATIONS.
+		{"reorged checkpoint", IndexerCheckpoint{Block: 8, Hash: common.Hash{1}.Hex()}, 5},
+		{"reorged checkpoint near the start", IndexerCheckpoint{Block: 3, Hash: common.Hash{1}.Hex()}, 2},
+	}
+
+	for _, tt := range tests {
+		if err := service.Store.SaveIndexerCheckpoint(&tt.checkpoint); err != nil {
+			t.Fatal(err)
+		}
+		next, err := service.nextIndexerBlock(ctx, safeBlock)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if next != tt.want {
+			t.Errorf("%s: next = %d, want %d", tt.name, next, tt.want)
+		}
+	}
+}
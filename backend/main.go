@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -24,6 +25,8 @@ func main() {
 		log.Fatalf("Failed to create weather service: %v", err)
 	}
 
+	service.Start(context.Background())
+
 	r := gin.Default()
 
 	r.Use(func(c *gin.Context) {
@@ -41,9 +44,16 @@ func main() {
 	{
 		api.POST("/register", service.RegisterDevice)
 		api.POST("/submit", service.SubmitWeatherData)
+		api.POST("/submit/batch", service.SubmitBatch)
 		api.GET("/data", service.GetWeatherData)
 		api.GET("/data/latest", service.GetLatestData)
+		api.GET("/submissions/:id", service.GetSubmission)
+		api.GET("/proofs/:data_hash", service.GetProof)
 		api.GET("/devices", service.GetDevices)
+		api.GET("/devices/:id", service.GetDevice)
+		api.GET("/devices/:id/rewards", service.GetDeviceRewards)
+		api.GET("/devices/:id/reputation", service.GetDeviceReputation)
+		api.POST("/devices/:id/rotate", service.RotateDeviceKey)
 		api.GET("/health", service.HealthCheck)
 	}
 
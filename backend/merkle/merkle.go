@@ -0,0 +1,86 @@
+// Package merkle builds and verifies the Merkle trees used to anchor batches
+// of submission data hashes on-chain. Leaves are keccak256(dataHash) and
+// interior nodes hash their children in sorted order, so proofs also verify
+// with OpenZeppelin's MerkleProof.verify. An odd node at the end of a layer is
+// carried up unchanged.
+package merkle
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrEmptyTree = errors.New("merkle tree requires at least one leaf")
+
+type Tree struct {
+	layers [][][32]byte
+}
+
+func New(dataHashes [][32]byte) (*Tree, error) {
+	if len(dataHashes) == 0 {
+		return nil, ErrEmptyTree
+	}
+
+	layer := make([][32]byte, len(dataHashes))
+	for i, dataHash := range dataHashes {
+		layer[i] = HashLeaf(dataHash)
+	}
+
+	layers := [][][32]byte{layer}
+	for len(layer) > 1 {
+		next := make([][32]byte, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			next = append(next, hashPair(layer[i], layer[i+1]))
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+
+	return &Tree{layers: layers}, nil
+}
+
+func (t *Tree) Root() [32]byte {
+	return t.layers[len(t.layers)-1][0]
+}
+
+func (t *Tree) Proof(index int) ([][32]byte, error) {
+	if index < 0 || index >= len(t.layers[0]) {
+		return nil, errors.New("leaf index out of range")
+	}
+
+	proof := make([][32]byte, 0, len(t.layers)-1)
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := index ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+func Verify(dataHash [32]byte, proof [][32]byte, root [32]byte) bool {
+	node := HashLeaf(dataHash)
+	for _, sibling := range proof {
+		node = hashPair(node, sibling)
+	}
+	return node == root
+}
+
+func HashLeaf(dataHash [32]byte) [32]byte {
+	return crypto.Keccak256Hash(dataHash[:])
+}
+
+func hashPair(a, b [32]byte) [32]byte {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
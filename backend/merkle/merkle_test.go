@@ -0,0 +1,103 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func testLeaves(n int) [][32]byte {
+	leaves := make([][32]byte, n)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("reading %d", i)))
+	}
+	return leaves
+}
+
+func TestProofRoundTrip(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13, 1000} {
+		t.Run(fmt.Sprintf("%d leaves", n), func(t *testing.T) {
+			leaves := testLeaves(n)
+			tree, err := New(leaves)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			root := tree.Root()
+
+			for i, leaf := range leaves {
+				proof, err := tree.Proof(i)
+				if err != nil {
+					t.Fatalf("Proof(%d): %v", i, err)
+				}
+				if !Verify(leaf, proof, root) {
+					t.Fatalf("proof for leaf %d does not verify", i)
+				}
+
+				tampered := leaf
+				tampered[0] ^= 1
+				if Verify(tampered, proof, root) {
+					t.Errorf("proof for leaf %d verifies a tampered leaf", i)
+				}
+
+				wrongRoot := root
+				wrongRoot[31] ^= 1
+				if Verify(leaf, proof, wrongRoot) {
+					t.Errorf("proof for leaf %d verifies against the wrong root", i)
+				}
+
+				if n > 1 {
+					other := (i + 1) % n
+					if Verify(leaves[other], proof, root) {
+						t.Errorf("proof for leaf %d verifies leaf %d", i, other)
+					}
+				}
+			}
+
+			for _, index := range []int{-1, n} {
+				if _, err := tree.Proof(index); err == nil {
+					t.Errorf("Proof(%d) succeeded", index)
+				}
+			}
+		})
+	}
+}
+
+func TestRoot(t *testing.T) {
+	leaves := testLeaves(3)
+	a, b, c := HashLeaf(leaves[0]), HashLeaf(leaves[1]), HashLeaf(leaves[2])
+
+	tests := []struct {
+		name   string
+		leaves [][32]byte
+		want   [32]byte
+	}{
+		{"single leaf", leaves[:1], a},
+		{"pair", leaves[:2], hashPair(a, b)},
+		// The odd leaf is carried up and paired at the next layer.
+		{"odd leaf carried up", leaves, hashPair(hashPair(a, b), c)},
+	}
+
+	for _, tt := range tests {
+		tree, err := New(tt.leaves)
+		if err != nil {
+			t.Fatalf("%s: New: %v", tt.name, err)
+		}
+		if root := tree.Root(); root != tt.want {
+			t.Errorf("%s: root = %x, want %x", tt.name, root, tt.want)
+		}
+	}
+
+	// Pairs are hashed in sorted order, so the order of siblings does not
+	// matter.
+	if hashPair(a, b) != hashPair(b, a) {
+		t.Error("hashPair depends on argument order")
+	}
+}
+
+func TestNewEmpty(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrEmptyTree) {
+		t.Errorf("New(nil) error = %v, want ErrEmptyTree", err)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+type QCFlag string
+
+const (
+	// QCPass means the reading agrees with enough nearby stations.
+	QCPass QCFlag = "pass"
+	// QCSuspect means at least one field is an outlier among its neighbours.
+	QCSuspect QCFlag = "suspect"
+	// QCUnchecked means the device has no location or too few neighbours
+	// reported around the same time.
+	QCUnchecked QCFlag = "unchecked"
+)
+
+const earthRadiusKm = 6371.0
+
+// QCResult is the spatial quality check stored with each submission. Score
+// runs from 1 for a reading at its neighbours' median to 0 for one twice the
+// outlier threshold away, taken from the worst field.
+type QCResult struct {
+	Flag       QCFlag         `json:"flag"`
+	Score      float64        `json:"score"`
+	Neighbours int            `json:"neighbours"`
+	Fields     []QCFieldCheck `json:"fields,omitempty"`
+}
+
+type QCFieldCheck struct {
+	Field   string  `json:"field"`
+	Median  float64 `json:"median"`
+	ZScore  float64 `json:"z_score"`
+	Outlier bool    `json:"outlier"`
+}
+
+// qcField compares one measurement after reducing it to sea level, so
+// stations at different elevations can be compared. minSpread stops a handful
+// of neighbours that happen to agree exactly from making every small
+// difference an outlier.
+type qcField struct {
+	name      string
+	minSpread float64
+	reduce    func(data *WeatherData, elevation float64) float64
+}
+
+var qcFields = []qcField{
+	{"temperature", 1.0, func(d *WeatherData, elevation float64) float64 { return d.Temperature + 0.0065*elevation }},
+	{"humidity", 5.0, func(d *WeatherData, elevation float64) float64 { return d.Humidity }},
+	{"pressure", 1.0, func(d *WeatherData, elevation float64) float64 { return d.Pressure / pressureFactor(elevation) }},
+}
+
+type qcNeighbour struct {
+	data      WeatherData
+	elevation float64
+}
+
+// spatialQC compares a reading with the closest-in-time reading from each
+// registered device within QC_RADIUS_KM, using the robust z-score
+// |x - median| / (1.4826 * MAD) for each field.
+func (s *WeatherService) spatialQC(device *DeviceRegistration, data WeatherData) *QCResult {
+	unchecked := &QCResult{Flag: QCUnchecked, Score: 1}
+	if device.Latitude == nil || device.Longitude == nil {
+		return unchecked
+	}
+
+	neighbours, err := s.findNeighbourReadings(device, data.Timestamp)
+	if err != nil {
+		log.Printf("Spatial QC for device %s skipped: %v", device.DeviceID, err)
+		return unchecked
+	}
+	unchecked.Neighbours = len(neighbours)
+	if len(neighbours) < s.Config.QCMinNeighbours {
+		return unchecked
+	}
+
+	result := &QCResult{Flag: QCPass, Score: 1, Neighbours: len(neighbours)}
+	threshold := s.Config.QCZThreshold
+
+	for _, field := range qcFields {
+		values := make([]float64, len(neighbours))
+		for i, neighbour := range neighbours {
+			values[i] = field.reduce(&neighbour.data, neighbour.elevation)
+		}
+
+		median := medianOf(values)
+		deviations := make([]float64, len(values))
+		for i, value := range values {
+			deviations[i] = math.Abs(value - median)
+		}
+		spread := math.Max(1.4826*medianOf(deviations), field.minSpread)
+
+		z := math.Abs(field.reduce(&data, elevationOf(device))-median) / spread
+		check := QCFieldCheck{Field: field.name, Median: median, ZScore: z, Outlier: z > threshold}
+		result.Fields = append(result.Fields, check)
+
+		if check.Outlier {
+			result.Flag = QCSuspect
+		}
+		result.Score = math.Min(result.Score, math.Max(0, 1-z/(2*threshold)))
+	}
+
+	return result
+}
+
+// findNeighbourReadings returns, for each usable device nearby, its reading
+// taken closest to at within QC_WINDOW. Only that stretch of each device's
+// timeline is read, so backfilled readings are compared with neighbours' at
+// the same time however late either arrived.
+func (s *WeatherService) findNeighbourReadings(device *DeviceRegistration, at time.Time) ([]qcNeighbour, error) {
+	devices, err := s.Store.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(s.Config.QCWindow) * time.Second
+	neighbours := make([]qcNeighbour, 0)
+
+	for _, other := range devices {
+		if other.DeviceID == device.DeviceID || !other.IsActive || other.Degraded || other.Latitude == nil || other.Longitude == nil {
+			continue
+		}
+		if haversineKm(*device.Latitude, *device.Longitude, *other.Latitude, *other.Longitude) > s.Config.QCRadiusKm {
+			continue
+		}
+
+		records, err := s.Store.ListDeviceSubmissionsBetween(other.DeviceID, at.Add(-window), at.Add(window))
+		if err != nil {
+			return nil, err
+		}
+
+		var closest *SubmissionRecord
+		for i := range records {
+			record := &records[i]
+			if record.QC != nil && record.QC.Flag == QCSuspect {
+				continue
+			}
+			if closest == nil || absDuration(record.Timestamp.Sub(at)) < absDuration(closest.Timestamp.Sub(at)) {
+				closest = record
+			}
+		}
+		if closest != nil {
+			neighbours = append(neighbours, qcNeighbour{data: closest.WeatherData, elevation: elevationOf(&other)})
+		}
+	}
+
+	return neighbours, nil
+}
+
+// qcEligible reports whether a submission counts towards rewards. Unchecked
+// readings score 1, so isolated stations are not penalised.
+func (s *WeatherService) qcEligible(record *SubmissionRecord) bool {
+	return record.QC == nil || record.QC.Score >= s.Config.QCMinScore
+}
+
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func elevationOf(device *DeviceRegistration) float64 {
+	if device.Elevation == nil {
+		return 0
+	}
+	return *device.Elevation
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// addQCDevice registers a device at the given position with one reading per
+// entry in readings, taken at the keyed times.
+func addQCDevice(t *testing.T, service *WeatherService, deviceID string, latitude, longitude float64, readings map[time.Time]float64) *DeviceRegistration {
+	t.Helper()
+
+	device := &DeviceRegistration{DeviceID: deviceID, Latitude: &latitude, Longitude: &longitude, IsActive: true}
+	if err := service.Store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+	for timestamp, temperature := range readings {
+		record := &SubmissionRecord{
+			WeatherData: WeatherData{DeviceID: deviceID, Temperature: temperature, Humidity: 50, Pressure: 1013, Timestamp: timestamp},
+			ReceivedAt:  time.Now(),
+		}
+		if err := service.Store.SaveSubmission(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return device
+}
+
+func TestSpatialQC(t *testing.T) {
+	service := newTestService(t)
+	service.Config.QCRadiusKm = 50
+	service.Config.QCWindow = 1800
+	service.Config.QCMinNeighbours = 3
+	service.Config.QCZThreshold = 3.5
+
+	// A day-old stretch sent late as backfill, next to readings from now.
+	at := time.Now().Add(-24 * time.Hour)
+	now := time.Now()
+	addQCDevice(t, service, "n1", 59.91, 10.75, map[time.Time]float64{at.Add(-5 * time.Minute): 20, now: 35})
+	addQCDevice(t, service, "n2", 59.92, 10.76, map[time.Time]float64{at.Add(10 * time.Minute): 21, at.Add(-40 * time.Minute): 35})
+	addQCDevice(t, service, "n3", 59.90, 10.74, map[time.Time]float64{at: 19, at.Add(time.Minute): 35})
+	addQCDevice(t, service, "far", 60.39, 5.32, map[time.Time]float64{at: 35})
+	degraded := addQCDevice(t, service, "degraded", 59.91, 10.75, map[time.Time]float64{at: 35})
+	degraded.Degraded = true
+	if err := service.Store.SaveDevice(degraded); err != nil {
+		t.Fatal(err)
+	}
+
+	// n3's reading a minute later is closer in time but suspect, so its
+	// reading at the same time is used.
+	err := service.Store.SaveSubmission(&SubmissionRecord{
+		WeatherData: WeatherData{DeviceID: "n3", Temperature: 35, Humidity: 50, Pressure: 1013, Timestamp: at.Add(-time.Second)},
+		QC:          &QCResult{Flag: QCSuspect},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := service.Store.ListDeviceSubmissionsBetween("n3", at.Add(-time.Minute), at.Add(30*time.Second))
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ListDeviceSubmissionsBetween returned %d readings, %v, want n3's two", len(records), err)
+	}
+
+	latitude, longitude := 59.915, 10.755
+	device := &DeviceRegistration{DeviceID: "self", Latitude: &latitude, Longitude: &longitude}
+	reading := func(temperature float64) WeatherData {
+		return WeatherData{DeviceID: "self", Temperature: temperature, Humidity: 50, Pressure: 1013, Timestamp: at}
+	}
+
+	result := service.spatialQC(device, reading(20.5))
+	if result.Flag != QCPass || result.Neighbours != 3 || result.Score < 0.9 {
+		t.Errorf("reading near the neighbours: %+v, want pass against 3 neighbours", result)
+	}
+	if result.Fields[0].Field != "temperature" || result.Fields[0].Median != 20 {
+		t.Errorf("temperature check %+v, want the median of 19, 20 and 21", result.Fields[0])
+	}
+
+	result = service.spatialQC(device, reading(40))
+	if result.Flag != QCSuspect || result.Score != 0 || !result.Fields[0].Outlier {
+		t.Errorf("reading 20 °C off: %+v, want a suspect temperature scoring 0", result)
+	}
+
+	service.Config.QCMinNeighbours = 4
+	if result := service.spatialQC(device, reading(30)); result.Flag != QCUnchecked || result.Score != 1 || result.Neighbours != 3 {
+		t.Errorf("with too few neighbours: %+v, want unchecked", result)
+	}
+	if result := service.spatialQC(&DeviceRegistration{DeviceID: "nowhere"}, reading(30)); result.Flag != QCUnchecked {
+		t.Errorf("device without a location: %+v, want unchecked", result)
+	}
+}
+
+func TestQCReduction(t *testing.T) {
+	// A station 1000 m up reads 6.5 °C colder and at lower pressure than one
+	// at sea level in the same air.
+	data := WeatherData{Temperature: 8.5, Pressure: 1013.25 * pressureFactor(1000)}
+	for _, field := range qcFields {
+		switch field.name {
+		case "temperature":
+			if got := field.reduce(&data, 1000); math.Abs(got-15) > 1e-9 {
+				t.Errorf("temperature at sea level = %v, want 15", got)
+			}
+		case "pressure":
+			if got := field.reduce(&data, 1000); math.Abs(got-1013.25) > 1e-9 {
+				t.Errorf("pressure at sea level = %v, want 1013.25", got)
+			}
+		}
+	}
+
+	if km := haversineKm(59.9139, 10.7522, 60.3913, 5.3221); math.Abs(km-305) > 5 {
+		t.Errorf("Oslo to Bergen is %v km, want about 305", km)
+	}
+	if median := medianOf([]float64{3, 1, 4, 1}); median != 2 {
+		t.Errorf("median of 3, 1, 4, 1 = %v, want 2", median)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Weights of the reputation components. Consistency is left out, and the
+// other weights scaled up, for devices none of whose readings could be
+// compared with neighbours.
+const (
+	reputationQCWeight          = 0.35
+	reputationUptimeWeight      = 0.25
+	reputationConsistencyWeight = 0.25
+	reputationAgeWeight         = 0.15
+)
+
+// reputationCacheTTL is how long a computed reputation is served before
+// GET /api/devices/<device_id>/reputation computes it again.
+const reputationCacheTTL = time.Minute
+
+// Reputation is a device's score from 0 to 1 at one point in time, computed
+// from its submissions over the last REPUTATION_WINDOW.
+type Reputation struct {
+	DeviceID    string               `json:"device_id"`
+	Score       float64              `json:"score"`
+	Components  ReputationComponents `json:"components"`
+	Submissions int                  `json:"submissions"`
+	ComputedAt  time.Time            `json:"computed_at"`
+}
+
+// ReputationComponents each run from 0 to 1. QCPassRate is the share of
+// submissions without anomalies or a suspect QC flag, Uptime the share of the
+// submissions the rate limit would have allowed, Consistency the mean spatial
+// QC score of readings that had neighbours, and Age how far the device is
+// through REPUTATION_MATURITY.
+type ReputationComponents struct {
+	QCPassRate  float64  `json:"qc_pass_rate"`
+	Uptime      float64  `json:"uptime"`
+	Consistency *float64 `json:"consistency,omitempty"`
+	Age         float64  `json:"age"`
+}
+
+func (s *WeatherService) runReputation(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.Config.ReputationInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.updateReputations(); err != nil {
+			log.Printf("Reputation update failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WeatherService) updateReputations() error {
+	devices, err := s.Store.ListDevices()
+	if err != nil {
+		return err
+	}
+
+	for i := range devices {
+		reputation, err := s.computeReputation(&devices[i], time.Now())
+		if err != nil {
+			return err
+		}
+		if err := s.Store.SaveReputation(reputation); err != nil {
+			return err
+		}
+		s.cacheReputation(reputation)
+	}
+	return nil
+}
+
+// currentReputation returns the device's reputation as of now, reusing one
+// computed within reputationCacheTTL so repeated requests do not each scan the
+// device's submissions.
+func (s *WeatherService) currentReputation(device *DeviceRegistration, now time.Time) (*Reputation, error) {
+	s.mu.RLock()
+	cached := s.reputations[device.DeviceID]
+	s.mu.RUnlock()
+	if cached != nil && now.Sub(cached.ComputedAt) < reputationCacheTTL {
+		return cached, nil
+	}
+
+	reputation, err := s.computeReputation(device, now)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheReputation(reputation)
+	return reputation, nil
+}
+
+func (s *WeatherService) cacheReputation(reputation *Reputation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reputations[reputation.DeviceID] = reputation
+}
+
+func (s *WeatherService) computeReputation(device *DeviceRegistration, now time.Time) (*Reputation, error) {
+	window := time.Duration(s.Config.ReputationWindow) * time.Second
+	since := now.Add(-window)
+	if device.RegistrationTime.After(since) {
+		since = device.RegistrationTime
+	}
+
+	records, err := s.Store.ListSubmissions(SubmissionFilter{DeviceID: device.DeviceID, ReceivedSince: since})
+	if err != nil {
+		return nil, err
+	}
+
+	reputation := &Reputation{DeviceID: device.DeviceID, Submissions: len(records), ComputedAt: now}
+	components := &reputation.Components
+
+	passed, checked := 0, 0
+	qcTotal := 0.0
+	for i := range records {
+		if !records[i].Flagged() {
+			passed++
+		}
+		if qc := records[i].QC; qc != nil && qc.Flag != QCUnchecked {
+			checked++
+			qcTotal += qc.Score
+		}
+	}
+	if len(records) > 0 {
+		components.QCPassRate = float64(passed) / float64(len(records))
+	}
+	if checked > 0 {
+		consistency := qcTotal / float64(checked)
+		components.Consistency = &consistency
+	}
+
+	// A device submitting as often as the rate limit allows has full uptime.
+	perSecond := float64(s.Config.MaxSubmissionsPerWindow) / float64(s.Config.RateLimitWindow)
+	if expected := math.Floor(now.Sub(since).Seconds() * perSecond); expected >= 1 {
+		components.Uptime = math.Min(1, float64(len(records))/expected)
+	} else if len(records) > 0 {
+		components.Uptime = 1
+	}
+
+	maturity := time.Duration(s.Config.ReputationMaturity) * time.Second
+	components.Age = math.Min(1, now.Sub(device.RegistrationTime).Seconds()/maturity.Seconds())
+
+	score := reputationQCWeight*components.QCPassRate + reputationUptimeWeight*components.Uptime + reputationAgeWeight*components.Age
+	weights := reputationQCWeight + reputationUptimeWeight + reputationAgeWeight
+	if components.Consistency != nil {
+		score += reputationConsistencyWeight * *components.Consistency
+		weights += reputationConsistencyWeight
+	}
+	reputation.Score = score / weights
+
+	return reputation, nil
+}
+
+// rewardReputation returns the score rewards are weighted by: the last stored
+// reputation, or 1 when weighting is off.
+func (s *WeatherService) rewardReputation(device *DeviceRegistration) float64 {
+	if !s.Config.ReputationWeightRewards {
+		return 1
+	}
+	if device.Reputation == nil {
+		return 0
+	}
+	return device.Reputation.Score
+}
+
+// minReputationQuery reads the min_reputation filter for data endpoints,
+// falling back to REPUTATION_MIN_DATA.
+func (s *WeatherService) minReputationQuery(c *gin.Context) (float64, error) {
+	value := c.Query("min_reputation")
+	if value == "" {
+		return s.Config.ReputationMinData, nil
+	}
+
+	minReputation, err := strconv.ParseFloat(value, 64)
+	if err != nil || minReputation < 0 || minReputation > 1 {
+		return 0, fmt.Errorf("min_reputation must be between 0 and 1")
+	}
+	return minReputation, nil
+}
+
+func (s *WeatherService) GetDeviceReputation(c *gin.Context) {
+	deviceID, err := normalizeDeviceID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	device, err := s.Store.GetDevice(deviceID)
+	if errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load device"})
+		return
+	}
+
+	current, err := s.currentReputation(device, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute reputation"})
+		return
+	}
+
+	history, err := s.Store.ListReputationHistory(deviceID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reputation history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"device_id": deviceID,
+		"current":   current,
+		"history":   history,
+		"count":     len(history),
+	})
+}
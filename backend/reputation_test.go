@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newReputationService scores devices over a 10 hour window in which the
+// rate limit allows one submission an hour, with full age after 20 hours.
+func newReputationService(t *testing.T) *WeatherService {
+	t.Helper()
+
+	service := newTestService(t)
+	service.Config.RateLimitWindow = 3600
+	service.Config.MaxSubmissionsPerWindow = 1
+	service.Config.ReputationWindow = 10 * 3600
+	service.Config.ReputationMaturity = 20 * 3600
+	return service
+}
+
+// addReputationDevice registers a device 10 hours ago and saves the given
+// submissions, received an hour apart up to now.
+func addReputationDevice(t *testing.T, service *WeatherService, deviceID string, now time.Time, records []SubmissionRecord) *DeviceRegistration {
+	t.Helper()
+
+	device := &DeviceRegistration{DeviceID: deviceID, RegistrationTime: now.Add(-10 * time.Hour), IsActive: true}
+	if err := service.Store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		record := records[i]
+		record.DeviceID = deviceID
+		record.ReceivedAt = now.Add(-time.Duration(len(records)-i) * time.Hour)
+		if err := service.Store.SaveSubmission(&record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return device
+}
+
+func TestComputeReputation(t *testing.T) {
+	service := newReputationService(t)
+	now := time.Now()
+
+	passed := SubmissionRecord{QC: &QCResult{Flag: QCPass, Score: 0.8}}
+	spike := SubmissionRecord{QC: &QCResult{Flag: QCUnchecked, Score: 1}, Anomalies: []Anomaly{{Check: "spike"}}}
+	device := addReputationDevice(t, service, "checked", now, []SubmissionRecord{passed, passed, passed, passed, spike})
+
+	reputation, err := service.computeReputation(device, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	components := reputation.Components
+	if reputation.Submissions != 5 || components.QCPassRate != 0.8 || components.Uptime != 0.5 || math.Abs(components.Age-0.5) > 1e-9 {
+		t.Errorf("components = %+v from %d submissions, want pass rate 0.8, uptime 0.5 and age 0.5 from 5", components, reputation.Submissions)
+	}
+	if components.Consistency == nil || math.Abs(*components.Consistency-0.8) > 1e-9 {
+		t.Errorf("consistency = %v, want the mean QC score 0.8 of the 4 checked readings", components.Consistency)
+	}
+	if want := 0.35*0.8 + 0.25*0.5 + 0.25*0.8 + 0.15*0.5; math.Abs(reputation.Score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", reputation.Score, want)
+	}
+
+	// Without neighbours, consistency is left out and the other weights scaled.
+	unchecked := SubmissionRecord{QC: &QCResult{Flag: QCUnchecked, Score: 1}}
+	isolated := addReputationDevice(t, service, "isolated", now, []SubmissionRecord{unchecked, unchecked})
+	reputation, err = service.computeReputation(isolated, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reputation.Components.Consistency != nil {
+		t.Errorf("consistency = %v for a device never compared with neighbours", *reputation.Components.Consistency)
+	}
+	if want := (0.35*1 + 0.25*0.2 + 0.15*0.5) / 0.75; math.Abs(reputation.Score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", reputation.Score, want)
+	}
+
+	// A device with nothing in the window scores on age alone.
+	quiet := addReputationDevice(t, service, "quiet", now, nil)
+	reputation, err = service.computeReputation(quiet, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.15 * 0.5 / 0.75; math.Abs(reputation.Score-want) > 1e-9 {
+		t.Errorf("quiet device score = %v, want %v", reputation.Score, want)
+	}
+}
+
+func TestGetDeviceReputation(t *testing.T) {
+	service := newReputationService(t)
+	passed := SubmissionRecord{QC: &QCResult{Flag: QCPass, Score: 1}}
+	addReputationDevice(t, service, "aa", time.Now(), []SubmissionRecord{passed})
+
+	var response struct {
+		Current Reputation   `json:"current"`
+		History []Reputation `json:"history"`
+	}
+	get := func(deviceID string) int {
+		response.History = nil
+		return serve(t, service.GetDeviceReputation, http.MethodGet, "/api/devices/:id/reputation", "/api/devices/"+deviceID+"/reputation", nil, &response)
+	}
+
+	if code := get("aa"); code != http.StatusOK || response.Current.Submissions != 1 || len(response.History) != 0 {
+		t.Fatalf("GET returned %d with %d submissions and %d history entries, want 200, 1 and none", code, response.Current.Submissions, len(response.History))
+	}
+	first := response.Current.ComputedAt
+
+	// A second request within the cache lifetime does not recompute.
+	if err := service.Store.SaveSubmission(&SubmissionRecord{WeatherData: WeatherData{DeviceID: "aa"}, ReceivedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if get("aa"); response.Current.Submissions != 1 || !response.Current.ComputedAt.Equal(first) {
+		t.Errorf("second GET computed %d submissions at %v, want the cached 1 from %v", response.Current.Submissions, response.Current.ComputedAt, first)
+	}
+
+	// The periodic update stores a score and refreshes the cache.
+	if err := service.updateReputations(); err != nil {
+		t.Fatalf("updateReputations: %v", err)
+	}
+	if get("aa"); response.Current.Submissions != 2 || len(response.History) != 1 {
+		t.Errorf("after the update: %d submissions and %d history entries, want 2 and 1", response.Current.Submissions, len(response.History))
+	}
+	if stored, err := service.Store.GetDevice("aa"); err != nil || stored.Reputation == nil {
+		t.Errorf("no reputation stored on the device: %v", err)
+	}
+
+	// Once the cached score expires it is computed again.
+	service.reputations["aa"].ComputedAt = time.Now().Add(-2 * reputationCacheTTL)
+	if err := service.Store.SaveSubmission(&SubmissionRecord{WeatherData: WeatherData{DeviceID: "aa"}, ReceivedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if get("aa"); response.Current.Submissions != 3 {
+		t.Errorf("after expiry GET computed %d submissions, want 3", response.Current.Submissions)
+	}
+
+	if code := get("bb"); code != http.StatusNotFound {
+		t.Errorf("unknown device returned %d, want 404", code)
+	}
+}
+
+func TestRewardReputation(t *testing.T) {
+	service := newTestService(t)
+	device := &DeviceRegistration{DeviceID: "aa"}
+
+	if got := service.rewardReputation(device); got != 1 {
+		t.Errorf("with weighting off the reward reputation is %v, want 1", got)
+	}
+	service.Config.ReputationWeightRewards = true
+	if got := service.rewardReputation(device); got != 0 {
+		t.Errorf("never scored device has reward reputation %v, want 0", got)
+	}
+	device.Reputation = &Reputation{Score: 0.7}
+	if got := service.rewardReputation(device); got != 0.7 {
+		t.Errorf("reward reputation is %v, want the stored 0.7", got)
+	}
+}
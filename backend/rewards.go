@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+)
+
+type RewardStatus string
+
+const (
+	RewardPending RewardStatus = "pending"
+	RewardPaid    RewardStatus = "paid"
+	RewardFailed  RewardStatus = "failed"
+)
+
+type RewardRecord struct {
+	ID          uint64       `json:"id"`
+	DeviceID    string       `json:"device_id"`
+	Period      int64        `json:"period"`
+	Submissions int          `json:"submissions"`
+	Reputation  float64      `json:"reputation,omitempty"`
+	Status      RewardStatus `json:"status"`
+	Attempts    int          `json:"attempts"`
+	TxJobID     uint64       `json:"tx_job_id,omitempty"`
+	TxHash      string       `json:"tx_hash,omitempty"`
+	Amount      string       `json:"amount,omitempty"`
+	LastError   string       `json:"last_error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	PaidAt      time.Time    `json:"paid_at,omitempty"`
+}
+
+func (s *WeatherService) runRewards(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.Config.RewardCheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.distributeRewards(ctx); err != nil {
+			log.Printf("Reward distribution pass failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WeatherService) distributeRewards(ctx context.Context) error {
+	remaining, err := s.Chain.RemainingDailyRewards(ctx)
+	if err != nil {
+		return err
+	}
+	if remaining.Sign() <= 0 {
+		log.Printf("Daily reward limit reached, skipping reward distribution")
+		return nil
+	}
+
+	devices, err := s.Store.ListDevices()
+	if err != nil {
+		return err
+	}
+
+	period := time.Now().Unix() / int64(s.Config.RewardInterval)
+	for _, device := range devices {
+		if !device.IsActive {
+			continue
+		}
+
+		reward, err := s.Store.FindReward(device.DeviceID, period)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			reward = &RewardRecord{DeviceID: device.DeviceID, Period: period, CreatedAt: time.Now()}
+		case err != nil:
+			return err
+		default:
+			if err := s.reconcileReward(reward); err != nil {
+				return err
+			}
+			if reward.Status != RewardFailed || reward.Attempts >= s.Config.RewardMaxAttempts {
+				continue
+			}
+		}
+
+		submissions, err := s.countRewardableSubmissions(&device)
+		if err != nil {
+			return err
+		}
+
+		// With reputation weighting, a device needs proportionally more
+		// eligible submissions the lower its reputation, so noisy devices are
+		// paid in fewer periods than reliable ones.
+		reputation := s.rewardReputation(&device)
+		if s.Config.ReputationWeightRewards && reputation < s.Config.ReputationMinReward {
+			continue
+		}
+		if float64(submissions)*reputation < float64(s.Config.RewardMinSubmissions) {
+			continue
+		}
+		if s.Config.ReputationWeightRewards {
+			reward.Reputation = reputation
+		}
+
+		// A device ID that does not fit the contract's bytes32 cannot be paid.
+		// The failure is recorded on the period so it shows up in the device's
+		// rewards instead of the device silently going unpaid.
+		deviceID, err := deviceIDToBytes32(device.DeviceID)
+		if err != nil {
+			log.Printf("Cannot reward device %s: %v", device.DeviceID, err)
+			reward.Status = RewardFailed
+			reward.Submissions = submissions
+			reward.Attempts++
+			reward.LastError = err.Error()
+			reward.UpdatedAt = time.Now()
+			if err := s.Store.SaveReward(reward); err != nil {
+				return err
+			}
+			continue
+		}
+
+		amount, err := s.Chain.CalculateReward(ctx, deviceID)
+		if err != nil {
+			return err
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		if err := s.queueReward(reward, deviceID, submissions); err != nil {
+			return err
+		}
+
+		remaining.Sub(remaining, amount)
+		if remaining.Sign() <= 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *WeatherService) reconcileReward(reward *RewardRecord) error {
+	if reward.Status != RewardPending || reward.TxJobID == 0 {
+		return nil
+	}
+
+	job, err := s.Store.GetTxJob(reward.TxJobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != TxFailed {
+		return nil
+	}
+
+	reward.Status = RewardFailed
+	reward.LastError = job.LastError
+	reward.UpdatedAt = time.Now()
+	return s.Store.SaveReward(reward)
+}
+
+func (s *WeatherService) countRewardableSubmissions(device *DeviceRegistration) (int, error) {
+	since := device.RegistrationTime
+
+	rewards, err := s.Store.ListDeviceRewards(device.DeviceID)
+	if err != nil {
+		return 0, err
+	}
+	for _, reward := range rewards {
+		if reward.Status == RewardPaid {
+			since = reward.PaidAt
+			break
+		}
+	}
+
+	records, err := s.Store.ListSubmissions(SubmissionFilter{DeviceID: device.DeviceID, ReceivedSince: since})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range records {
+		if s.qcEligible(&records[i]) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *WeatherService) queueReward(reward *RewardRecord, deviceID [32]byte, submissions int) error {
+	reward.Status = RewardPending
+	reward.Submissions = submissions
+	reward.Attempts++
+	reward.LastError = ""
+	reward.UpdatedAt = time.Now()
+	if err := s.Store.SaveReward(reward); err != nil {
+		return err
+	}
+
+	job, err := s.Chain.DistributeRewardJob(reward.ID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.TxQueue.Enqueue(job); err != nil {
+		reward.Status = RewardFailed
+		reward.LastError = err.Error()
+		if saveErr := s.Store.SaveReward(reward); saveErr != nil {
+			return saveErr
+		}
+		return fmt.Errorf("failed to queue reward for device %s: %v", reward.DeviceID, err)
+	}
+
+	reward.TxJobID = job.ID
+	return s.Store.SaveReward(reward)
+}
+
+func (s *WeatherService) handleRewardPaid(job *TxJob, receipt *types.Receipt) {
+	reward, err := s.Store.GetReward(job.RewardID)
+	if err != nil {
+		log.Printf("Failed to load reward %d: %v", job.RewardID, err)
+		return
+	}
+
+	amount, err := s.Chain.ParseRewardAmount(receipt)
+	if err != nil {
+		log.Printf("Reward %d: %v", reward.ID, err)
+		amount = new(big.Int)
+	}
+
+	reward.Status = RewardPaid
+	reward.Amount = amount.String()
+	reward.TxHash = job.TxHash()
+	reward.PaidAt = time.Now()
+	reward.UpdatedAt = reward.PaidAt
+
+	if err := s.Store.SaveReward(reward); err != nil {
+		log.Printf("Failed to update reward %d: %v", reward.ID, err)
+	}
+}
+
+func (s *WeatherService) GetDeviceRewards(c *gin.Context) {
+	deviceID, err := normalizeDeviceID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	rewards, err := s.Store.ListDeviceRewards(deviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rewards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"device_id": deviceID,
+		"rewards":   rewards,
+		"count":     len(rewards),
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRewardService returns a service paying rewards through a reward manager
+// on the simulated chain.
+func newRewardService(t *testing.T, limit, amount *big.Int) (*WeatherService, *testChain) {
+	t.Helper()
+
+	chain := newTestChain(t)
+	chain.withRewardManager(t, limit, amount)
+
+	service := newTestService(t)
+	service.Config.RewardMinSubmissions = 3
+	service.Chain = chain.client
+	service.TxQueue = chain.newQueue(nil, service.Store, 5)
+	service.TxQueue.OnConfirmed(service.handleConfirmedTx)
+	return service, chain
+}
+
+func addSubmissions(t *testing.T, service *WeatherService, deviceID string, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		now := time.Now()
+		record := &SubmissionRecord{
+			WeatherData: WeatherData{DeviceID: deviceID, Timestamp: now},
+			ReceivedAt:  now,
+		}
+		if err := service.Store.SaveSubmission(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDistributeRewards(t *testing.T) {
+	ctx := context.Background()
+	amount := big.NewInt(1000)
+	service, chain := newRewardService(t, big.NewInt(1_000_000), amount)
+
+	eligible := newTestStation(t, service).device
+	addSubmissions(t, service, eligible.DeviceID, 3)
+
+	quiet := newTestStation(t, service).device
+	addSubmissions(t, service, quiet.DeviceID, 2)
+
+	invalid := &DeviceRegistration{
+		DeviceID:         strings.Repeat("ab", 33),
+		RegistrationTime: time.Now(),
+		IsActive:         true,
+	}
+	if err := service.Store.SaveDevice(invalid); err != nil {
+		t.Fatal(err)
+	}
+	addSubmissions(t, service, invalid.DeviceID, 3)
+
+	if err := service.distributeRewards(ctx); err != nil {
+		t.Fatalf("distributeRewards: %v", err)
+	}
+	service.TxQueue.process(ctx)
+	chain.sim.Commit()
+	service.TxQueue.process(ctx)
+
+	rewards, err := service.Store.ListDeviceRewards(eligible.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 1 {
+		t.Fatalf("eligible device has %d rewards, want 1", len(rewards))
+	}
+	if reward := rewards[0]; reward.Status != RewardPaid || reward.Amount != amount.String() || reward.Submissions != 3 || reward.TxHash == "" {
+		t.Errorf("reward is %s for %s after %d submissions (%q), want paid %s for 3", reward.Status, reward.Amount, reward.Submissions, reward.LastError, amount)
+	}
+
+	rewards, err = service.Store.ListDeviceRewards(quiet.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 0 {
+		t.Errorf("device below REWARD_MIN_SUBMISSIONS has %d rewards", len(rewards))
+	}
+
+	// A device ID the contract cannot take is recorded as a failed reward
+	// rather than skipped without a trace.
+	rewards, err = service.Store.ListDeviceRewards(invalid.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 1 {
+		t.Fatalf("invalid device has %d rewards, want 1", len(rewards))
+	}
+	if reward := rewards[0]; reward.Status != RewardFailed || reward.Attempts != 1 || !strings.Contains(reward.LastError, "longer than 32 bytes") {
+		t.Errorf("invalid device reward is %s after %d attempts (%q), want failed after 1", reward.Status, reward.Attempts, reward.LastError)
+	}
+
+	// The paid period is not paid again.
+	if err := service.distributeRewards(ctx); err != nil {
+		t.Fatalf("distributeRewards: %v", err)
+	}
+	jobs, err := service.Store.ListPendingTxJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("second pass queued %d jobs", len(jobs))
+	}
+	rewards, err = service.Store.ListDeviceRewards(eligible.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 1 {
+		t.Errorf("eligible device has %d rewards after a second pass, want 1", len(rewards))
+	}
+}
+
+func TestDistributeRewardsDailyLimit(t *testing.T) {
+	ctx := context.Background()
+	amount := big.NewInt(1000)
+	service, _ := newRewardService(t, amount, amount)
+
+	var devices []*DeviceRegistration
+	for i := 0; i < 2; i++ {
+		device := newTestStation(t, service).device
+		addSubmissions(t, service, device.DeviceID, 3)
+		devices = append(devices, device)
+	}
+
+	if err := service.distributeRewards(ctx); err != nil {
+		t.Fatalf("distributeRewards: %v", err)
+	}
+
+	queued := 0
+	for _, device := range devices {
+		rewards, err := service.Store.ListDeviceRewards(device.DeviceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		queued += len(rewards)
+	}
+	if queued != 1 {
+		t.Errorf("queued %d rewards with room for one under the daily limit", queued)
+	}
+}
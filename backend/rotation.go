@@ -0,0 +1,190 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"weather-protocol"
+)
+
+const rotationMaxSkew = 10 * time.Minute
+
+var errKeyChanged = errors.New("device key changed during rotation")
+
+// RetiredKey is a device key that was replaced by a rotation. Submissions keep
+// the key that signed them, so the window here shows which key was valid when.
+type RetiredKey struct {
+	PublicKey  string    `json:"public_key"`
+	ActiveFrom time.Time `json:"active_from"`
+	RetiredAt  time.Time `json:"retired_at"`
+}
+
+type RotationRequest struct {
+	Rotation        protocol.KeyRotation `json:"rotation"`
+	Version         int                  `json:"version"`
+	Signature       string               `json:"signature"`
+	NewKeySignature string               `json:"new_key_signature"`
+}
+
+// originalPublicKey returns the key the device registered with, which its
+// device ID is derived from.
+func (d *DeviceRegistration) originalPublicKey() string {
+	if len(d.KeyHistory) > 0 {
+		return d.KeyHistory[0].PublicKey
+	}
+	return d.PublicKey
+}
+
+func (d *DeviceRegistration) usedPublicKey(publicKey string) bool {
+	if d.PublicKey == publicKey {
+		return true
+	}
+	for _, key := range d.KeyHistory {
+		if key.PublicKey == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WeatherService) RotateDeviceKey(c *gin.Context) {
+	deviceID, err := normalizeDeviceID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var request RotationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rotation request"})
+		return
+	}
+
+	if requestID, err := normalizeDeviceID(request.Rotation.DeviceID); err != nil || requestID != deviceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation is for a different device"})
+		return
+	}
+
+	skew := time.Since(request.Rotation.Timestamp)
+	if skew > rotationMaxSkew || skew < -rotationMaxSkew {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation timestamp is too far from the current time"})
+		return
+	}
+
+	newKey, newKeyBytes, err := parsePublicKey(request.Rotation.NewPublicKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid new public key"})
+		return
+	}
+	newPublicKey := hex.EncodeToString(newKeyBytes)
+
+	device, err := s.Store.GetDevice(deviceID)
+	if errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load device"})
+		return
+	}
+
+	if !device.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Device is not active"})
+		return
+	}
+
+	if device.usedPublicKey(newPublicKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "New key has already been used by this device"})
+		return
+	}
+
+	// The new key's own derived ID must stay free, otherwise one key would sign
+	// for two identities.
+	if _, err := s.Store.GetDevice(deriveDeviceID(newKeyBytes)); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "New key is registered to another device"})
+		return
+	} else if !errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load device"})
+		return
+	}
+
+	currentKey, _, err := parsePublicKey(device.PublicKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stored device key is invalid"})
+		return
+	}
+
+	digest, err := protocol.RotationDigest(request.Rotation, request.Version)
+	if errors.Is(err, protocol.ErrUnsupportedVersion) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported rotation version"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rotation request"})
+		return
+	}
+
+	if !verifyDigest(currentKey, digest, request.Signature) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Rotation is not signed by the current device key"})
+		return
+	}
+	if !verifyDigest(newKey, digest, request.NewKeySignature) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation is not signed by the new key"})
+		return
+	}
+
+	verifiedKey := device.PublicKey
+	rotatedAt := time.Now()
+	err = s.Store.UpdateDevice(deviceID, func(device *DeviceRegistration) error {
+		if device.PublicKey != verifiedKey {
+			return errKeyChanged
+		}
+
+		activeFrom := device.RegistrationTime
+		if len(device.KeyHistory) > 0 {
+			activeFrom = device.KeyHistory[len(device.KeyHistory)-1].RetiredAt
+		}
+
+		device.KeyHistory = append(device.KeyHistory, RetiredKey{
+			PublicKey:  device.PublicKey,
+			ActiveFrom: activeFrom,
+			RetiredAt:  rotatedAt,
+		})
+		device.PublicKey = newPublicKey
+		return nil
+	})
+	if errors.Is(err, errKeyChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device key changed during rotation, retry"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store rotated key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Device key rotated successfully",
+		"device_id":  deviceID,
+		"public_key": newPublicKey,
+		"rotated_at": rotatedAt,
+	})
+}
+
+func verifyDigest(publicKey *ecdsa.PublicKey, digest [32]byte, signatureHex string) bool {
+	signatureBytes, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false
+	}
+
+	r, sigS, err := protocol.DecodeSignature(signatureBytes)
+	if err != nil {
+		return false
+	}
+
+	return ecdsa.Verify(publicKey, digest[:], r, sigS)
+}
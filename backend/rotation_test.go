@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"weather-protocol"
+)
+
+func newRotationKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, hex.EncodeToString(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+}
+
+// rotationRequest asks for the device to move to newKey, signed by current as
+// the device key.
+func rotationRequest(t *testing.T, deviceID string, current, newKey *ecdsa.PrivateKey, timestamp time.Time) RotationRequest {
+	t.Helper()
+
+	rotation := protocol.KeyRotation{
+		DeviceID:     deviceID,
+		NewPublicKey: hex.EncodeToString(elliptic.Marshal(elliptic.P256(), newKey.X, newKey.Y)),
+		Timestamp:    timestamp,
+	}
+	digest, err := protocol.RotationDigest(rotation, protocol.RotationVersionV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(key *ecdsa.PrivateKey) string {
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hex.EncodeToString(protocol.EncodeSignature(r, s))
+	}
+	return RotationRequest{
+		Rotation:        rotation,
+		Version:         protocol.RotationVersionV1,
+		Signature:       sign(current),
+		NewKeySignature: sign(newKey),
+	}
+}
+
+func rotate(t *testing.T, service *WeatherService, deviceID string, request RotationRequest) (int, string) {
+	t.Helper()
+
+	var response struct {
+		Error string `json:"error"`
+	}
+	code := serve(t, service.RotateDeviceKey, http.MethodPost, "/api/devices/:id/rotate", "/api/devices/"+deviceID+"/rotate", request, &response)
+	return code, response.Error
+}
+
+func TestRotateDeviceKey(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+	deviceID := station.device.DeviceID
+	original := station.key
+
+	next, nextPublicKey := newRotationKey(t)
+	if code, message := rotate(t, service, deviceID, rotationRequest(t, deviceID, original, next, time.Now())); code != http.StatusOK {
+		t.Fatalf("rotation signed by the current key returned %d: %s", code, message)
+	}
+
+	device, err := service.Store.GetDevice(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.PublicKey != nextPublicKey {
+		t.Errorf("device key is %s, want the new key", device.PublicKey)
+	}
+	if len(device.KeyHistory) != 1 || device.KeyHistory[0].PublicKey != station.device.PublicKey {
+		t.Errorf("key history = %+v, want the original key retired", device.KeyHistory)
+	}
+
+	// Readings are now accepted with the new key and not with the retired one.
+	submit := func(key *ecdsa.PrivateKey) int {
+		payload := signPayload(t, key, station.reading(time.Now()), protocol.SigVersionV1)
+		return serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, nil)
+	}
+	if code := submit(next); code != http.StatusOK {
+		t.Errorf("reading signed by the new key returned %d", code)
+	}
+	if code := submit(original); code == http.StatusOK {
+		t.Error("reading signed by the retired key was accepted")
+	}
+
+	another, _ := newRotationKey(t)
+	stranger, _ := newRotationKey(t)
+	mismatched := rotationRequest(t, deviceID, next, another, time.Now())
+	mismatched.NewKeySignature = rotationRequest(t, deviceID, next, stranger, time.Now()).NewKeySignature
+
+	tests := []struct {
+		name     string
+		request  RotationRequest
+		wantCode int
+	}{
+		{"signed by the retired key", rotationRequest(t, deviceID, original, another, time.Now()), http.StatusForbidden},
+		{"signed by another device's key", rotationRequest(t, deviceID, stranger, another, time.Now()), http.StatusForbidden},
+		{"new key signature by another key", mismatched, http.StatusBadRequest},
+		{"back to the retired key", rotationRequest(t, deviceID, next, original, time.Now()), http.StatusConflict},
+		{"stale timestamp", rotationRequest(t, deviceID, next, another, time.Now().Add(-time.Hour)), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code, message := rotate(t, service, deviceID, tt.request); code != tt.wantCode {
+			t.Errorf("%s: rotation returned %d (%s), want %d", tt.name, code, message, tt.wantCode)
+		}
+	}
+
+	if device, err := service.Store.GetDevice(deviceID); err != nil || device.PublicKey != nextPublicKey {
+		t.Errorf("a rejected rotation changed the device key")
+	}
+}
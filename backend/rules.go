@@ -0,0 +1,354 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"weather-protocol"
+)
+
+// ValidationRules decide which readings are plausible enough to accept. The
+// defaults can be overridden per rule from the JSON file at
+// VALIDATION_RULES_PATH; see rules.example.json.
+type ValidationRules struct {
+	Ranges         map[string]RangeRule `json:"ranges"`
+	WindDirections []string             `json:"wind_directions"`
+	RateOfChange   RateOfChangeRules    `json:"rate_of_change"`
+	CrossField     []CrossFieldRule     `json:"cross_field"`
+	Timestamp      TimestampRule        `json:"timestamp"`
+}
+
+// RangeRule bounds one measurement. With ElevationAdjusted the bounds are for
+// sea level and are scaled down to the device's registered elevation, which is
+// how station pressure is checked at altitude.
+type RangeRule struct {
+	Min               float64 `json:"min"`
+	Max               float64 `json:"max"`
+	MaxExclusive      bool    `json:"max_exclusive,omitempty"`
+	ElevationAdjusted bool    `json:"elevation_adjusted,omitempty"`
+}
+
+// RateOfChangeRules limit how fast a measurement may change between a device's
+// consecutive readings. Readings closer together than MinIntervalSeconds are
+// allowed the change for that interval, so sensor noise between frequent
+// readings is not rejected. Readings more than MaxGapSeconds apart are not
+// compared.
+type RateOfChangeRules struct {
+	MinIntervalSeconds int                `json:"min_interval_seconds"`
+	MaxGapSeconds      int                `json:"max_gap_seconds"`
+	MaxPerHour         map[string]float64 `json:"max_per_hour"`
+}
+
+// CrossFieldRule requires Field Op Other, give or take Tolerance. It only
+// applies when the reading has both fields.
+type CrossFieldRule struct {
+	Field     string  `json:"field"`
+	Op        string  `json:"op"`
+	Other     string  `json:"other"`
+	Tolerance float64 `json:"tolerance"`
+}
+
+type TimestampRule struct {
+	MaxAgeSeconds    int `json:"max_age_seconds"`
+	MaxFutureSeconds int `json:"max_future_seconds"`
+}
+
+type Violation struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func DefaultValidationRules() *ValidationRules {
+	return &ValidationRules{
+		Ranges: map[string]RangeRule{
+			"temperature":       {Min: -100, Max: 70},
+			"humidity":          {Min: 0, Max: 100},
+			"pressure":          {Min: 800, Max: 1200, ElevationAdjusted: true},
+			"wind_speed":        {Min: 0, Max: 200},
+			"rain_rate":         {Min: 0, Max: 500},
+			"rain_accumulation": {Min: 0, Max: 2000},
+			"solar_radiation":   {Min: 0, Max: 1500},
+			"uv_index":          {Min: 0, Max: 20},
+			"pm2_5":             {Min: 0, Max: 1000},
+			"pm10":              {Min: 0, Max: 2000},
+			"dew_point":         {Min: -100, Max: 70},
+			"wind_gust":         {Min: 0, Max: 400},
+			"wind_bearing":      {Min: 0, Max: 360, MaxExclusive: true},
+			"snow_depth":        {Min: 0, Max: 1500},
+			"soil_moisture":     {Min: 0, Max: 100},
+		},
+		WindDirections: []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"},
+		RateOfChange: RateOfChangeRules{
+			MinIntervalSeconds: 600,
+			MaxGapSeconds:      10800,
+			MaxPerHour: map[string]float64{
+				"temperature": 10,
+				"humidity":    60,
+				"pressure":    10,
+			},
+		},
+		CrossField: []CrossFieldRule{
+			{Field: "dew_point", Op: "<=", Other: "temperature", Tolerance: 0.5},
+			{Field: "wind_gust", Op: ">=", Other: "wind_speed"},
+			{Field: "pm2_5", Op: "<=", Other: "pm10", Tolerance: 5},
+		},
+		Timestamp: TimestampRule{
+			MaxAgeSeconds:    3600,
+			MaxFutureSeconds: 300,
+		},
+	}
+}
+
+// LoadValidationRules returns the defaults, overridden by the file at path if
+// one is given. Each entry in ranges and max_per_hour replaces the default for
+// that field, and cross_field and wind_directions replace the default lists.
+func LoadValidationRules(path string) (*ValidationRules, error) {
+	rules := DefaultValidationRules()
+	if path == "" {
+		return rules, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open validation rules: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rules); err != nil {
+		return nil, fmt.Errorf("failed to parse validation rules %s: %v", path, err)
+	}
+
+	if err := rules.check(); err != nil {
+		return nil, fmt.Errorf("invalid validation rules %s: %v", path, err)
+	}
+	return rules, nil
+}
+
+func (r *ValidationRules) check() error {
+	for field, rule := range r.Ranges {
+		if !knownField(field) {
+			return fmt.Errorf("ranges: unknown field %q", field)
+		}
+		if rule.Min > rule.Max {
+			return fmt.Errorf("ranges: %s min is above max", field)
+		}
+	}
+	for _, field := range measurementFields() {
+		if _, ok := r.Ranges[field]; !ok {
+			return fmt.Errorf("ranges: no range for %s", field)
+		}
+	}
+
+	if len(r.WindDirections) == 0 {
+		return fmt.Errorf("wind_directions must not be empty")
+	}
+
+	for field, limit := range r.RateOfChange.MaxPerHour {
+		if !knownField(field) {
+			return fmt.Errorf("rate_of_change: unknown field %q", field)
+		}
+		if limit <= 0 {
+			return fmt.Errorf("rate_of_change: %s limit must be positive", field)
+		}
+	}
+	if r.RateOfChange.MinIntervalSeconds <= 0 || r.RateOfChange.MaxGapSeconds <= 0 {
+		return fmt.Errorf("rate_of_change: intervals must be positive")
+	}
+
+	for i, rule := range r.CrossField {
+		if !knownField(rule.Field) || !knownField(rule.Other) {
+			return fmt.Errorf("cross_field %d: unknown field", i)
+		}
+		if _, ok := crossFieldOps[rule.Op]; !ok {
+			return fmt.Errorf("cross_field %d: unknown op %q", i, rule.Op)
+		}
+		if rule.Tolerance < 0 {
+			return fmt.Errorf("cross_field %d: tolerance must not be negative", i)
+		}
+	}
+
+	if r.Timestamp.MaxAgeSeconds <= 0 || r.Timestamp.MaxFutureSeconds < 0 {
+		return fmt.Errorf("timestamp: max_age_seconds must be positive and max_future_seconds not negative")
+	}
+	return nil
+}
+
+// LiveMaxAge is the oldest reading accepted through the live endpoint. Older
+// readings can only be sent as batch backfill.
+func (r *ValidationRules) LiveMaxAge() time.Duration {
+	return time.Duration(r.Timestamp.MaxAgeSeconds) * time.Second
+}
+
+var crossFieldOps = map[string]struct {
+	holds func(a, b, tolerance float64) bool
+	text  string
+}{
+	"<":  {func(a, b, t float64) bool { return a < b+t }, "below"},
+	"<=": {func(a, b, t float64) bool { return a <= b+t }, "at most"},
+	">":  {func(a, b, t float64) bool { return a > b-t }, "above"},
+	">=": {func(a, b, t float64) bool { return a >= b-t }, "at least"},
+}
+
+var coreFields = map[string]func(*WeatherData) float64{
+	"temperature": func(d *WeatherData) float64 { return d.Temperature },
+	"humidity":    func(d *WeatherData) float64 { return d.Humidity },
+	"pressure":    func(d *WeatherData) float64 { return d.Pressure },
+	"wind_speed":  func(d *WeatherData) float64 { return d.WindSpeed },
+}
+
+// measurementFields lists every numeric field, core ones first, in a stable
+// order so violations are reported consistently.
+func measurementFields() []string {
+	fields := make([]string, 0, len(coreFields)+len(protocol.OptionalFields))
+	for field := range coreFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range protocol.OptionalFields {
+		fields = append(fields, field.Name)
+	}
+	return fields
+}
+
+func knownField(name string) bool {
+	if _, ok := coreFields[name]; ok {
+		return true
+	}
+	for _, field := range protocol.OptionalFields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue returns a numeric field by its JSON name, and false when it is an
+// optional field the reading does not have.
+func fieldValue(data *WeatherData, name string) (float64, bool) {
+	if get, ok := coreFields[name]; ok {
+		return get(data), true
+	}
+	for _, field := range protocol.OptionalFields {
+		if field.Name == name {
+			if value := field.Get(data); value != nil {
+				return *value, true
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// pressureFactor is the ratio of station pressure to sea-level pressure at
+// the given elevation in metres, from the standard atmosphere.
+func pressureFactor(elevation float64) float64 {
+	return math.Pow(1-2.25577e-5*elevation, 5.25588)
+}
+
+// Validate checks a reading against every rule and returns all violations.
+// previous is the device's last accepted reading and may be nil.
+func (r *ValidationRules) Validate(device *DeviceRegistration, data WeatherData, previous *WeatherData, maxAge time.Duration) []Violation {
+	violations := make([]Violation, 0)
+	add := func(rule, field, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, field := range measurementFields() {
+		value, ok := fieldValue(&data, field)
+		if !ok {
+			continue
+		}
+
+		rule := r.Ranges[field]
+		min, max := rule.Min, rule.Max
+		if rule.ElevationAdjusted && device.Elevation != nil {
+			factor := pressureFactor(*device.Elevation)
+			min, max = min*factor, max*factor
+		}
+
+		switch {
+		case rule.ElevationAdjusted && device.Elevation == nil && (value < min || value > max):
+			add("range", field, "%s must be between %.4g and %.4g at sea level; register the device's elevation for stations above it", field, min, max)
+		case math.IsNaN(value), value < min, value > max:
+			add("range", field, "%s must be between %.4g and %.4g", field, min, max)
+		case rule.MaxExclusive && value == max:
+			add("range", field, "%s must be below %.4g", field, max)
+		}
+	}
+
+	if !containsString(r.WindDirections, data.WindDir) {
+		add("enum", "wind_direction", "wind_direction must be one of %v", r.WindDirections)
+	}
+
+	for _, rule := range r.CrossField {
+		value, ok := fieldValue(&data, rule.Field)
+		other, otherOK := fieldValue(&data, rule.Other)
+		if !ok || !otherOK {
+			continue
+		}
+		if op := crossFieldOps[rule.Op]; !op.holds(value, other, rule.Tolerance) {
+			add("cross_field", rule.Field, "%s (%.4g) must be %s %s (%.4g)", rule.Field, value, op.text, rule.Other, other)
+		}
+	}
+
+	if previous != nil {
+		r.checkRateOfChange(data, *previous, add)
+	}
+
+	age := time.Since(data.Timestamp)
+	maxFuture := time.Duration(r.Timestamp.MaxFutureSeconds) * time.Second
+	switch {
+	case age > maxAge:
+		add("timestamp", "timestamp", "timestamp is more than %s old", maxAge)
+	case age < -maxFuture:
+		add("timestamp", "timestamp", "timestamp is more than %s in the future", maxFuture)
+	}
+
+	return violations
+}
+
+func (r *ValidationRules) checkRateOfChange(data, previous WeatherData, add func(rule, field, format string, args ...interface{})) {
+	elapsed := data.Timestamp.Sub(previous.Timestamp)
+	if elapsed > time.Duration(r.RateOfChange.MaxGapSeconds)*time.Second {
+		return
+	}
+	window := elapsed
+	if minInterval := time.Duration(r.RateOfChange.MinIntervalSeconds) * time.Second; window < minInterval {
+		window = minInterval
+	}
+
+	fields := make([]string, 0, len(r.RateOfChange.MaxPerHour))
+	for field := range r.RateOfChange.MaxPerHour {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		value, ok := fieldValue(&data, field)
+		last, lastOK := fieldValue(&previous, field)
+		if !ok || !lastOK {
+			continue
+		}
+
+		limit := r.RateOfChange.MaxPerHour[field]
+		if change, allowed := math.Abs(value-last), limit*window.Hours(); change > allowed {
+			add("rate_of_change", field, "%s changed by %.4g in %s since the previous reading; at most %.4g is allowed (%g per hour)", field, change, elapsed.Round(time.Second), allowed, limit)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
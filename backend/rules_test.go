@@ -0,0 +1,143 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeRules(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadValidationRules(t *testing.T) {
+	rules, err := LoadValidationRules("")
+	if err != nil {
+		t.Fatalf("LoadValidationRules without a file: %v", err)
+	}
+	if !reflect.DeepEqual(rules, DefaultValidationRules()) {
+		t.Error("rules without a file are not the defaults")
+	}
+
+	rules, err = LoadValidationRules("rules.example.json")
+	if err != nil {
+		t.Fatalf("LoadValidationRules(rules.example.json): %v", err)
+	}
+
+	// Listed fields replace their defaults and the rest are kept.
+	defaults := DefaultValidationRules()
+	if got := rules.Ranges["temperature"]; got != (RangeRule{Min: -60, Max: 55}) {
+		t.Errorf("temperature range = %+v, want -60..55", got)
+	}
+	if got := rules.Ranges["pressure"]; got != (RangeRule{Min: 870, Max: 1085, ElevationAdjusted: true}) {
+		t.Errorf("pressure range = %+v, want 870..1085 at sea level", got)
+	}
+	if got := rules.Ranges["wind_bearing"]; got != defaults.Ranges["wind_bearing"] {
+		t.Errorf("wind_bearing range = %+v, want the default %+v", got, defaults.Ranges["wind_bearing"])
+	}
+	if got := rules.RateOfChange.MaxPerHour["pressure"]; got != 6 {
+		t.Errorf("pressure max_per_hour = %v, want 6", got)
+	}
+	if !reflect.DeepEqual(rules.WindDirections, defaults.WindDirections) {
+		t.Errorf("wind_directions = %v, want the defaults", rules.WindDirections)
+	}
+
+	// A list replaces the whole default list.
+	rules, err = LoadValidationRules(writeRules(t, `{"wind_directions": ["N", "S"], "cross_field": []}`))
+	if err != nil {
+		t.Fatalf("LoadValidationRules: %v", err)
+	}
+	if !reflect.DeepEqual(rules.WindDirections, []string{"N", "S"}) || len(rules.CrossField) != 0 {
+		t.Errorf("wind_directions = %v and %d cross_field rules, want [N S] and none", rules.WindDirections, len(rules.CrossField))
+	}
+}
+
+func TestLoadValidationRulesRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{"unknown key", `{"range": {}}`, `unknown field "range"`},
+		{"unknown range field", `{"ranges": {"visibility": {"min": 0, "max": 1}}}`, `ranges: unknown field "visibility"`},
+		{"inverted range", `{"ranges": {"humidity": {"min": 100, "max": 0}}}`, "ranges: humidity min is above max"},
+		{"no wind directions", `{"wind_directions": []}`, "wind_directions must not be empty"},
+		{"unknown rate field", `{"rate_of_change": {"max_per_hour": {"visibility": 1}}}`, `rate_of_change: unknown field "visibility"`},
+		{"zero rate", `{"rate_of_change": {"max_per_hour": {"humidity": 0}}}`, "rate_of_change: humidity limit must be positive"},
+		{"zero interval", `{"rate_of_change": {"min_interval_seconds": 0}}`, "rate_of_change: intervals must be positive"},
+		{"unknown cross field", `{"cross_field": [{"field": "dew_point", "op": "<=", "other": "visibility"}]}`, "cross_field 0: unknown field"},
+		{"unknown op", `{"cross_field": [{"field": "dew_point", "op": "!=", "other": "temperature"}]}`, `cross_field 0: unknown op "!="`},
+		{"negative tolerance", `{"cross_field": [{"field": "dew_point", "op": "<=", "other": "temperature", "tolerance": -1}]}`, "cross_field 0: tolerance must not be negative"},
+		{"zero max age", `{"timestamp": {"max_age_seconds": 0}}`, "timestamp: max_age_seconds must be positive"},
+		{"not JSON", `ranges: {}`, "failed to parse validation rules"},
+	}
+
+	for _, tt := range tests {
+		if _, err := LoadValidationRules(writeRules(t, tt.contents)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: LoadValidationRules error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := LoadValidationRules(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadValidationRules succeeded without the file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rules := DefaultValidationRules()
+	now := time.Now()
+	reading := WeatherData{Temperature: 20, Humidity: 50, Pressure: 1013, WindSpeed: 10, WindDir: "N", Timestamp: now}
+	value := func(v float64) *float64 { return &v }
+	elevation := 3000.0
+
+	tests := []struct {
+		name     string
+		device   DeviceRegistration
+		mutate   func(d *WeatherData)
+		previous *WeatherData
+		want     []string
+	}{
+		{"plausible", DeviceRegistration{}, func(d *WeatherData) {}, nil, nil},
+		{"too hot", DeviceRegistration{}, func(d *WeatherData) { d.Temperature = 80 }, nil, []string{"range temperature"}},
+		{"NaN", DeviceRegistration{}, func(d *WeatherData) { d.Humidity = math.NaN() }, nil, []string{"range humidity"}},
+		{"bearing of 360", DeviceRegistration{}, func(d *WeatherData) { d.WindBearing = value(360) }, nil, []string{"range wind_bearing"}},
+		{"unknown direction", DeviceRegistration{}, func(d *WeatherData) { d.WindDir = "NNE" }, nil, []string{"enum wind_direction"}},
+		{"dew point above temperature", DeviceRegistration{}, func(d *WeatherData) { d.DewPoint = value(21) }, nil, []string{"cross_field dew_point"}},
+		{"dew point within tolerance", DeviceRegistration{}, func(d *WeatherData) { d.DewPoint = value(20.4) }, nil, nil},
+		{"station pressure at altitude", DeviceRegistration{Elevation: &elevation}, func(d *WeatherData) { d.Pressure = 700 }, nil, nil},
+		{"station pressure without elevation", DeviceRegistration{}, func(d *WeatherData) { d.Pressure = 700 }, nil, []string{"range pressure"}},
+		{"too old", DeviceRegistration{}, func(d *WeatherData) { d.Timestamp = now.Add(-2 * time.Hour) }, nil, []string{"timestamp timestamp"}},
+		{"in the future", DeviceRegistration{}, func(d *WeatherData) { d.Timestamp = now.Add(time.Hour) }, nil, []string{"timestamp timestamp"}},
+		{"fast change", DeviceRegistration{}, func(d *WeatherData) { d.Temperature = 25 },
+			&WeatherData{Temperature: 20, Humidity: 50, Pressure: 1013, Timestamp: now.Add(-15 * time.Minute)}, []string{"rate_of_change temperature"}},
+		{"noise between close readings", DeviceRegistration{}, func(d *WeatherData) { d.Temperature = 21.5 },
+			&WeatherData{Temperature: 20, Humidity: 50, Pressure: 1013, Timestamp: now.Add(-time.Minute)}, nil},
+		{"change after a long gap", DeviceRegistration{}, func(d *WeatherData) { d.Temperature = 45 },
+			&WeatherData{Temperature: 20, Humidity: 50, Pressure: 1013, Timestamp: now.Add(-4 * time.Hour)}, nil},
+		{"every violation reported", DeviceRegistration{}, func(d *WeatherData) { d.Temperature = 80; d.WindDir = "?"; d.Timestamp = now.Add(time.Hour) },
+			nil, []string{"range temperature", "enum wind_direction", "timestamp timestamp"}},
+	}
+
+	for _, tt := range tests {
+		data := reading
+		tt.mutate(&data)
+
+		var got []string
+		for _, violation := range rules.Validate(&tt.device, data, tt.previous, rules.LiveMaxAge()) {
+			got = append(got, violation.Rule+" "+violation.Field)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: violations = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
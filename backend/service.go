@@ -3,16 +3,23 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
+
+	"weather-protocol"
 )
 
 type WeatherService struct {
@@ -20,33 +27,51 @@ type WeatherService struct {
 	EthClient  *ethclient.Client
 	PrivateKey *ecdsa.PrivateKey
 	Auth       *bind.TransactOpts
+	Store      Store
+	Chain      *ChainClient
+	TxQueue    *TxQueue
+	Rules      *ValidationRules
 
 	submissionCounts map[string][]time.Time
+	backfillCounts   map[string][]time.Time
+	reputations      map[string]*Reputation
 	mu               sync.RWMutex
 }
 
 type DeviceRegistration struct {
-	DeviceID  string `json:"device_id"`
-	PublicKey string `json:"public_key"`
-	Location  string `json:"location"`
-}
+	DeviceID         string    `json:"device_id"`
+	PublicKey        string    `json:"public_key"`
+	Location         string    `json:"location"`
+	Latitude         *float64  `json:"latitude,omitempty"`
+	Longitude        *float64  `json:"longitude,omitempty"`
+	Elevation        *float64  `json:"elevation,omitempty"`
+	RegistrationTime time.Time `json:"registration_time"`
+	IsActive         bool      `json:"is_active"`
+	LastSubmission   time.Time `json:"last_submission"`
+	TotalSubmissions uint64    `json:"total_submissions"`
+	LastSequence     uint64    `json:"last_sequence"`
+	Degraded         bool      `json:"degraded"`
+	DegradedSince    time.Time `json:"degraded_since,omitempty"`
+
+	Stats      *DeviceStats `json:"stats,omitempty"`
+	Reputation *Reputation  `json:"reputation,omitempty"`
 
-type WeatherData struct {
-	DeviceID    string    `json:"device_id"`
-	Location    string    `json:"location"`
-	Temperature float64   `json:"temperature"`
-	Humidity    float64   `json:"humidity"`
-	Pressure    float64   `json:"pressure"`
-	WindSpeed   float64   `json:"wind_speed"`
-	WindDir     string    `json:"wind_direction"`
-	Timestamp   time.Time `json:"timestamp"`
+	KeyHistory []RetiredKey `json:"key_history,omitempty"`
+
+	Owner                 string    `json:"owner,omitempty"`
+	OnChainSubmissions    uint64    `json:"onchain_submissions,omitempty"`
+	LastOnChainSubmission time.Time `json:"last_onchain_submission,omitempty"`
+	TotalRewards          string    `json:"total_rewards,omitempty"`
 }
 
+type WeatherData = protocol.WeatherData
+
 type SubmissionPayload struct {
 	WeatherData WeatherData `json:"weather_data"`
 	DataHash    string      `json:"data_hash"`
 	Signature   string      `json:"signature"`
 	PublicKey   string      `json:"public_key"`
+	SigVersion  int         `json:"sig_version"`
 }
 
 type PinataResponse struct {
@@ -79,13 +104,66 @@ func NewWeatherService(config *Config) (*WeatherService, error) {
 		}
 	}
 
-	return &WeatherService{
+	rules, err := LoadValidationRules(config.ValidationRulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := NewBoltStore(config.DatabasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &WeatherService{
 		Config:           config,
 		EthClient:        client,
 		PrivateKey:       privateKey,
 		Auth:             auth,
+		Store:            store,
+		Rules:            rules,
 		submissionCounts: make(map[string][]time.Time),
-	}, nil
+		backfillCounts:   make(map[string][]time.Time),
+		reputations:      make(map[string]*Reputation),
+	}
+
+	if common.IsHexAddress(config.WeatherDataAddr) && common.IsHexAddress(config.DeviceRegistryAddr) {
+		var rewardManagerAddr common.Address
+		if common.IsHexAddress(config.RewardManagerAddr) {
+			rewardManagerAddr = common.HexToAddress(config.RewardManagerAddr)
+		}
+
+		service.Chain, err = NewChainClient(client, common.HexToAddress(config.WeatherDataAddr), common.HexToAddress(config.DeviceRegistryAddr), rewardManagerAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		if auth != nil {
+			service.TxQueue = NewTxQueue(client, auth, store, config)
+			service.TxQueue.OnConfirmed(service.handleConfirmedTx)
+		}
+	}
+
+	return service, nil
+}
+
+func (s *WeatherService) Start(ctx context.Context) {
+	go s.runReputation(ctx)
+
+	if s.Chain != nil {
+		go s.runIndexer(ctx)
+	}
+
+	if s.TxQueue != nil {
+		go s.TxQueue.Run(ctx)
+
+		if s.Config.AnchorMode == anchorModeBatch {
+			go s.runAnchoring(ctx)
+		}
+
+		if s.Chain.HasRewardManager() {
+			go s.runRewards(ctx)
+		}
+	}
 }
 
 func (s *WeatherService) RegisterDevice(c *gin.Context) {
@@ -100,10 +178,56 @@ func (s *WeatherService) RegisterDevice(c *gin.Context) {
 		return
 	}
 
+	deviceID, err := normalizeDeviceID(registration.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	if _, err := s.Store.GetDevice(deviceID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device already registered"})
+		return
+	} else if !errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load device"})
+		return
+	}
+
+	_, publicKeyBytes, err := parsePublicKey(registration.PublicKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid public key"})
+		return
+	}
+
+	if deriveDeviceID(publicKeyBytes) != deviceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Device ID does not match public key"})
+		return
+	}
+
+	if err := validateCoordinates(registration.Latitude, registration.Longitude, registration.Elevation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location: " + err.Error()})
+		return
+	}
+
+	device := &DeviceRegistration{
+		DeviceID:         deviceID,
+		PublicKey:        hex.EncodeToString(publicKeyBytes),
+		Location:         registration.Location,
+		Latitude:         registration.Latitude,
+		Longitude:        registration.Longitude,
+		Elevation:        registration.Elevation,
+		RegistrationTime: time.Now(),
+		IsActive:         true,
+	}
+
+	if err := s.Store.SaveDevice(device); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store device"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Device registration received",
-		"device_id": registration.DeviceID,
-		"status":    "pending_blockchain_confirmation",
+		"message":   "Device registered successfully",
+		"device_id": device.DeviceID,
+		"status":    "registered",
 	})
 }
 
@@ -114,37 +238,285 @@ func (s *WeatherService) SubmitWeatherData(c *gin.Context) {
 		return
 	}
 
-	deviceID := payload.WeatherData.DeviceID
-	if !s.checkRateLimit(deviceID) {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+	c.JSON(s.processSubmission(payload, s.Rules.LiveMaxAge(), false))
+}
+
+// processSubmission verifies, validates and stores a signed reading. backfill
+// marks readings older than the live window that came in through the batch
+// endpoint.
+func (s *WeatherService) processSubmission(payload SubmissionPayload, maxAge time.Duration, backfill bool) (int, gin.H) {
+	device, err := s.verifySignature(payload)
+	switch {
+	case errors.Is(err, errUnknownDevice):
+		return http.StatusForbidden, gin.H{"error": "Device is not registered"}
+	case errors.Is(err, errDeviceInactive):
+		return http.StatusForbidden, gin.H{"error": "Device is not active"}
+	case errors.Is(err, errDeviceIDMismatch), errors.Is(err, errPublicKeyMismatch):
+		return http.StatusForbidden, gin.H{"error": "Device key mismatch"}
+	case errors.Is(err, protocol.ErrUnsupportedVersion):
+		return http.StatusBadRequest, gin.H{"error": "Unsupported signature version"}
+	case errors.Is(err, protocol.ErrOptionalFieldsV1):
+		return http.StatusBadRequest, gin.H{"error": "Optional measurements require sig_version 2"}
+	case errors.Is(err, errInvalidSignature):
+		return http.StatusBadRequest, gin.H{"error": "Invalid signature"}
+	case err != nil:
+		return http.StatusInternalServerError, gin.H{"error": "Failed to verify submission"}
+	}
+
+	// Only signed readings count against the allowances, under the registered
+	// device ID, so neither changing the ID's case nor sending unsigned junk
+	// in another device's name gets around them.
+	if backfill {
+		if !s.checkBackfillLimit(device.DeviceID) {
+			return http.StatusTooManyRequests, gin.H{"error": "Backfill allowance exceeded"}
+		}
+	} else if !s.checkRateLimit(device.DeviceID) {
+		return http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"}
+	}
+
+	if payload.WeatherData.Sequence <= device.LastSequence {
+		return staleSequenceResponse(device)
+	}
+
+	if _, err := s.Store.FindSubmissionByHash(payload.DataHash); err == nil {
+		return http.StatusConflict, gin.H{"error": "Duplicate submission"}
+	} else if !errors.Is(err, ErrNotFound) {
+		return http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicate submission"}
+	}
+
+	// Backfilled readings arrive out of order, so the rate-of-change rules
+	// compare against the reading taken just before this one, not the last
+	// one received.
+	var previous *WeatherData
+	earlier, err := s.Store.FindPreviousSubmission(device.DeviceID, payload.WeatherData.Timestamp)
+	switch {
+	case err == nil:
+		previous = &earlier.WeatherData
+	case !errors.Is(err, ErrNotFound):
+		return http.StatusInternalServerError, gin.H{"error": "Failed to load previous submission"}
+	}
+
+	if violations := s.Rules.Validate(device, payload.WeatherData, previous, maxAge); len(violations) > 0 {
+		return http.StatusBadRequest, gin.H{"error": "Invalid weather data", "violations": violations}
+	}
+
+	ipfsHash, err := s.uploadToPinata("weather_data.json", payload.WeatherData)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"error": "Failed to upload to IPFS"}
+	}
+
+	// Store the registered form of the device ID, so a client sending it in
+	// upper case or with a 0x prefix still lands in the device's indexes.
+	payload.WeatherData.DeviceID = device.DeviceID
+
+	receivedAt := time.Now()
+	record := &SubmissionRecord{
+		WeatherData: payload.WeatherData,
+		DataHash:    payload.DataHash,
+		Signature:   payload.Signature,
+		PublicKey:   device.PublicKey,
+		IPFSHash:    ipfsHash,
+		ReceivedAt:  receivedAt,
+		QC:          s.spatialQC(device, payload.WeatherData),
+		Anomalies:   s.detectAnomalies(device, payload.WeatherData, receivedAt, backfill),
+	}
+
+	err = s.Store.AcceptSubmission(record)
+	switch {
+	case errors.Is(err, ErrDuplicateSubmission):
+		return http.StatusConflict, gin.H{"error": "Duplicate submission"}
+	case errors.Is(err, ErrStaleSequence):
+		if latest, err := s.Store.GetDevice(device.DeviceID); err == nil {
+			device = latest
+		}
+		return staleSequenceResponse(device)
+	case err != nil:
+		return http.StatusInternalServerError, gin.H{"error": "Failed to store submission"}
+	}
+
+	s.updateDeviceStats(device.DeviceID, payload.WeatherData, receivedAt, backfill, record.Flagged())
+
+	chainStatus := "stored"
+	switch {
+	case s.TxQueue != nil && s.Config.AnchorMode == anchorModeBatch:
+		chainStatus = "awaiting_batch"
+		err := s.Store.AddPendingAnchor(PendingAnchor{
+			SubmissionID: record.ID,
+			DeviceID:     device.DeviceID,
+			DataHash:     record.DataHash,
+		})
+		if err != nil {
+			log.Printf("Failed to queue submission %d for batch anchoring: %v", record.ID, err)
+			chainStatus = "failed"
+		}
+	case s.TxQueue != nil:
+		chainStatus = "pending"
+		if err := s.queueChainSubmission(device, record); err != nil {
+			log.Printf("Failed to queue submission %d for on-chain recording: %v", record.ID, err)
+			chainStatus = "failed"
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"message":       "Weather data submitted successfully",
+		"submission_id": record.ID,
+		"ipfs_hash":     ipfsHash,
+		"device_id":     payload.WeatherData.DeviceID,
+		"timestamp":     record.ReceivedAt,
+		"data_hash":     payload.DataHash,
+		"status":        chainStatus,
+		"qc":            record.QC,
+		"anomalies":     record.Anomalies,
+	}
+}
+
+func staleSequenceResponse(device *DeviceRegistration) (int, gin.H) {
+	return http.StatusConflict, gin.H{
+		"error":         "Sequence number must be greater than the last accepted one",
+		"last_sequence": device.LastSequence,
+	}
+}
+
+// queueChainSubmission sends a submission to WeatherData and, for devices
+// registered in DeviceRegistry, records it there too. recordSubmission reverts
+// for devices that only registered through /api/register, so they get no
+// record job.
+func (s *WeatherService) queueChainSubmission(device *DeviceRegistration, record *SubmissionRecord) error {
+	deviceID, err := deviceIDToBytes32(record.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	dataHash, err := hexToBytes32(record.DataHash)
+	if err != nil {
+		return err
+	}
+
+	submitJob, err := s.Chain.SubmitWeatherDataJob(record.ID, deviceID, record.IPFSHash, dataHash)
+	if err != nil {
+		return err
+	}
+
+	if device.Owner == "" {
+		return s.TxQueue.Enqueue(submitJob)
+	}
+
+	recordJob, err := s.Chain.RecordSubmissionJob(record.ID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	return s.TxQueue.Enqueue(submitJob, recordJob)
+}
+
+func (s *WeatherService) handleConfirmedTx(job *TxJob, receipt *types.Receipt) {
+	switch job.Kind {
+	case txKindSubmitWeatherData, txKindRecordSubmission:
+		if job.SubmissionID != 0 {
+			s.handleSubmissionTx(job, receipt)
+		}
+	case txKindAnchorBatch:
+		s.handleAnchoredBatch(job, receipt)
+	case txKindDistributeReward:
+		s.handleRewardPaid(job, receipt)
+	}
+}
+
+func (s *WeatherService) handleSubmissionTx(job *TxJob, receipt *types.Receipt) {
+	record, err := s.Store.GetSubmission(job.SubmissionID)
+	if err != nil {
+		log.Printf("Failed to load submission %d for confirmed transaction: %v", job.SubmissionID, err)
 		return
 	}
 
-	if !s.verifySignature(payload) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
+	if job.Kind == txKindSubmitWeatherData {
+		entryID, err := s.Chain.ParseEntryID(receipt)
+		if err != nil {
+			log.Printf("Submission %d: %v", record.ID, err)
+		} else {
+			record.EntryID = entryID.String()
+		}
+		record.TxHash = job.TxHash()
+	} else {
+		record.RegistryTxHash = job.TxHash()
+	}
+
+	if err := s.Store.SaveSubmission(record); err != nil {
+		log.Printf("Failed to update submission %d: %v", record.ID, err)
+	}
+}
+
+func (s *WeatherService) GetSubmission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID"})
 		return
 	}
 
-	if !s.validateWeatherData(payload.WeatherData) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid weather data"})
+	record, err := s.Store.GetSubmission(id)
+	if errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load submission"})
 		return
 	}
 
-	ipfsHash, err := s.uploadToPinata(payload.WeatherData)
+	jobs, err := s.Store.ListSubmissionTxJobs(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload to IPFS"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load submission transactions"})
 		return
 	}
 
+	if record.BatchID != 0 {
+		batch, err := s.Store.GetAnchorBatch(record.BatchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load anchor batch"})
+			return
+		}
+		if batch.TxJobID != 0 {
+			job, err := s.Store.GetTxJob(batch.TxJobID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load anchor transaction"})
+				return
+			}
+			jobs = append(jobs, *job)
+		}
+	}
+
+	status := submissionChainStatus(jobs)
+	if len(jobs) == 0 && s.TxQueue != nil && s.Config.AnchorMode == anchorModeBatch {
+		status = "awaiting_batch"
+		if record.BatchID != 0 {
+			status = "pending"
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Weather data submitted successfully",
-		"ipfs_hash": ipfsHash,
-		"device_id": deviceID,
-		"timestamp": time.Now(),
-		"data_hash": payload.DataHash,
+		"submission":   record,
+		"status":       status,
+		"transactions": jobs,
 	})
 }
 
+func submissionChainStatus(jobs []TxJob) string {
+	if len(jobs) == 0 {
+		return "stored"
+	}
+
+	status := "confirmed"
+	for _, job := range jobs {
+		switch job.Status {
+		case TxFailed:
+			return "failed"
+		case TxQueued, TxSent:
+			status = "pending"
+		}
+	}
+	return status
+}
+
 func (s *WeatherService) GetWeatherData(c *gin.Context) {
 	limit := 50
 	if l := c.Query("limit"); l != "" {
@@ -153,44 +525,100 @@ func (s *WeatherService) GetWeatherData(c *gin.Context) {
 		}
 	}
 
-	mockData := s.generateMockHistoricalData(limit)
+	var deviceID string
+	if raw := c.Query("device_id"); raw != "" {
+		var err error
+		if deviceID, err = normalizeDeviceID(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+			return
+		}
+	}
+
+	minReputation, err := s.minReputationQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_reputation"})
+		return
+	}
+
+	records, err := s.Store.ListSubmissions(SubmissionFilter{
+		DeviceID:       deviceID,
+		Limit:          limit,
+		ExcludeFlagged: c.Query("exclude_flagged") == "true",
+		MinReputation:  minReputation,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load weather data"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":  mockData,
-		"count": len(mockData),
+		"data":  records,
+		"count": len(records),
 	})
 }
 
 func (s *WeatherService) GetLatestData(c *gin.Context) {
-	mockData := s.generateMockHistoricalData(10)
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	minReputation, err := s.minReputationQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_reputation"})
+		return
+	}
+
+	records, err := s.Store.ListSubmissions(SubmissionFilter{
+		Limit:          limit,
+		ExcludeFlagged: c.Query("exclude_flagged") == "true",
+		MinReputation:  minReputation,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load weather data"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data": mockData,
+		"data": records,
 	})
 }
 
 func (s *WeatherService) GetDevices(c *gin.Context) {
-	mockDevices := []map[string]interface{}{
-		{
-			"device_id":         "0x1a2b3c4d5e6f",
-			"location":          "New York, NY",
-			"last_submission":   time.Now().Add(-time.Hour * 2),
-			"total_submissions": 245,
-			"status":            "active",
-		},
-		{
-			"device_id":         "0x9a8b7c6d5e4f",
-			"location":          "London, UK",
-			"last_submission":   time.Now().Add(-time.Minute * 30),
-			"total_submissions": 189,
-			"status":            "active",
-		},
+	devices, err := s.Store.ListDevices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load devices"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"devices": mockDevices,
-		"count":   len(mockDevices),
+		"devices": devices,
+		"count":   len(devices),
 	})
 }
 
+func (s *WeatherService) GetDevice(c *gin.Context) {
+	deviceID, err := normalizeDeviceID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	device, err := s.Store.GetDevice(deviceID)
+	if errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
 func (s *WeatherService) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":    "healthy",
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	bolt "go.etcd.io/bbolt"
+
+	"weather-protocol"
+)
+
+func newTestService(t *testing.T) *WeatherService {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	store, err := NewBoltStore(filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return &WeatherService{
+		Config:           config,
+		Store:            store,
+		Rules:            DefaultValidationRules(),
+		submissionCounts: make(map[string][]time.Time),
+		backfillCounts:   make(map[string][]time.Time),
+		reputations:      make(map[string]*Reputation),
+	}
+}
+
+// testStation is a registered device with its signing key.
+type testStation struct {
+	key      *ecdsa.PrivateKey
+	device   *DeviceRegistration
+	sequence uint64
+}
+
+func newTestStation(t *testing.T, service *WeatherService) *testStation {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+
+	device := &DeviceRegistration{
+		DeviceID:         deriveDeviceID(publicKey),
+		PublicKey:        hex.EncodeToString(publicKey),
+		Location:         "Test Station",
+		RegistrationTime: time.Now(),
+		IsActive:         true,
+	}
+	if err := service.Store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+	return &testStation{key: key, device: device}
+}
+
+// reading returns a plausible reading with the station's next sequence number.
+func (s *testStation) reading(timestamp time.Time) WeatherData {
+	s.sequence++
+	return WeatherData{
+		DeviceID:    s.device.DeviceID,
+		Location:    s.device.Location,
+		Temperature: 20,
+		Humidity:    50,
+		Pressure:    1013,
+		WindSpeed:   10,
+		WindDir:     "N",
+		Timestamp:   timestamp,
+		Sequence:    s.sequence,
+	}
+}
+
+func (s *testStation) sign(t *testing.T, data WeatherData) SubmissionPayload {
+	t.Helper()
+	return signPayload(t, s.key, data, protocol.VersionFor(data))
+}
+
+// serve sends one request to handler mounted at route and decodes the JSON
+// response into out, if given.
+func serve(t *testing.T, handler gin.HandlerFunc, method, route, target string, body interface{}, out interface{}) int {
+	t.Helper()
+
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	router.Handle(method, route, handler)
+
+	request := httptest.NewRequest(method, target, bytes.NewReader(encoded))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if out != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: failed to decode response %q: %v", method, target, recorder.Body.String(), err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestBoltStoreSubmissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.db")
+	store, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, deviceID := range []string{"aa", "bb"} {
+		if err := store.SaveDevice(&DeviceRegistration{DeviceID: deviceID, IsActive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, deviceID := range []string{"aa", "bb", "aa"} {
+		record := &SubmissionRecord{
+			WeatherData: WeatherData{DeviceID: deviceID, Temperature: float64(i), Timestamp: now, Sequence: uint64(i + 1)},
+			DataHash:    hex.EncodeToString([]byte{byte(i)}),
+			IPFSHash:    "Qm" + deviceID,
+			ReceivedAt:  now.Add(time.Duration(i) * time.Second),
+		}
+		if err := store.AcceptSubmission(record); err != nil {
+			t.Fatalf("AcceptSubmission %d: %v", i, err)
+		}
+		if record.ID != uint64(i+1) {
+			t.Fatalf("submission %d got ID %d", i, record.ID)
+		}
+	}
+
+	// Everything must survive reopening the database.
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	all, err := store.ListSubmissions(SubmissionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].ID != 3 || all[2].ID != 1 {
+		t.Fatalf("ListSubmissions returned %d records, want IDs 3, 2, 1", len(all))
+	}
+
+	device, err := store.ListSubmissions(SubmissionFilter{DeviceID: "aa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(device) != 2 || device[0].ID != 3 || device[1].ID != 1 {
+		t.Errorf("device filter returned %+v, want submissions 3 and 1", device)
+	}
+
+	limited, err := store.ListSubmissions(SubmissionFilter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 || limited[0].ID != 3 {
+		t.Errorf("limit 1 returned %d records, want only submission 3", len(limited))
+	}
+
+	record, err := store.GetSubmission(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.DeviceID != "bb" || record.IPFSHash != "Qmbb" || !record.ReceivedAt.Equal(now.Add(time.Second)) {
+		t.Errorf("GetSubmission(2) = %+v", record)
+	}
+
+	if _, err := store.GetSubmission(4); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSubmission(4) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFindPreviousSubmission(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.db")
+	store, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Readings arrive out of order, as a backlog does after a live reading.
+	base := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{2 * time.Hour, 0, time.Hour} {
+		record := &SubmissionRecord{WeatherData: WeatherData{DeviceID: "aa", Timestamp: base.Add(offset)}}
+		if err := store.SaveSubmission(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SaveSubmission(&SubmissionRecord{WeatherData: WeatherData{DeviceID: "bb", Timestamp: base.Add(90 * time.Minute)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(store *BoltStore) {
+		t.Helper()
+		tests := []struct {
+			before time.Time
+			want   time.Duration
+		}{
+			{base.Add(3 * time.Hour), 2 * time.Hour},
+			{base.Add(2 * time.Hour), time.Hour},
+			{base.Add(90 * time.Minute), time.Hour},
+			{base.Add(time.Minute), 0},
+		}
+		for _, tt := range tests {
+			previous, err := store.FindPreviousSubmission("aa", tt.before)
+			if err != nil {
+				t.Errorf("FindPreviousSubmission before %v: %v", tt.before, err)
+				continue
+			}
+			if !previous.Timestamp.Equal(base.Add(tt.want)) {
+				t.Errorf("previous reading before %v is from %v, want %v", tt.before, previous.Timestamp, base.Add(tt.want))
+			}
+		}
+
+		if _, err := store.FindPreviousSubmission("aa", base); !errors.Is(err, ErrNotFound) {
+			t.Errorf("reading before the first returned error %v, want ErrNotFound", err)
+		}
+		if _, err := store.FindPreviousSubmission("cc", base.Add(time.Hour)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("reading of an unknown device returned error %v, want ErrNotFound", err)
+		}
+	}
+	check(store)
+
+	// A database from before the index gets it rebuilt when opened.
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(deviceTimelineBucket)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	check(store)
+}
+
+func TestGetWeatherData(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+	other := newTestStation(t, service)
+
+	for _, s := range []*testStation{station, other, station} {
+		payload := s.sign(t, s.reading(time.Now()))
+		if code := serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, nil); code != http.StatusOK {
+			t.Fatalf("submit returned %d", code)
+		}
+	}
+
+	var data struct {
+		Data  []SubmissionRecord `json:"data"`
+		Count int                `json:"count"`
+	}
+	target := "/api/data?device_id=" + station.device.DeviceID
+	if code := serve(t, service.GetWeatherData, http.MethodGet, "/api/data", target, nil, &data); code != http.StatusOK {
+		t.Fatalf("GET %s returned %d", target, code)
+	}
+	if data.Count != 2 || len(data.Data) != 2 {
+		t.Fatalf("GET %s returned %d records, want 2", target, data.Count)
+	}
+	for _, record := range data.Data {
+		if record.DeviceID != station.device.DeviceID || record.Signature == "" || record.IPFSHash == "" || record.ReceivedAt.IsZero() {
+			t.Errorf("record %+v is incomplete or from another device", record)
+		}
+	}
+	if data.Data[0].Sequence != 2 {
+		t.Errorf("newest record has sequence %d, want 2", data.Data[0].Sequence)
+	}
+
+	var latest struct {
+		Data []SubmissionRecord `json:"data"`
+	}
+	if code := serve(t, service.GetLatestData, http.MethodGet, "/api/data/latest", "/api/data/latest?limit=2", nil, &latest); code != http.StatusOK {
+		t.Fatalf("GET /api/data/latest returned %d", code)
+	}
+	if len(latest.Data) != 2 || latest.Data[0].DeviceID != station.device.DeviceID || latest.Data[1].DeviceID != other.device.DeviceID {
+		t.Errorf("latest data = %+v, want the two newest submissions", latest.Data)
+	}
+}
+
+func TestRegisterDevice(t *testing.T) {
+	service := newTestService(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyBytes := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+	publicKey := hex.EncodeToString(publicKeyBytes)
+	deviceID := deriveDeviceID(publicKeyBytes)
+	latitude, longitude := 51.5, -0.1
+
+	tests := []struct {
+		name         string
+		registration gin.H
+		want         int
+	}{
+		{"missing public key", gin.H{"device_id": deviceID}, http.StatusBadRequest},
+		{"invalid public key", gin.H{"device_id": deviceID, "public_key": "04abcd"}, http.StatusBadRequest},
+		{"device ID not derived from key", gin.H{"device_id": deriveDeviceID([]byte("another key")), "public_key": publicKey}, http.StatusBadRequest},
+		{"latitude without longitude", gin.H{"device_id": deviceID, "public_key": publicKey, "latitude": latitude}, http.StatusBadRequest},
+		{"registered", gin.H{"device_id": "0x" + strings.ToUpper(deviceID), "public_key": publicKey, "location": "Roof", "latitude": latitude, "longitude": longitude}, http.StatusOK},
+		{"already registered", gin.H{"device_id": deviceID, "public_key": publicKey}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := serve(t, service.RegisterDevice, http.MethodPost, "/api/register", "/api/register", tt.registration, nil); code != tt.want {
+				t.Errorf("register returned %d, want %d", code, tt.want)
+			}
+		})
+	}
+
+	device, err := service.Store.GetDevice(deviceID)
+	if err != nil {
+		t.Fatalf("device was not stored under its normalized ID: %v", err)
+	}
+	if !device.IsActive || device.Location != "Roof" || device.Latitude == nil || *device.Latitude != latitude || device.RegistrationTime.IsZero() {
+		t.Errorf("stored device = %+v", device)
+	}
+}
+
+func TestGetDevices(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+	newTestStation(t, service)
+
+	var list struct {
+		Devices []DeviceRegistration `json:"devices"`
+		Count   int                  `json:"count"`
+	}
+	if code := serve(t, service.GetDevices, http.MethodGet, "/api/devices", "/api/devices", nil, &list); code != http.StatusOK {
+		t.Fatalf("GET /api/devices returned %d", code)
+	}
+	if list.Count != 2 || len(list.Devices) != 2 {
+		t.Errorf("GET /api/devices returned %d devices, want 2", list.Count)
+	}
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{station.device.DeviceID, http.StatusOK},
+		{"0x" + strings.ToUpper(station.device.DeviceID), http.StatusOK},
+		{strings.Repeat("0", 32), http.StatusNotFound},
+		{"not-hex", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		var device DeviceRegistration
+		code := serve(t, service.GetDevice, http.MethodGet, "/api/devices/:id", "/api/devices/"+tt.id, nil, &device)
+		if code != tt.want {
+			t.Errorf("GET /api/devices/%s returned %d, want %d", tt.id, code, tt.want)
+		}
+		if code == http.StatusOK && device.DeviceID != station.device.DeviceID {
+			t.Errorf("GET /api/devices/%s returned device %s", tt.id, device.DeviceID)
+		}
+	}
+}
+
+func TestGetWeatherDataNormalizesDeviceID(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+
+	payload := station.sign(t, station.reading(time.Now()))
+	if code := serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, nil); code != http.StatusOK {
+		t.Fatalf("submit returned %d", code)
+	}
+
+	var data struct {
+		Count int `json:"count"`
+	}
+	target := "/api/data?device_id=0x" + strings.ToUpper(station.device.DeviceID)
+	if code := serve(t, service.GetWeatherData, http.MethodGet, "/api/data", target, nil, &data); code != http.StatusOK {
+		t.Fatalf("GET %s returned %d", target, code)
+	}
+	if data.Count != 1 {
+		t.Errorf("GET %s returned %d records, want 1", target, data.Count)
+	}
+
+	if code := serve(t, service.GetWeatherData, http.MethodGet, "/api/data", "/api/data?device_id=xyz", nil, nil); code != http.StatusBadRequest {
+		t.Errorf("GET /api/data?device_id=xyz returned %d, want 400", code)
+	}
+}
+
+func TestSubmitWeatherDataVerification(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+	inactive := newTestStation(t, service)
+	inactive.device.IsActive = false
+	if err := service.Store.SaveDevice(inactive.device); err != nil {
+		t.Fatal(err)
+	}
+
+	impostor, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		payload func() SubmissionPayload
+		want    int
+	}{
+		{"valid", func() SubmissionPayload { return station.sign(t, station.reading(time.Now())) }, http.StatusOK},
+		{"unknown device", func() SubmissionPayload {
+			data := station.reading(time.Now())
+			data.DeviceID = strings.Repeat("ab", 16)
+			return station.sign(t, data)
+		}, http.StatusForbidden},
+		{"inactive device", func() SubmissionPayload { return inactive.sign(t, inactive.reading(time.Now())) }, http.StatusForbidden},
+		{"payload key is not the registered key", func() SubmissionPayload {
+			payload := signPayload(t, impostor, station.reading(time.Now()), protocol.SigVersionV1)
+			payload.PublicKey = hex.EncodeToString(elliptic.Marshal(elliptic.P256(), impostor.X, impostor.Y))
+			return payload
+		}, http.StatusForbidden},
+		{"signed by another key", func() SubmissionPayload {
+			return signPayload(t, impostor, station.reading(time.Now()), protocol.SigVersionV1)
+		}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", tt.payload(), nil); code != tt.want {
+				t.Errorf("submit returned %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitRateLimit(t *testing.T) {
+	service := newTestService(t)
+	service.Config.MaxSubmissionsPerWindow = 2
+	station := newTestStation(t, service)
+	victim := newTestStation(t, service)
+
+	submit := func(payload SubmissionPayload) int {
+		return serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, nil)
+	}
+
+	// Unsigned readings in the victim's name do not use up its allowance.
+	for i := 0; i < 5; i++ {
+		junk := victim.reading(time.Now())
+		if code := submit(SubmissionPayload{WeatherData: junk, SigVersion: protocol.SigVersionV1}); code != http.StatusBadRequest {
+			t.Fatalf("unsigned submission returned %d, want 400", code)
+		}
+	}
+	if code := submit(victim.sign(t, victim.reading(time.Now()))); code != http.StatusOK {
+		t.Errorf("victim's signed submission returned %d, want 200", code)
+	}
+
+	// Spelling the device ID differently does not get a fresh allowance.
+	for i, deviceID := range []string{station.device.DeviceID, "0x" + strings.ToUpper(station.device.DeviceID), "0x" + station.device.DeviceID} {
+		data := station.reading(time.Now())
+		data.DeviceID = deviceID
+
+		want := http.StatusOK
+		if i >= service.Config.MaxSubmissionsPerWindow {
+			want = http.StatusTooManyRequests
+		}
+		if code := submit(station.sign(t, data)); code != want {
+			t.Errorf("submission %d as %s returned %d, want %d", i+1, deviceID, code, want)
+		}
+	}
+}
+
+func TestSubmitReplay(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+
+	submit := func(payload SubmissionPayload) (int, gin.H) {
+		var response gin.H
+		code := serve(t, service.SubmitWeatherData, http.MethodPost, "/api/submit", "/api/submit", payload, &response)
+		return code, response
+	}
+
+	first := station.sign(t, station.reading(time.Now()))
+	if code, response := submit(first); code != http.StatusOK {
+		t.Fatalf("first submission returned %d: %v", code, response)
+	}
+
+	// A captured payload sent again is rejected, as is an older reading signed
+	// with a sequence number the device already used.
+	if code, response := submit(first); code != http.StatusConflict || response["last_sequence"] != float64(1) {
+		t.Errorf("replayed submission returned %d: %v, want 409 with last_sequence 1", code, response)
+	}
+	stale := station.reading(time.Now())
+	stale.Sequence = 1
+	if code, _ := submit(station.sign(t, stale)); code != http.StatusConflict {
+		t.Errorf("submission reusing sequence 1 returned %d, want 409", code)
+	}
+
+	if code, response := submit(station.sign(t, station.reading(time.Now()))); code != http.StatusOK {
+		t.Errorf("next submission returned %d: %v", code, response)
+	}
+
+	device, err := service.Store.GetDevice(station.device.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.TotalSubmissions != 2 || device.LastSequence != station.sequence {
+		t.Errorf("device has %d submissions up to sequence %d, want 2 up to %d", device.TotalSubmissions, device.LastSequence, station.sequence)
+	}
+}
+
+func TestAcceptSubmission(t *testing.T) {
+	service := newTestService(t)
+	station := newTestStation(t, service)
+
+	accept := func(data WeatherData, dataHash string) error {
+		return service.Store.AcceptSubmission(&SubmissionRecord{WeatherData: data, DataHash: dataHash, ReceivedAt: time.Now()})
+	}
+
+	if err := accept(station.reading(time.Now()), "hash-1"); err != nil {
+		t.Fatalf("AcceptSubmission: %v", err)
+	}
+	if err := accept(station.reading(time.Now()), "hash-1"); !errors.Is(err, ErrDuplicateSubmission) {
+		t.Errorf("accepting a known data hash returned %v, want ErrDuplicateSubmission", err)
+	}
+	stale := station.reading(time.Now())
+	stale.Sequence = 1
+	if err := accept(stale, "hash-2"); !errors.Is(err, ErrStaleSequence) {
+		t.Errorf("accepting a used sequence returned %v, want ErrStaleSequence", err)
+	}
+}
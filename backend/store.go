@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrDuplicateSubmission = errors.New("duplicate submission")
+	ErrStaleSequence       = errors.New("sequence number is not greater than the last accepted one")
+)
+
+type SubmissionRecord struct {
+	ID uint64 `json:"id"`
+	WeatherData
+	DataHash   string    `json:"data_hash"`
+	Signature  string    `json:"signature"`
+	PublicKey  string    `json:"public_key"`
+	IPFSHash   string    `json:"ipfs_hash"`
+	ReceivedAt time.Time `json:"received_at"`
+	QC         *QCResult `json:"qc,omitempty"`
+	Anomalies  []Anomaly `json:"anomalies,omitempty"`
+
+	BatchID        uint64 `json:"batch_id,omitempty"`
+	EntryID        string `json:"entry_id,omitempty"`
+	TxHash         string `json:"tx_hash,omitempty"`
+	RegistryTxHash string `json:"registry_tx_hash,omitempty"`
+}
+
+// SubmissionFilter selects submissions newest first. ReceivedSince is compared
+// with when a submission arrived, not when its reading was taken; use
+// ListDeviceSubmissionsBetween to select by reading time.
+type SubmissionFilter struct {
+	DeviceID       string
+	ReceivedSince  time.Time
+	Limit          int
+	ExcludeFlagged bool
+	MinReputation  float64
+}
+
+type SubmissionRepository interface {
+	SaveSubmission(record *SubmissionRecord) error
+	AcceptSubmission(record *SubmissionRecord) error
+	GetSubmission(id uint64) (*SubmissionRecord, error)
+	ListSubmissions(filter SubmissionFilter) ([]SubmissionRecord, error)
+	FindSubmissionByHash(dataHash string) (*SubmissionRecord, error)
+	FindPreviousSubmission(deviceID string, before time.Time) (*SubmissionRecord, error)
+	ListDeviceSubmissionsBetween(deviceID string, from, to time.Time) ([]SubmissionRecord, error)
+}
+
+type DeviceRepository interface {
+	SaveDevice(device *DeviceRegistration) error
+	GetDevice(deviceID string) (*DeviceRegistration, error)
+	ListDevices() ([]DeviceRegistration, error)
+	UpdateDevice(deviceID string, update func(device *DeviceRegistration) error) error
+}
+
+type TxJobRepository interface {
+	SaveTxJob(job *TxJob) error
+	GetTxJob(id uint64) (*TxJob, error)
+	ListPendingTxJobs() ([]TxJob, error)
+	ListSubmissionTxJobs(submissionID uint64) ([]TxJob, error)
+}
+
+type AnchorRepository interface {
+	AddPendingAnchor(anchor PendingAnchor) error
+	ListPendingAnchors() ([]PendingAnchor, error)
+	CreateAnchorBatch(batch *AnchorBatch, jobs []*TxJob) error
+	SaveAnchorBatch(batch *AnchorBatch) error
+	GetAnchorBatch(id uint64) (*AnchorBatch, error)
+	FindAnchorBatch(dataHash string) (*AnchorBatch, error)
+}
+
+type RewardRepository interface {
+	SaveReward(reward *RewardRecord) error
+	GetReward(id uint64) (*RewardRecord, error)
+	FindReward(deviceID string, period int64) (*RewardRecord, error)
+	ListDeviceRewards(deviceID string) ([]RewardRecord, error)
+}
+
+type ReputationRepository interface {
+	SaveReputation(reputation *Reputation) error
+	ListReputationHistory(deviceID string, limit int) ([]Reputation, error)
+}
+
+type IndexerRepository interface {
+	GetIndexerCheckpoint() (*IndexerCheckpoint, error)
+	SaveIndexerCheckpoint(checkpoint *IndexerCheckpoint) error
+}
+
+type Store interface {
+	SubmissionRepository
+	DeviceRepository
+	TxJobRepository
+	AnchorRepository
+	RewardRepository
+	ReputationRepository
+	IndexerRepository
+	Close() error
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type TxStatus string
+
+const (
+	TxQueued    TxStatus = "queued"
+	TxSent      TxStatus = "sent"
+	TxConfirmed TxStatus = "confirmed"
+	TxFailed    TxStatus = "failed"
+)
+
+type TxJob struct {
+	ID           uint64         `json:"id"`
+	Kind         string         `json:"kind"`
+	SubmissionID uint64         `json:"submission_id,omitempty"`
+	BatchID      uint64         `json:"batch_id,omitempty"`
+	RewardID     uint64         `json:"reward_id,omitempty"`
+	To           common.Address `json:"to"`
+	Data         hexutil.Bytes  `json:"data"`
+
+	Status         TxStatus  `json:"status"`
+	Nonce          *uint64   `json:"nonce,omitempty"`
+	GasLimit       uint64    `json:"gas_limit,omitempty"`
+	GasPrice       *big.Int  `json:"gas_price,omitempty"`
+	TxHashes       []string  `json:"tx_hashes,omitempty"`
+	CancelTxHashes []string  `json:"cancel_tx_hashes,omitempty"`
+	MinedTxHash    string    `json:"mined_tx_hash,omitempty"`
+	Attempts       int       `json:"attempts"`
+	Cancelling     bool      `json:"cancelling,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	BlockNumber    uint64    `json:"block_number,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	SentAt         time.Time `json:"sent_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (j *TxJob) TxHash() string {
+	if j.MinedTxHash != "" {
+		return j.MinedTxHash
+	}
+	if len(j.TxHashes) == 0 {
+		return ""
+	}
+	return j.TxHashes[len(j.TxHashes)-1]
+}
+
+func (j *TxJob) Done() bool {
+	return j.Status == TxConfirmed || j.Status == TxFailed
+}
+
+type TxQueue struct {
+	backend        ChainBackend
+	auth           *bind.TransactOpts
+	store          TxJobRepository
+	pollInterval   time.Duration
+	stuckTimeout   time.Duration
+	gasBumpPercent int64
+	maxAttempts    int
+
+	nextNonce   uint64
+	nonceLoaded bool
+	onConfirmed []func(job *TxJob, receipt *types.Receipt)
+	wake        chan struct{}
+	mu          sync.Mutex
+}
+
+func NewTxQueue(backend ChainBackend, auth *bind.TransactOpts, store TxJobRepository, config *Config) *TxQueue {
+	return &TxQueue{
+		backend:        backend,
+		auth:           auth,
+		store:          store,
+		pollInterval:   time.Duration(config.TxPollInterval) * time.Second,
+		stuckTimeout:   time.Duration(config.TxStuckTimeout) * time.Second,
+		gasBumpPercent: int64(config.TxGasBumpPercent),
+		maxAttempts:    config.TxMaxAttempts,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+func (q *TxQueue) OnConfirmed(fn func(job *TxJob, receipt *types.Receipt)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onConfirmed = append(q.onConfirmed, fn)
+}
+
+func (q *TxQueue) Enqueue(jobs ...*TxJob) error {
+	q.Prepare(jobs...)
+	for _, job := range jobs {
+		if err := q.store.SaveTxJob(job); err != nil {
+			return fmt.Errorf("failed to persist %s transaction: %v", job.Kind, err)
+		}
+	}
+
+	q.Wake()
+	return nil
+}
+
+// Prepare marks jobs as queued without persisting them, for callers that save
+// them in the same store transaction as related state. Wake must be called
+// once they are saved.
+func (q *TxQueue) Prepare(jobs ...*TxJob) {
+	now := time.Now()
+	for _, job := range jobs {
+		job.Status = TxQueued
+		job.CreatedAt = now
+		job.UpdatedAt = now
+	}
+}
+
+func (q *TxQueue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *TxQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *TxQueue) process(ctx context.Context) {
+	jobs, err := q.store.ListPendingTxJobs()
+	if err != nil {
+		log.Printf("Failed to load pending transactions: %v", err)
+		return
+	}
+
+	if !q.nonceLoaded {
+		if err := q.loadNonce(ctx, jobs); err != nil {
+			log.Printf("Failed to load account nonce: %v", err)
+			return
+		}
+	}
+
+	for _, job := range jobs {
+		var err error
+		switch job.Status {
+		case TxQueued:
+			if !q.nonceLoaded {
+				return
+			}
+			err = q.send(ctx, job)
+		case TxSent:
+			err = q.track(ctx, job)
+		}
+
+		if err != nil {
+			log.Printf("Transaction job %d (%s): %v", job.ID, job.Kind, err)
+			if job.Status == TxQueued {
+				return
+			}
+		}
+	}
+}
+
+func (q *TxQueue) loadNonce(ctx context.Context, jobs []TxJob) error {
+	nonce, err := q.backend.PendingNonceAt(ctx, q.auth.From)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.Nonce != nil && *job.Nonce >= nonce {
+			nonce = *job.Nonce + 1
+		}
+	}
+
+	q.nextNonce = nonce
+	q.nonceLoaded = true
+	return nil
+}
+
+func (q *TxQueue) send(ctx context.Context, job TxJob) error {
+	gasLimit, err := q.backend.EstimateGas(ctx, ethereum.CallMsg{From: q.auth.From, To: &job.To, Data: job.Data})
+	if err != nil && isExecutionReverted(err) {
+		job.Status = TxFailed
+		job.LastError = fmt.Sprintf("gas estimation failed: %v", err)
+		return q.save(&job)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %v", err)
+	}
+
+	gasPrice, err := q.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas price: %v", err)
+	}
+
+	nonce := q.nextNonce
+	job.Nonce = &nonce
+	job.GasLimit = gasLimit * 120 / 100
+	job.GasPrice = gasPrice
+
+	tx, err := q.sign(&job)
+	if err != nil {
+		return err
+	}
+
+	q.nextNonce++
+	err = q.broadcast(ctx, &job, tx)
+
+	// A node that answers with an error has refused the transaction, so the
+	// nonce is still free and the job can go out again on the next pass. A
+	// transport error may hide a transaction that did get through, so that
+	// job stays sent and is rebroadcast after the stuck timeout.
+	var rejected rpc.Error
+	if err != nil && job.Status == TxSent && errors.As(err, &rejected) {
+		q.nextNonce = nonce
+		job.Status = TxQueued
+		job.Nonce = nil
+		if saveErr := q.save(&job); saveErr != nil {
+			return saveErr
+		}
+	}
+	return err
+}
+
+func (q *TxQueue) track(ctx context.Context, job TxJob) error {
+	for i := len(job.TxHashes) - 1; i >= 0; i-- {
+		receipt, err := q.backend.TransactionReceipt(ctx, common.HexToHash(job.TxHashes[i]))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch receipt: %v", err)
+		}
+		return q.finalize(&job, receipt)
+	}
+
+	if time.Since(job.SentAt) < q.stuckTimeout {
+		return nil
+	}
+
+	if job.Attempts >= q.maxAttempts && !job.Cancelling {
+		job.Cancelling = true
+		job.LastError = fmt.Sprintf("not mined after %d attempts, cancelling nonce %d", job.Attempts, *job.Nonce)
+	}
+
+	// Cancellations get the same number of attempts. After that the job is
+	// given up on rather than bumped without limit; its nonce stays pending
+	// until the node drops the transactions or they are replaced by hand.
+	if job.Cancelling && len(job.CancelTxHashes) >= q.maxAttempts {
+		job.Status = TxFailed
+		job.LastError = fmt.Sprintf("cancellation of nonce %d not mined after %d attempts", *job.Nonce, len(job.CancelTxHashes))
+		return q.save(&job)
+	}
+
+	gasPrice, err := q.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas price: %v", err)
+	}
+
+	bumped := new(big.Int).Mul(job.GasPrice, big.NewInt(100+q.gasBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(gasPrice) < 0 {
+		bumped = gasPrice
+	}
+	job.GasPrice = bumped
+
+	tx, err := q.sign(&job)
+	if err != nil {
+		return err
+	}
+
+	return q.broadcast(ctx, &job, tx)
+}
+
+func (q *TxQueue) sign(job *TxJob) (*types.Transaction, error) {
+	to, data, gasLimit := job.To, []byte(job.Data), job.GasLimit
+	if job.Cancelling {
+		to, data, gasLimit = q.auth.From, nil, 21000
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    *job.Nonce,
+		To:       &to,
+		Gas:      gasLimit,
+		GasPrice: job.GasPrice,
+		Data:     data,
+	})
+
+	signed, err := q.auth.Signer(q.auth.From, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signed, nil
+}
+
+func (q *TxQueue) broadcast(ctx context.Context, job *TxJob, tx *types.Transaction) error {
+	job.Status = TxSent
+	job.TxHashes = append(job.TxHashes, tx.Hash().Hex())
+	if job.Cancelling {
+		job.CancelTxHashes = append(job.CancelTxHashes, tx.Hash().Hex())
+	}
+	job.Attempts++
+	job.SentAt = time.Now()
+	if err := q.save(job); err != nil {
+		return err
+	}
+
+	err := q.backend.SendTransaction(ctx, tx)
+	if err != nil && strings.Contains(err.Error(), "nonce too low") {
+		q.nonceLoaded = false
+		job.Status = TxQueued
+		job.Nonce = nil
+		job.Cancelling = false
+		job.LastError = err.Error()
+		if saveErr := q.save(job); saveErr != nil {
+			return saveErr
+		}
+		return fmt.Errorf("nonce %d already used, requeueing: %v", tx.Nonce(), err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "already known") {
+		job.LastError = err.Error()
+		if saveErr := q.save(job); saveErr != nil {
+			return saveErr
+		}
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return nil
+}
+
+func (q *TxQueue) finalize(job *TxJob, receipt *types.Receipt) error {
+	job.BlockNumber = receipt.BlockNumber.Uint64()
+	job.MinedTxHash = receipt.TxHash.Hex()
+
+	switch {
+	case slices.Contains(job.CancelTxHashes, job.MinedTxHash):
+		job.Status = TxFailed
+	case receipt.Status != types.ReceiptStatusSuccessful:
+		job.Status = TxFailed
+		job.LastError = "transaction reverted"
+	default:
+		job.Status = TxConfirmed
+		job.LastError = ""
+	}
+
+	if err := q.save(job); err != nil {
+		return err
+	}
+
+	if job.Status == TxConfirmed {
+		q.mu.Lock()
+		hooks := q.onConfirmed
+		q.mu.Unlock()
+
+		for _, hook := range hooks {
+			hook(job, receipt)
+		}
+	}
+	return nil
+}
+
+// rpcCodeExecutionReverted is the JSON-RPC error code nodes return when a
+// call reverts.
+const rpcCodeExecutionReverted = 3
+
+// isExecutionReverted reports whether an error means the call itself reverts,
+// so sending it can never succeed. Other errors, such as timeouts and rate
+// limits, are worth retrying.
+func isExecutionReverted(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcCodeExecutionReverted
+}
+
+func (q *TxQueue) save(job *TxJob) error {
+	job.UpdatedAt = time.Now()
+	if err := q.store.SaveTxJob(job); err != nil {
+		return fmt.Errorf("failed to persist transaction state: %v", err)
+	}
+	return nil
+}
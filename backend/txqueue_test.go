@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// sendHook is a chain backend whose SendTransaction can be replaced.
+type sendHook struct {
+	ChainBackend
+	send func(ctx context.Context, tx *types.Transaction) error
+}
+
+func (b *sendHook) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.send(ctx, tx)
+}
+
+// rpcError is an error answered by a node, as the RPC client returns it.
+type rpcError struct {
+	code    int
+	message string
+}
+
+func (e rpcError) Error() string  { return e.message }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIsExecutionReverted(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"revert", rpcError{3, "execution reverted: Device not active"}, true},
+		{"wrapped revert", fmt.Errorf("estimate: %w", rpcError{3, "execution reverted"}), true},
+		{"other node error", rpcError{-32000, "header not found"}, false},
+		{"revert text without a code", errors.New("execution reverted"), false},
+		{"timeout", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		if got := isExecutionReverted(tt.err); got != tt.want {
+			t.Errorf("%s: isExecutionReverted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTxQueueSendErrors(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	store := newTestTxStore(t)
+
+	var sendErr error
+	backend := &sendHook{ChainBackend: chain.sim.Client(), send: func(ctx context.Context, tx *types.Transaction) error {
+		if sendErr != nil {
+			return sendErr
+		}
+		return chain.sim.Client().SendTransaction(ctx, tx)
+	}}
+	queue := chain.newQueue(backend, store, 5)
+
+	job, err := chain.client.RecordSubmissionJob(1, chain.registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Enqueue(job); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := chain.sim.Client().PendingNonceAt(ctx, chain.auth.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A node refusing the transaction puts the job straight back in the queue
+	// and frees its nonce.
+	sendErr = rpcError{-32000, "insufficient funds for gas * price + value"}
+	queue.process(ctx)
+
+	stored, err := store.GetTxJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != TxQueued || stored.Nonce != nil || !strings.Contains(stored.LastError, "insufficient funds") {
+		t.Fatalf("after a rejected send the job is %s with nonce %v (%q), want queued without a nonce", stored.Status, stored.Nonce, stored.LastError)
+	}
+
+	// A transport error may hide a transaction that got through, so the job
+	// keeps its nonce and waits for the stuck timeout.
+	sendErr = errors.New("connection reset by peer")
+	queue.process(ctx)
+
+	stored, err = store.GetTxJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != TxSent || stored.Nonce == nil || *stored.Nonce != nonce {
+		t.Fatalf("after a transport error the job is %s with nonce %v, want sent with nonce %d", stored.Status, stored.Nonce, nonce)
+	}
+
+	// The stuck timeout rebroadcasts it with the same nonce.
+	sendErr = nil
+	queue.stuckTimeout = 0
+	queue.process(ctx)
+	chain.sim.Commit()
+	queue.process(ctx)
+
+	stored, err = store.GetTxJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != TxConfirmed || *stored.Nonce != nonce {
+		t.Errorf("job is %s with nonce %d (%q), want confirmed with nonce %d", stored.Status, *stored.Nonce, stored.LastError, nonce)
+	}
+}
+
+func TestTxQueueGivesUpCancelling(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	store := newTestTxStore(t)
+
+	// The node accepts every transaction and never mines any of them.
+	backend := &sendHook{ChainBackend: chain.sim.Client(), send: func(context.Context, *types.Transaction) error {
+		return nil
+	}}
+	queue := chain.newQueue(backend, store, 2)
+	queue.stuckTimeout = 0
+
+	job, err := chain.client.RecordSubmissionJob(1, chain.registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Enqueue(job); err != nil {
+		t.Fatal(err)
+	}
+
+	queue.process(ctx)
+	first, err := store.GetTxJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		queue.process(ctx)
+	}
+
+	stored, err := store.GetTxJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != TxFailed || !stored.Cancelling {
+		t.Fatalf("job is %s (%q), want failed while cancelling", stored.Status, stored.LastError)
+	}
+	if stored.Attempts != 4 || len(stored.CancelTxHashes) != 2 {
+		t.Errorf("job made %d attempts, %d of them cancellations, want 4 and 2", stored.Attempts, len(stored.CancelTxHashes))
+	}
+
+	// Every rebroadcast bumps the gas price by TX_GAS_BUMP_PERCENT.
+	want := new(big.Int).Set(first.GasPrice)
+	for i := 0; i < 3; i++ {
+		want.Mul(want, big.NewInt(120)).Div(want, big.NewInt(100))
+	}
+	if stored.GasPrice.Cmp(want) != 0 {
+		t.Errorf("final gas price = %v, want %v", stored.GasPrice, want)
+	}
+
+	pending, err := store.ListPendingTxJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d jobs still pending", len(pending))
+	}
+}
+
+func TestTxQueueResumesAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	store := newTestTxStore(t)
+
+	first, err := chain.client.RecordSubmissionJob(1, chain.registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := chain.newQueue(nil, store, 5)
+	if err := before.Enqueue(first); err != nil {
+		t.Fatal(err)
+	}
+	before.process(ctx)
+
+	// A new queue over the same store tracks the sent job and gives the next
+	// one a fresh nonce.
+	after := chain.newQueue(nil, store, 5)
+	second, err := chain.client.RecordSubmissionJob(2, chain.registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := after.Enqueue(second); err != nil {
+		t.Fatal(err)
+	}
+	after.process(ctx)
+	chain.sim.Commit()
+	after.process(ctx)
+
+	var nonces []uint64
+	for _, job := range []*TxJob{first, second} {
+		stored, err := store.GetTxJob(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != TxConfirmed {
+			t.Errorf("job %d is %s (%q), want confirmed", stored.ID, stored.Status, stored.LastError)
+			continue
+		}
+		nonces = append(nonces, *stored.Nonce)
+	}
+	if len(nonces) == 2 && nonces[1] != nonces[0]+1 {
+		t.Errorf("nonces = %v, want consecutive", nonces)
+	}
+}
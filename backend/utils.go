@@ -7,23 +7,36 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math/big"
 	"math/rand"
 	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
+
+	"weather-protocol"
 )
 
 func (s *WeatherService) checkRateLimit(deviceID string) bool {
+	return s.allowSubmission(s.submissionCounts, deviceID, s.Config.MaxSubmissionsPerWindow)
+}
+
+// checkBackfillLimit counts historical readings separately from live ones so a
+// device draining a backlog does not starve its live submissions.
+func (s *WeatherService) checkBackfillLimit(deviceID string) bool {
+	return s.allowSubmission(s.backfillCounts, deviceID, s.Config.BackfillMaxPerWindow)
+}
+
+func (s *WeatherService) allowSubmission(counts map[string][]time.Time, deviceID string, limit int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now()
 	windowStart := now.Add(-time.Duration(s.Config.RateLimitWindow) * time.Second)
 
-	submissions := s.submissionCounts[deviceID]
+	submissions := counts[deviceID]
 	validSubmissions := make([]time.Time, 0)
 
 	for _, submission := range submissions {
@@ -32,95 +45,159 @@ func (s *WeatherService) checkRateLimit(deviceID string) bool {
 		}
 	}
 
-	if len(validSubmissions) >= s.Config.MaxSubmissionsPerWindow {
+	if len(validSubmissions) >= limit {
 		return false
 	}
 
 	validSubmissions = append(validSubmissions, now)
-	s.submissionCounts[deviceID] = validSubmissions
+	counts[deviceID] = validSubmissions
 
 	return true
 }
 
-func (s *WeatherService) verifySignature(payload SubmissionPayload) bool {
-	publicKeyBytes, err := hex.DecodeString(payload.PublicKey)
-	if err != nil {
-		return false
+func normalizeDeviceID(deviceID string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(deviceID, "0x"))
+	if normalized == "" {
+		return "", fmt.Errorf("device ID is empty")
+	}
+	if _, err := hex.DecodeString(normalized); err != nil {
+		return "", fmt.Errorf("device ID is not valid hex: %v", err)
 	}
+	return normalized, nil
+}
 
-	x, y := elliptic.Unmarshal(elliptic.P256(), publicKeyBytes)
-	if x == nil || y == nil {
-		return false
+func deviceIDToBytes32(deviceID string) ([32]byte, error) {
+	var out [32]byte
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(deviceID, "0x"))
+	if err != nil {
+		return out, fmt.Errorf("device ID is not valid hex: %v", err)
+	}
+	if len(raw) > 32 {
+		return out, fmt.Errorf("device ID is longer than 32 bytes")
 	}
 
-	publicKey := &ecdsa.PublicKey{
-		Curve: elliptic.P256(),
-		X:     x,
-		Y:     y,
+	copy(out[:], raw)
+	return out, nil
+}
+
+func bytes32ToDeviceID(deviceID [32]byte) string {
+	if [16]byte(deviceID[16:]) == [16]byte{} {
+		return hex.EncodeToString(deviceID[:16])
 	}
+	return hex.EncodeToString(deviceID[:])
+}
+
+func hexToBytes32(value string) ([32]byte, error) {
+	var out [32]byte
 
-	dataBytes, err := json.Marshal(payload.WeatherData)
+	raw, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
 	if err != nil {
-		return false
+		return out, fmt.Errorf("value is not valid hex: %v", err)
+	}
+	if len(raw) != 32 {
+		return out, fmt.Errorf("expected 32 bytes, got %d", len(raw))
 	}
 
-	dataHash := sha256.Sum256(dataBytes)
-	expectedHash := hex.EncodeToString(dataHash[:])
+	copy(out[:], raw)
+	return out, nil
+}
 
-	if expectedHash != payload.DataHash {
-		return false
-	}
+var (
+	errUnknownDevice     = errors.New("device is not registered")
+	errDeviceInactive    = errors.New("device is not active")
+	errDeviceIDMismatch  = errors.New("device ID is not derived from the registered public key")
+	errPublicKeyMismatch = errors.New("public key does not match the registered device key")
+	errInvalidSignature  = errors.New("invalid signature")
+)
 
-	signatureBytes, err := hex.DecodeString(payload.Signature)
+func parsePublicKey(publicKeyHex string) (*ecdsa.PublicKey, []byte, error) {
+	publicKeyBytes, err := hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
 	if err != nil {
-		return false
+		return nil, nil, fmt.Errorf("public key is not valid hex: %v", err)
 	}
 
-	if len(signatureBytes) != 64 {
-		return false
+	x, y := elliptic.Unmarshal(elliptic.P256(), publicKeyBytes)
+	if x == nil || y == nil {
+		return nil, nil, fmt.Errorf("public key is not an uncompressed P-256 point")
 	}
 
-	r := new(big.Int).SetBytes(signatureBytes[:32])
-	sigS := new(big.Int).SetBytes(signatureBytes[32:])
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, publicKeyBytes, nil
+}
 
-	return ecdsa.Verify(publicKey, dataHash[:], r, sigS)
+func deriveDeviceID(publicKeyBytes []byte) string {
+	hash := sha256.Sum256(publicKeyBytes)
+	return hex.EncodeToString(hash[:16])
 }
 
-func (s *WeatherService) validateWeatherData(data WeatherData) bool {
-	if data.Temperature < -100 || data.Temperature > 70 {
-		return false
+func (s *WeatherService) verifySignature(payload SubmissionPayload) (*DeviceRegistration, error) {
+	deviceID, err := normalizeDeviceID(payload.WeatherData.DeviceID)
+	if err != nil {
+		return nil, errUnknownDevice
 	}
 
-	if data.Humidity < 0 || data.Humidity > 100 {
-		return false
+	device, err := s.Store.GetDevice(deviceID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errUnknownDevice
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if data.Pressure < 800 || data.Pressure > 1200 {
-		return false
+	if !device.IsActive {
+		return nil, errDeviceInactive
 	}
 
-	if data.WindSpeed < 0 || data.WindSpeed > 200 {
-		return false
+	publicKey, _, err := parsePublicKey(device.PublicKey)
+	if err != nil {
+		return nil, errDeviceIDMismatch
 	}
 
-	validDirections := map[string]bool{
-		"N": true, "NE": true, "E": true, "SE": true,
-		"S": true, "SW": true, "W": true, "NW": true,
+	_, originalKeyBytes, err := parsePublicKey(device.originalPublicKey())
+	if err != nil || deriveDeviceID(originalKeyBytes) != deviceID {
+		return nil, errDeviceIDMismatch
 	}
 
-	if !validDirections[data.WindDir] {
-		return false
+	if payload.PublicKey != "" && !strings.EqualFold(strings.TrimPrefix(payload.PublicKey, "0x"), device.PublicKey) {
+		return nil, errPublicKeyMismatch
 	}
 
-	timeDiff := time.Since(data.Timestamp)
-	if timeDiff > time.Hour || timeDiff < -time.Minute*5 {
-		return false
+	dataHash, err := protocol.Digest(payload.WeatherData, payload.SigVersion)
+	if errors.Is(err, protocol.ErrUnsupportedVersion) || errors.Is(err, protocol.ErrOptionalFieldsV1) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errInvalidSignature
 	}
 
-	return true
+	if hex.EncodeToString(dataHash[:]) != payload.DataHash {
+		return nil, errInvalidSignature
+	}
+
+	if !verifyDigest(publicKey, dataHash, payload.Signature) {
+		return nil, errInvalidSignature
+	}
+
+	return device, nil
+}
+
+func validateCoordinates(latitude, longitude, elevation *float64) error {
+	if (latitude == nil) != (longitude == nil) {
+		return fmt.Errorf("latitude and longitude must be given together")
+	}
+	if latitude != nil && (*latitude < -90 || *latitude > 90) {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if longitude != nil && (*longitude < -180 || *longitude > 180) {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	if elevation != nil && (*elevation < -500 || *elevation > 9000) {
+		return fmt.Errorf("elevation must be between -500 and 9000 metres")
+	}
+	return nil
 }
 
-func (s *WeatherService) uploadToPinata(data WeatherData) (string, error) {
+func (s *WeatherService) uploadToPinata(filename string, data interface{}) (string, error) {
 	if s.Config.PinataAPIKey == "" {
 		return s.generateMockIPFSHash(), nil
 	}
@@ -133,7 +210,7 @@ func (s *WeatherService) uploadToPinata(data WeatherData) (string, error) {
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
-	fileWriter, err := writer.CreateFormFile("file", "weather_data.json")
+	fileWriter, err := writer.CreateFormFile("file", filename)
 	if err != nil {
 		return "", err
 	}
@@ -184,25 +261,3 @@ func (s *WeatherService) generateMockIPFSHash() string {
 	rand.Read(hash)
 	return "Qm" + hex.EncodeToString(hash)[:44]
 }
-
-func (s *WeatherService) generateMockHistoricalData(count int) []map[string]interface{} {
-	data := make([]map[string]interface{}, count)
-	locations := []string{"New York, NY", "London, UK", "Tokyo, Japan", "Sydney, Australia", "Berlin, Germany"}
-	devices := []string{"0x1a2b3c4d5e6f", "0x9a8b7c6d5e4f", "0x3f2e1d0c9b8a", "0x7e6d5c4b3a29"}
-
-	for i := 0; i < count; i++ {
-		data[i] = map[string]interface{}{
-			"device_id":      devices[rand.Intn(len(devices))],
-			"location":       locations[rand.Intn(len(locations))],
-			"temperature":    15.0 + rand.Float64()*20.0,
-			"humidity":       30.0 + rand.Float64()*40.0,
-			"pressure":       980.0 + rand.Float64()*40.0,
-			"wind_speed":     rand.Float64() * 30.0,
-			"wind_direction": []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}[rand.Intn(8)],
-			"timestamp":      time.Now().Add(-time.Duration(i*5) * time.Minute),
-			"ipfs_hash":      s.generateMockIPFSHash(),
-		}
-	}
-
-	return data
-}
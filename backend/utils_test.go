@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-protocol"
+)
+
+// The golden vectors are shared with the protocol module and the client.
+const goldenVectorsPath = "../protocol/testdata/golden.json"
+
+type goldenVector struct {
+	Name        string          `json:"name"`
+	SigVersion  int             `json:"sig_version"`
+	WeatherData json.RawMessage `json:"weather_data"`
+	Digest      string          `json:"digest"`
+}
+
+func loadGoldenVectors(t *testing.T) []goldenVector {
+	t.Helper()
+
+	raw, err := os.ReadFile(goldenVectorsPath)
+	if err != nil {
+		t.Fatalf("failed to read golden vectors: %v", err)
+	}
+
+	var vectors []goldenVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatalf("failed to parse golden vectors: %v", err)
+	}
+	return vectors
+}
+
+// signPayload signs data the way a station does.
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, data WeatherData, version int) SubmissionPayload {
+	t.Helper()
+
+	digest, err := protocol.Digest(data, version)
+	if err != nil {
+		t.Fatalf("Digest: %v", err)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return SubmissionPayload{
+		WeatherData: data,
+		DataHash:    hex.EncodeToString(digest[:]),
+		Signature:   hex.EncodeToString(protocol.EncodeSignature(r, s)),
+		SigVersion:  version,
+	}
+}
+
+func TestGoldenVectorsVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vector := range loadGoldenVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			// Decode the reading as the submit handler does.
+			var payload SubmissionPayload
+			body := fmt.Sprintf(`{"weather_data":%s,"sig_version":%d}`, vector.WeatherData, vector.SigVersion)
+			if err := json.Unmarshal([]byte(body), &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+
+			digest, err := protocol.Digest(payload.WeatherData, payload.SigVersion)
+			if err != nil {
+				t.Fatalf("Digest: %v", err)
+			}
+			if got := hex.EncodeToString(digest[:]); got != vector.Digest {
+				t.Fatalf("Digest = %s, want %s", got, vector.Digest)
+			}
+
+			signed := signPayload(t, key, payload.WeatherData, payload.SigVersion)
+			if signed.DataHash != vector.Digest {
+				t.Errorf("signed data_hash = %s, want %s", signed.DataHash, vector.Digest)
+			}
+			if !verifyDigest(&key.PublicKey, digest, signed.Signature) {
+				t.Error("signature over the golden digest does not verify")
+			}
+
+			digest[0] ^= 1
+			if verifyDigest(&key.PublicKey, digest, signed.Signature) {
+				t.Error("signature verifies against a different digest")
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	store, err := NewBoltStore(filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	service := &WeatherService{Store: store}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+	device := &DeviceRegistration{
+		DeviceID:         deriveDeviceID(publicKey),
+		PublicKey:        hex.EncodeToString(publicKey),
+		RegistrationTime: time.Now(),
+		IsActive:         true,
+	}
+	if err := store.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vector := range loadGoldenVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			var data WeatherData
+			if err := json.Unmarshal(vector.WeatherData, &data); err != nil {
+				t.Fatal(err)
+			}
+			data.DeviceID = device.DeviceID
+
+			payload := signPayload(t, key, data, vector.SigVersion)
+			verified, err := service.verifySignature(payload)
+			if err != nil {
+				t.Fatalf("verifySignature: %v", err)
+			}
+			if verified.DeviceID != device.DeviceID {
+				t.Errorf("verified device %s, want %s", verified.DeviceID, device.DeviceID)
+			}
+
+			// The device ID is matched case-insensitively and with a 0x prefix.
+			data.DeviceID = "0x" + strings.ToUpper(device.DeviceID)
+			if _, err := service.verifySignature(signPayload(t, key, data, vector.SigVersion)); err != nil {
+				t.Errorf("verifySignature with a 0x upper-case device ID: %v", err)
+			}
+
+			tampered := payload
+			tampered.WeatherData.Temperature++
+			if _, err := service.verifySignature(tampered); !errors.Is(err, errInvalidSignature) {
+				t.Errorf("tampered reading error = %v, want errInvalidSignature", err)
+			}
+
+			wrongVersion := payload
+			wrongVersion.SigVersion = 0
+			if _, err := service.verifySignature(wrongVersion); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+				t.Errorf("sig_version 0 error = %v, want ErrUnsupportedVersion", err)
+			}
+		})
+	}
+
+	t.Run("unknown device", func(t *testing.T) {
+		var data WeatherData
+		if err := json.Unmarshal(loadGoldenVectors(t)[0].WeatherData, &data); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.verifySignature(signPayload(t, key, data, protocol.SigVersionV1)); !errors.Is(err, errUnknownDevice) {
+			t.Errorf("error = %v, want errUnknownDevice", err)
+		}
+	})
+}
@@ -3,13 +3,18 @@ package main
 import (
 	"bytes"
 	"crypto/ecdsa"
-	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
+
+	"weather-protocol"
 )
 
 type WeatherClient struct {
@@ -17,91 +22,176 @@ type WeatherClient struct {
 	PrivateKey *ecdsa.PrivateKey
 	PublicKey  *ecdsa.PublicKey
 	DeviceID   []byte
+	Sensors    []Sensor
+	Outbox     *Outbox
+	HTTP       *http.Client
+
+	retryFailures int
+	retryAt       time.Time
 }
 
 type DeviceKeys struct {
-	PrivateKey string `json:"private_key"`
-	PublicKey  string `json:"public_key"`
-	DeviceID   string `json:"device_id"`
+	PrivateKey string        `json:"private_key,omitempty"`
+	PublicKey  string        `json:"public_key"`
+	DeviceID   string        `json:"device_id"`
+	Crypto     *EncryptedKey `json:"crypto,omitempty"`
 }
 
-type WeatherData struct {
-	DeviceID    string    `json:"device_id"`
-	Location    string    `json:"location"`
-	Temperature float64   `json:"temperature"`
-	Humidity    float64   `json:"humidity"`
-	Pressure    float64   `json:"pressure"`
-	WindSpeed   float64   `json:"wind_speed"`
-	WindDir     string    `json:"wind_direction"`
-	Timestamp   time.Time `json:"timestamp"`
-}
+type WeatherData = protocol.WeatherData
 
 type SubmissionPayload struct {
 	WeatherData WeatherData `json:"weather_data"`
 	DataHash    string      `json:"data_hash"`
 	Signature   string      `json:"signature"`
 	PublicKey   string      `json:"public_key"`
+	SigVersion  int         `json:"sig_version"`
 }
 
 func NewWeatherClient(config *Config) (*WeatherClient, error) {
+	outbox, err := NewOutbox(config.QueueDir, int64(config.QueueMaxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	return &WeatherClient{
 		Config: config,
+		Outbox: outbox,
+		HTTP:   httpClient,
 	}, nil
 }
 
+func newHTTPClient(config TLSConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", config.CAFile)
+		}
+	}
+
+	if config.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
+
 func (c *WeatherClient) LoadOrCreateKeys() error {
-	keys, err := c.loadKeys()
-	if err != nil {
+	if _, err := os.Stat(c.Config.KeysPath); errors.Is(err, os.ErrNotExist) {
 		fmt.Println("Creating new device keys...")
 		return c.generateAndSaveKeys()
 	}
+	return c.LoadKeys()
+}
+
+func (c *WeatherClient) LoadKeys() error {
+	keys, err := c.loadKeys(c.Config.KeysPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no device keys at %s, run `init` first", c.Config.KeysPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load device keys from %s: %v", c.Config.KeysPath, err)
+	}
+
+	if err := c.useKeys(keys); err != nil {
+		return err
+	}
 
+	fmt.Printf("Loaded existing device keys, device ID: %x\n", c.DeviceID)
+	return nil
+}
+
+func (c *WeatherClient) useKeys(keys *DeviceKeys) error {
 	privateKeyBytes, err := hex.DecodeString(keys.PrivateKey)
 	if err != nil {
 		return fmt.Errorf("failed to decode private key: %v", err)
 	}
 
-	c.PrivateKey, err = DeserializePrivateKey(privateKeyBytes)
+	privateKey, err := DeserializePrivateKey(privateKeyBytes)
 	if err != nil {
 		return fmt.Errorf("failed to deserialize private key: %v", err)
 	}
-
-	c.PublicKey = &c.PrivateKey.PublicKey
+	if publicKey := hex.EncodeToString(SerializePublicKey(&privateKey.PublicKey)); publicKey != keys.PublicKey {
+		return fmt.Errorf("private key does not match public key %s", keys.PublicKey)
+	}
 
 	deviceIDBytes, err := hex.DecodeString(keys.DeviceID)
 	if err != nil {
 		return fmt.Errorf("failed to decode device ID: %v", err)
 	}
-	c.DeviceID = deviceIDBytes
 
-	fmt.Printf("Loaded existing device keys, device ID: %x\n", c.DeviceID)
+	c.PrivateKey = privateKey
+	c.PublicKey = &privateKey.PublicKey
+	c.DeviceID = deviceIDBytes
 	return nil
 }
 
 func (c *WeatherClient) SubmitWeatherData() error {
-	weatherData := c.generateMockWeatherData()
+	weatherData, err := c.readSensors()
+	if err != nil {
+		return fmt.Errorf("failed to read sensors: %v", err)
+	}
+
+	sequence, err := c.nextSequence()
+	if err != nil {
+		return err
+	}
+	weatherData.Sequence = sequence
+
+	payload, err := c.signReading(weatherData)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Outbox.Enqueue(payload); err != nil {
+		return fmt.Errorf("failed to queue reading: %v", err)
+	}
+
+	return c.FlushOutbox()
+}
 
-	dataBytes, err := json.Marshal(weatherData)
+// signReading signs a reading with the oldest protocol version that can
+// encode it.
+func (c *WeatherClient) signReading(weatherData WeatherData) (SubmissionPayload, error) {
+	version := protocol.VersionFor(weatherData)
+	dataHash, err := protocol.Digest(weatherData, version)
 	if err != nil {
-		return fmt.Errorf("failed to marshal weather data: %v", err)
+		return SubmissionPayload{}, fmt.Errorf("failed to encode weather data: %v", err)
 	}
 
-	dataHash := sha256.Sum256(dataBytes)
-	signature, err := c.signData(dataHash[:])
+	signature, err := c.signDigest(dataHash)
 	if err != nil {
-		return fmt.Errorf("failed to sign data: %v", err)
+		return SubmissionPayload{}, fmt.Errorf("failed to sign data: %v", err)
 	}
 
 	publicKeyBytes := SerializePublicKey(c.PublicKey)
 
-	payload := SubmissionPayload{
+	return SubmissionPayload{
 		WeatherData: weatherData,
 		DataHash:    hex.EncodeToString(dataHash[:]),
 		Signature:   hex.EncodeToString(signature),
 		PublicKey:   hex.EncodeToString(publicKeyBytes),
-	}
-
-	return c.sendToBackend(payload)
+		SigVersion:  version,
+	}, nil
 }
 
 func (c *WeatherClient) RegisterDevice() error {
@@ -112,10 +202,13 @@ func (c *WeatherClient) RegisterDevice() error {
 
 	publicKeyBytes := SerializePublicKey(c.PublicKey)
 
-	registrationData := map[string]string{
+	registrationData := map[string]interface{}{
 		"device_id":  hex.EncodeToString(c.DeviceID),
 		"public_key": hex.EncodeToString(publicKeyBytes),
 		"location":   c.Config.DeviceLocation,
+		"latitude":   c.Config.Latitude,
+		"longitude":  c.Config.Longitude,
+		"elevation":  c.Config.Elevation,
 	}
 
 	payloadBytes, err := json.Marshal(registrationData)
@@ -123,7 +216,7 @@ func (c *WeatherClient) RegisterDevice() error {
 		return fmt.Errorf("failed to marshal registration data: %v", err)
 	}
 
-	resp, err := http.Post(c.Config.BackendURL+"/register", "application/json", bytes.NewBuffer(payloadBytes))
+	resp, err := c.HTTP.Post(c.Config.BackendURL+"/register", "application/json", bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to send registration request: %v", err)
 	}
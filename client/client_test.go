@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"weather-protocol"
+)
+
+// The golden vectors are shared with the protocol module and the backend.
+const goldenVectorsPath = "../protocol/testdata/golden.json"
+
+type goldenVector struct {
+	Name        string      `json:"name"`
+	SigVersion  int         `json:"sig_version"`
+	WeatherData WeatherData `json:"weather_data"`
+	Digest      string      `json:"digest"`
+}
+
+func loadGoldenVectors(t *testing.T) []goldenVector {
+	t.Helper()
+
+	raw, err := os.ReadFile(goldenVectorsPath)
+	if err != nil {
+		t.Fatalf("failed to read golden vectors: %v", err)
+	}
+
+	var vectors []goldenVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatalf("failed to parse golden vectors: %v", err)
+	}
+	return vectors
+}
+
+func TestSignReadingGoldenVectors(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &WeatherClient{PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+
+	for _, vector := range loadGoldenVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			payload, err := client.signReading(vector.WeatherData)
+			if err != nil {
+				t.Fatalf("signReading: %v", err)
+			}
+
+			if payload.SigVersion != vector.SigVersion {
+				t.Errorf("SigVersion = %d, want %d", payload.SigVersion, vector.SigVersion)
+			}
+			if payload.DataHash != vector.Digest {
+				t.Errorf("DataHash = %s, want %s", payload.DataHash, vector.Digest)
+			}
+
+			// The backend recomputes the digest from the JSON it receives, so
+			// the payload must survive a round trip unchanged.
+			encoded, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var received SubmissionPayload
+			if err := json.Unmarshal(encoded, &received); err != nil {
+				t.Fatal(err)
+			}
+			digest, err := protocol.Digest(received.WeatherData, received.SigVersion)
+			if err != nil {
+				t.Fatalf("Digest: %v", err)
+			}
+			if got := hex.EncodeToString(digest[:]); got != vector.Digest {
+				t.Errorf("digest after JSON round trip = %s, want %s", got, vector.Digest)
+			}
+
+			signature, err := hex.DecodeString(received.Signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, s, err := protocol.DecodeSignature(signature)
+			if err != nil {
+				t.Fatalf("DecodeSignature: %v", err)
+			}
+			if !ecdsa.Verify(&privateKey.PublicKey, digest[:], r, s) {
+				t.Error("signature does not verify against the golden digest")
+			}
+
+			publicKey, err := hex.DecodeString(received.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x, y := elliptic.Unmarshal(elliptic.P256(), publicKey)
+			if x == nil || x.Cmp(privateKey.X) != 0 || y.Cmp(privateKey.Y) != 0 {
+				t.Error("public_key is not the uncompressed signing key")
+			}
+		})
+	}
+}
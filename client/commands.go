@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type command struct {
+	Name    string
+	Args    string
+	NArgs   int
+	Summary string
+	Run     func(c *WeatherClient, args []string) error
+	// UsesLocation marks commands that send the station location name, which
+	// the configuration must then provide.
+	UsesLocation bool
+}
+
+var commands = []command{
+	{Name: "init", Summary: "Generate device keys", Run: runInit},
+	{Name: "register", Summary: "Register the device with the backend, generating keys if needed", Run: runRegister, UsesLocation: true},
+	{Name: "run", Summary: "Read sensors and submit readings every interval", Run: runLoop, UsesLocation: true},
+	{Name: "submit-once", Summary: "Read sensors and submit a single reading", Run: runSubmitOnce, UsesLocation: true},
+	{Name: "status", Summary: "Show the backend's record of this device and the local queue", Run: runStatus},
+	{Name: "show-id", Summary: "Print the device ID", Run: runShowID},
+	{Name: "export-pubkey", Summary: "Print the device public key as hex", Run: runExportPubkey},
+	{Name: "verify-config", Summary: "Check the configuration, keys, sensors and backend connection", Run: runVerifyConfig, UsesLocation: true},
+	{Name: "replay", Args: "<file>", NArgs: 1, Summary: "Resend signed payloads from a file (an outbox entry or a JSON array) and print each response", Run: runReplay},
+	{Name: "encrypt-keys", Summary: "Encrypt an existing plaintext key file with the configured passphrase", Run: runEncryptKeys},
+	{Name: "rotate-keys", Summary: "Replace the device key while keeping the device ID", Run: runRotateKeys},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].Name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func runInit(c *WeatherClient, args []string) error {
+	if _, err := os.Stat(c.Config.KeysPath); err == nil {
+		return fmt.Errorf("device keys already exist at %s", c.Config.KeysPath)
+	}
+
+	if err := c.generateAndSaveKeys(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Device keys written to %s\n", c.Config.KeysPath)
+	fmt.Printf("Device ID: %x\n", c.DeviceID)
+	return nil
+}
+
+func runRegister(c *WeatherClient, args []string) error {
+	if err := c.RegisterDevice(); err != nil {
+		return err
+	}
+	fmt.Println("Device registered successfully!")
+	return nil
+}
+
+func runLoop(c *WeatherClient, args []string) error {
+	fmt.Println("Starting Weather Data Client...")
+
+	if err := c.LoadOrCreateKeys(); err != nil {
+		return fmt.Errorf("failed to load keys: %v", err)
+	}
+
+	var err error
+	c.Sensors, err = OpenSensors(c.Config.Sensors)
+	if err != nil {
+		return fmt.Errorf("failed to open sensors: %v", err)
+	}
+	defer CloseSensors(c.Sensors)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Client running with device ID: %x\n", c.DeviceID)
+	fmt.Printf("Reading from sensors: %s\n", c.Config.Sensors)
+
+	// Readings a station pushes are submitted as they arrive, so each one is
+	// sent exactly once; other sensors are read on the interval.
+	var tick <-chan time.Time
+	var arrived <-chan struct{}
+	push := findPushSensor(c.Sensors)
+	if push != nil {
+		arrived = push.Arrived()
+		fmt.Printf("Submitting each reading from sensor %s as it arrives\n", push.Name())
+	} else {
+		ticker := time.NewTicker(time.Duration(c.Config.SubmissionInterval) * time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+		fmt.Printf("Submitting data every %d seconds\n", c.Config.SubmissionInterval)
+	}
+
+	retry := time.NewTimer(0)
+	if pending, err := c.Outbox.Pending(); err != nil || len(pending) == 0 {
+		retry.Stop()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("Shutting down")
+			return nil
+		case <-tick:
+			c.submitAndReport()
+		case <-arrived:
+			// A failure may leave the upload waiting, so stop there rather
+			// than retry it in a tight loop. It is tried again on the next
+			// upload.
+			for push.Waiting() && c.submitAndReport() {
+			}
+		case <-retry.C:
+			if err := c.FlushOutbox(); err != nil {
+				log.Printf("Failed to deliver queued readings: %v", err)
+			} else {
+				fmt.Println("Delivered queued readings")
+			}
+		}
+
+		if wait, pending := c.RetryIn(); pending {
+			retry.Reset(wait)
+		}
+	}
+}
+
+func (c *WeatherClient) submitAndReport() bool {
+	if err := c.SubmitWeatherData(); err != nil {
+		log.Printf("Failed to submit weather data: %v", err)
+		return false
+	}
+	fmt.Printf("Weather data submitted successfully at %s\n", time.Now().Format(time.RFC3339))
+	return true
+}
+
+func runSubmitOnce(c *WeatherClient, args []string) error {
+	if err := c.LoadKeys(); err != nil {
+		return err
+	}
+
+	var err error
+	c.Sensors, err = OpenSensors(c.Config.Sensors)
+	if err != nil {
+		return fmt.Errorf("failed to open sensors: %v", err)
+	}
+	defer CloseSensors(c.Sensors)
+
+	return c.SubmitWeatherData()
+}
+
+func runStatus(c *WeatherClient, args []string) error {
+	if err := c.loadIdentity(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Device ID:   %x\n", c.DeviceID)
+
+	state, err := c.loadState()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sequence:    %d\n", state.Sequence)
+
+	pending, err := c.Outbox.Pending()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Queued:      %d readings in %s\n", len(pending), c.Config.QueueDir)
+
+	resp, err := c.HTTP.Get(fmt.Sprintf("%s/devices/%x", c.Config.BackendURL, c.DeviceID))
+	if err != nil {
+		return fmt.Errorf("failed to reach backend: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		fmt.Println("Backend:     not registered")
+		return nil
+	}
+
+	var device map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("backend returned status %d: %v", resp.StatusCode, device)
+	}
+
+	formatted, err := json.MarshalIndent(device, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backend:\n%s\n", formatted)
+	return nil
+}
+
+func runShowID(c *WeatherClient, args []string) error {
+	if err := c.loadIdentity(); err != nil {
+		return err
+	}
+	fmt.Printf("%x\n", c.DeviceID)
+	return nil
+}
+
+func runExportPubkey(c *WeatherClient, args []string) error {
+	keys, err := readKeyFile(c.Config.KeysPath)
+	if err != nil {
+		return fmt.Errorf("failed to read device keys: %v", err)
+	}
+	fmt.Println(keys.PublicKey)
+	return nil
+}
+
+// runVerifyConfig checks what LoadConfig cannot: that the keys decrypt, the
+// sensors open and the backend answers. Every problem is reported rather than
+// stopping at the first, so a provisioning script sees everything that needs
+// fixing at once.
+func runVerifyConfig(c *WeatherClient, args []string) error {
+	problems := 0
+	check := func(name string, err error) {
+		if err != nil {
+			problems++
+			fmt.Printf("FAIL  %-10s %v\n", name, err)
+			return
+		}
+		fmt.Printf("ok    %s\n", name)
+	}
+
+	if c.Config.ConfigPath != "" {
+		fmt.Printf("ok    config     %s\n", c.Config.ConfigPath)
+	} else {
+		fmt.Println("ok    config     environment and defaults only")
+	}
+
+	_, err := c.passphrase()
+	check("passphrase", err)
+
+	if _, err := os.Stat(c.Config.KeysPath); errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("note  keys       no key file at %s yet, run `init`\n", c.Config.KeysPath)
+	} else {
+		_, err := c.loadKeys(c.Config.KeysPath)
+		check("keys", err)
+	}
+
+	sensors, err := OpenSensors(c.Config.Sensors)
+	check("sensors", err)
+	CloseSensors(sensors)
+
+	check("health", c.checkBackendHealth())
+
+	if problems > 0 {
+		return fmt.Errorf("%d problems found", problems)
+	}
+	return nil
+}
+
+func (c *WeatherClient) checkBackendHealth() error {
+	resp, err := c.HTTP.Get(c.Config.BackendURL + "/health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s/health returned status %d", c.Config.BackendURL, resp.StatusCode)
+	}
+	return nil
+}
+
+func runReplay(c *WeatherClient, args []string) error {
+	if err := c.loadIdentity(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var payloads []SubmissionPayload
+	if err := json.Unmarshal(data, &payloads); err != nil {
+		var payload SubmissionPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("%s is not a signed payload or an array of them: %v", args[0], err)
+		}
+		payloads = []SubmissionPayload{payload}
+	}
+
+	failed := 0
+	for _, payload := range payloads {
+		fmt.Printf("Replaying reading %d from device %s\n", payload.WeatherData.Sequence, payload.WeatherData.DeviceID)
+		if err := c.sendToBackend(payload); err != nil {
+			fmt.Printf("  %v\n", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("backend rejected %d of %d payloads", failed, len(payloads))
+	}
+	return nil
+}
+
+func runEncryptKeys(c *WeatherClient, args []string) error {
+	if err := c.EncryptKeyFile(); err != nil {
+		return err
+	}
+	fmt.Printf("Device keys in %s are now encrypted\n", c.Config.KeysPath)
+	return nil
+}
+
+func runRotateKeys(c *WeatherClient, args []string) error {
+	if err := c.RotateKeys(); err != nil {
+		return err
+	}
+	fmt.Printf("Device %x now signs with a new key\n", c.DeviceID)
+	return nil
+}
+
+// loadIdentity reads the device ID without decrypting the private key, so
+// read-only commands work without the passphrase.
+func (c *WeatherClient) loadIdentity() error {
+	keys, err := readKeyFile(c.Config.KeysPath)
+	if err != nil {
+		return fmt.Errorf("failed to read device keys: %v", err)
+	}
+
+	deviceID, err := hex.DecodeString(keys.DeviceID)
+	if err != nil {
+		return fmt.Errorf("failed to decode device ID: %v", err)
+	}
+	c.DeviceID = deviceID
+	return nil
+}
@@ -1,40 +1,414 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+const defaultConfigPath = "./client.yaml"
+
 type Config struct {
+	ConfigPath         string
 	BackendURL         string
 	SubmissionInterval int
 	KeysPath           string
+	KeysPassphrase     string
+	KeysPassphraseFile string
+	StatePath          string
 	DeviceLocation     string
+	Latitude           *float64
+	Longitude          *float64
+	Elevation          *float64
+	Sensors            string
+	QueueDir           string
+	QueueMaxBytes      int
+	RetryBaseDelay     int
+	RetryMaxDelay      int
+	BatchSize          int
+	TLS                TLSConfig
+}
+
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// fileConfig is the layout of the YAML config file. Unknown keys are rejected
+// so a typo does not silently fall back to a default.
+type fileConfig struct {
+	Backend struct {
+		URL string `yaml:"url"`
+		TLS struct {
+			CAFile             string `yaml:"ca_file"`
+			CertFile           string `yaml:"cert_file"`
+			KeyFile            string `yaml:"key_file"`
+			ServerName         string `yaml:"server_name"`
+			InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+		} `yaml:"tls"`
+	} `yaml:"backend"`
+	Interval *int `yaml:"interval"`
+	Keys     struct {
+		Path           string `yaml:"path"`
+		PassphraseFile string `yaml:"passphrase_file"`
+	} `yaml:"keys"`
+	StatePath string `yaml:"state_path"`
+	Location  struct {
+		Name      string   `yaml:"name"`
+		Latitude  *float64 `yaml:"latitude"`
+		Longitude *float64 `yaml:"longitude"`
+		Elevation *float64 `yaml:"elevation"`
+	} `yaml:"location"`
+	Sensors []struct {
+		Driver  string            `yaml:"driver"`
+		Options map[string]string `yaml:"options"`
+	} `yaml:"sensors"`
+	Queue struct {
+		Dir            string `yaml:"dir"`
+		MaxBytes       *int   `yaml:"max_bytes"`
+		RetryBaseDelay *int   `yaml:"retry_base_delay"`
+		RetryMaxDelay  *int   `yaml:"retry_max_delay"`
+		BatchSize      *int   `yaml:"batch_size"`
+	} `yaml:"queue"`
+}
+
+// ConfigError lists every problem found while loading the configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%d configuration problems:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+type configLoader struct {
+	requireLocation bool
+	problems        []string
 }
 
-func LoadConfig() (*Config, error) {
+func (l *configLoader) problem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+// LoadConfig builds the configuration from, in increasing priority, built-in
+// defaults, the YAML config file, environment variables and the flags in args.
+// All problems are reported together rather than stopping at the first. The
+// location name is only required when requireLocation is set.
+func LoadConfig(flags *flag.FlagSet, args []string, requireLocation bool) (*Config, error) {
+	var overrides Config
+	flags.StringVar(&overrides.ConfigPath, "config", "", "YAML config file (CLIENT_CONFIG, default "+defaultConfigPath+" if it exists)")
+	flags.StringVar(&overrides.BackendURL, "backend", "", "backend API URL (BACKEND_URL)")
+	flags.IntVar(&overrides.SubmissionInterval, "interval", 0, "seconds between submissions (SUBMISSION_INTERVAL)")
+	flags.StringVar(&overrides.KeysPath, "keys", "", "device key file (KEYS_PATH)")
+	flags.StringVar(&overrides.KeysPassphraseFile, "passphrase-file", "", "file holding the key passphrase (KEYS_PASSPHRASE_FILE)")
+	flags.StringVar(&overrides.StatePath, "state", "", "sequence state file, defaults to next to the key file (STATE_PATH)")
+	flags.StringVar(&overrides.DeviceLocation, "location", "", "station location name (DEVICE_LOCATION)")
+	flags.StringVar(&overrides.Sensors, "sensors", "", "comma-separated sensor drivers (SENSORS)")
+	flags.StringVar(&overrides.QueueDir, "queue-dir", "", "outbox directory, defaults to next to the key file (QUEUE_DIR)")
+	flags.IntVar(&overrides.BatchSize, "batch-size", 0, "queued readings per batch request (BATCH_SIZE)")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		BackendURL:         getEnvOrDefault("BACKEND_URL", "http://localhost:8080"),
-		SubmissionInterval: getEnvIntOrDefault("SUBMISSION_INTERVAL", 300),
-		KeysPath:           getEnvOrDefault("KEYS_PATH", "./device_keys.json"),
-		DeviceLocation:     getEnvOrDefault("DEVICE_LOCATION", "Unknown"),
+		BackendURL:         "http://localhost:8080",
+		SubmissionInterval: 300,
+		KeysPath:           "./device_keys.json",
+		Sensors:            "mock",
+		QueueMaxBytes:      10 * 1024 * 1024,
+		RetryBaseDelay:     5,
+		RetryMaxDelay:      900,
+		BatchSize:          100,
+	}
+	loader := &configLoader{requireLocation: requireLocation}
+
+	config.ConfigPath = os.Getenv("CLIENT_CONFIG")
+	if flagSet(flags, "config") {
+		config.ConfigPath = overrides.ConfigPath
+	}
+	loader.loadFile(config)
+	loader.loadEnv(config)
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "backend":
+			config.BackendURL = overrides.BackendURL
+		case "interval":
+			config.SubmissionInterval = overrides.SubmissionInterval
+		case "keys":
+			config.KeysPath = overrides.KeysPath
+		case "passphrase-file":
+			// A passphrase file given on the command line wins over KEYS_PASSPHRASE.
+			config.KeysPassphraseFile = overrides.KeysPassphraseFile
+			config.KeysPassphrase = ""
+		case "state":
+			config.StatePath = overrides.StatePath
+		case "location":
+			config.DeviceLocation = overrides.DeviceLocation
+		case "sensors":
+			config.Sensors = overrides.Sensors
+		case "queue-dir":
+			config.QueueDir = overrides.QueueDir
+		case "batch-size":
+			config.BatchSize = overrides.BatchSize
+		}
+	})
+
+	if config.StatePath == "" {
+		config.StatePath = filepath.Join(filepath.Dir(config.KeysPath), "device_state.json")
+	}
+	if config.QueueDir == "" {
+		config.QueueDir = filepath.Join(filepath.Dir(config.KeysPath), "outbox")
 	}
 
+	loader.validate(config)
+	if len(loader.problems) > 0 {
+		return nil, &ConfigError{Problems: loader.problems}
+	}
 	return config, nil
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (l *configLoader) loadFile(config *Config) {
+	path := config.ConfigPath
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err != nil {
+			return
+		}
+		path = defaultConfigPath
+		config.ConfigPath = path
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.problem("failed to read config file: %v", err)
+		return
+	}
+
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		l.problem("%s: %v", path, err)
+		return
+	}
+
+	setString(&config.BackendURL, file.Backend.URL)
+	setString(&config.TLS.CAFile, file.Backend.TLS.CAFile)
+	setString(&config.TLS.CertFile, file.Backend.TLS.CertFile)
+	setString(&config.TLS.KeyFile, file.Backend.TLS.KeyFile)
+	setString(&config.TLS.ServerName, file.Backend.TLS.ServerName)
+	config.TLS.InsecureSkipVerify = file.Backend.TLS.InsecureSkipVerify
+	setInt(&config.SubmissionInterval, file.Interval)
+	setString(&config.KeysPath, file.Keys.Path)
+	setString(&config.KeysPassphraseFile, file.Keys.PassphraseFile)
+	setString(&config.StatePath, file.StatePath)
+	setString(&config.DeviceLocation, file.Location.Name)
+	config.Latitude = file.Location.Latitude
+	config.Longitude = file.Location.Longitude
+	config.Elevation = file.Location.Elevation
+	setString(&config.QueueDir, file.Queue.Dir)
+	setInt(&config.QueueMaxBytes, file.Queue.MaxBytes)
+	setInt(&config.RetryBaseDelay, file.Queue.RetryBaseDelay)
+	setInt(&config.RetryMaxDelay, file.Queue.RetryMaxDelay)
+	setInt(&config.BatchSize, file.Queue.BatchSize)
+
+	if len(file.Sensors) > 0 {
+		entries := make([]string, 0, len(file.Sensors))
+		for i, sensor := range file.Sensors {
+			if sensor.Driver == "" {
+				l.problem("sensors[%d]: driver is required", i)
+				continue
+			}
+			entries = append(entries, sensorSpecEntry(sensor.Driver, sensor.Options))
+		}
+		config.Sensors = strings.Join(entries, ",")
 	}
-	return defaultValue
 }
 
-func getEnvIntOrDefault(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+func sensorSpecEntry(driver string, options map[string]string) string {
+	if len(options) == 0 {
+		return driver
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := url.Values{}
+	for _, key := range keys {
+		values.Set(key, options[key])
+	}
+	return driver + "?" + values.Encode()
+}
+
+func (l *configLoader) loadEnv(config *Config) {
+	l.envString("BACKEND_URL", &config.BackendURL)
+	l.envInt("SUBMISSION_INTERVAL", &config.SubmissionInterval)
+	l.envString("KEYS_PATH", &config.KeysPath)
+	l.envString("KEYS_PASSPHRASE", &config.KeysPassphrase)
+	l.envString("KEYS_PASSPHRASE_FILE", &config.KeysPassphraseFile)
+	l.envString("STATE_PATH", &config.StatePath)
+	l.envString("DEVICE_LOCATION", &config.DeviceLocation)
+	l.envFloat("DEVICE_LATITUDE", &config.Latitude)
+	l.envFloat("DEVICE_LONGITUDE", &config.Longitude)
+	l.envFloat("DEVICE_ELEVATION", &config.Elevation)
+	l.envString("SENSORS", &config.Sensors)
+	l.envString("QUEUE_DIR", &config.QueueDir)
+	l.envInt("QUEUE_MAX_BYTES", &config.QueueMaxBytes)
+	l.envInt("RETRY_BASE_DELAY", &config.RetryBaseDelay)
+	l.envInt("RETRY_MAX_DELAY", &config.RetryMaxDelay)
+	l.envInt("BATCH_SIZE", &config.BatchSize)
+	l.envString("TLS_CA_FILE", &config.TLS.CAFile)
+	l.envString("TLS_CERT_FILE", &config.TLS.CertFile)
+	l.envString("TLS_KEY_FILE", &config.TLS.KeyFile)
+	l.envString("TLS_SERVER_NAME", &config.TLS.ServerName)
+	l.envBool("TLS_INSECURE_SKIP_VERIFY", &config.TLS.InsecureSkipVerify)
+}
+
+func (l *configLoader) validate(config *Config) {
+	if parsed, err := url.Parse(config.BackendURL); err != nil {
+		l.problem("backend URL %q is invalid: %v", config.BackendURL, err)
+	} else if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		l.problem("backend URL %q must be an http or https URL", config.BackendURL)
+	} else if parsed.Host == "" {
+		l.problem("backend URL %q has no host", config.BackendURL)
+	}
+
+	l.positive("interval", config.SubmissionInterval)
+	l.positive("batch size", config.BatchSize)
+	l.positive("retry base delay", config.RetryBaseDelay)
+	if config.RetryMaxDelay < config.RetryBaseDelay {
+		l.problem("retry max delay (%d) is less than retry base delay (%d)", config.RetryMaxDelay, config.RetryBaseDelay)
+	}
+	if config.QueueMaxBytes < 0 {
+		l.problem("queue max bytes must not be negative, got %d", config.QueueMaxBytes)
+	}
+
+	if config.KeysPath == "" {
+		l.problem("keys path is required")
+	}
+
+	if name := strings.TrimSpace(config.DeviceLocation); l.requireLocation && (name == "" || strings.EqualFold(name, "unknown")) {
+		l.problem("location name is required (location.name or DEVICE_LOCATION)")
+	}
+	if (config.Latitude == nil) != (config.Longitude == nil) {
+		l.problem("latitude and longitude must be set together")
+	}
+	if config.Latitude != nil && (*config.Latitude < -90 || *config.Latitude > 90) {
+		l.problem("latitude %v is outside -90..90", *config.Latitude)
+	}
+	if config.Longitude != nil && (*config.Longitude < -180 || *config.Longitude > 180) {
+		l.problem("longitude %v is outside -180..180", *config.Longitude)
+	}
+	if config.Elevation != nil && (*config.Elevation < -500 || *config.Elevation > 9000) {
+		l.problem("elevation %v m is outside -500..9000", *config.Elevation)
+	}
+
+	if _, err := parseSensorSpec(config.Sensors); err != nil {
+		l.problem("sensors: %v", err)
+	}
+
+	if (config.TLS.CertFile == "") != (config.TLS.KeyFile == "") {
+		l.problem("TLS cert file and key file must be set together")
+	}
+	for _, path := range []string{config.TLS.CAFile, config.TLS.CertFile, config.TLS.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			l.problem("TLS file: %v", err)
 		}
 	}
-	return defaultValue
+}
+
+func (l *configLoader) positive(name string, value int) {
+	if value <= 0 {
+		l.problem("%s must be greater than zero, got %d", name, value)
+	}
+}
+
+func (l *configLoader) envString(key string, dst *string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+func (l *configLoader) envInt(key string, dst *int) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.problem("%s=%q is not an integer", key, value)
+		return
+	}
+	*dst = parsed
+}
+
+func (l *configLoader) envFloat(key string, dst **float64) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.problem("%s=%q is not a number", key, value)
+		return
+	}
+	*dst = &parsed
+}
+
+func (l *configLoader) envBool(key string, dst *bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.problem("%s=%q is not a boolean", key, value)
+		return
+	}
+	*dst = parsed
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func setInt(dst *int, value *int) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// configEnv lists every variable LoadConfig reads, so a test starts from a
+// clean environment.
+var configEnv = []string{
+	"CLIENT_CONFIG", "BACKEND_URL", "SUBMISSION_INTERVAL", "KEYS_PATH", "KEYS_PASSPHRASE", "KEYS_PASSPHRASE_FILE",
+	"STATE_PATH", "DEVICE_LOCATION", "DEVICE_LATITUDE", "DEVICE_LONGITUDE", "DEVICE_ELEVATION", "SENSORS",
+	"QUEUE_DIR", "QUEUE_MAX_BYTES", "RETRY_BASE_DELAY", "RETRY_MAX_DELAY", "BATCH_SIZE",
+	"TLS_CA_FILE", "TLS_CERT_FILE", "TLS_KEY_FILE", "TLS_SERVER_NAME", "TLS_INSECURE_SKIP_VERIFY",
+}
+
+// loadTestConfig loads the configuration from the YAML file contents, if any,
+// the environment variables in env and the flags in args.
+func loadTestConfig(t *testing.T, file string, env map[string]string, args []string, requireLocation bool) (*Config, error) {
+	t.Helper()
+
+	for _, key := range configEnv {
+		t.Setenv(key, "")
+	}
+	if file != "" {
+		path := filepath.Join(t.TempDir(), "client.yaml")
+		if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("CLIENT_CONFIG", path)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	return LoadConfig(flags, args, requireLocation)
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	file := `
+backend:
+  url: http://file.example.org
+interval: 60
+keys:
+  path: /var/lib/weather/device_keys.json
+location:
+  name: Rooftop
+  latitude: 59.9
+  longitude: 10.7
+sensors:
+  - driver: mock
+    options:
+      unit: c
+      seed: "1"
+queue:
+  batch_size: 10
+`
+	env := map[string]string{
+		"BACKEND_URL":         "http://env.example.org",
+		"SUBMISSION_INTERVAL": "120",
+		"KEYS_PASSPHRASE":     "from the environment",
+		"DEVICE_ELEVATION":    "23",
+	}
+	args := []string{"-backend", "http://flag.example.org", "-passphrase-file", "/run/secrets/passphrase", "extra"}
+
+	config, err := loadTestConfig(t, file, env, args, true)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	latitude, longitude, elevation := 59.9, 10.7, 23.0
+	want := &Config{
+		ConfigPath:         os.Getenv("CLIENT_CONFIG"),
+		BackendURL:         "http://flag.example.org",
+		SubmissionInterval: 120,
+		KeysPath:           "/var/lib/weather/device_keys.json",
+		KeysPassphraseFile: "/run/secrets/passphrase",
+		StatePath:          "/var/lib/weather/device_state.json",
+		DeviceLocation:     "Rooftop",
+		Latitude:           &latitude,
+		Longitude:          &longitude,
+		Elevation:          &elevation,
+		Sensors:            "mock?seed=1&unit=c",
+		QueueDir:           "/var/lib/weather/outbox",
+		QueueMaxBytes:      10 * 1024 * 1024,
+		RetryBaseDelay:     5,
+		RetryMaxDelay:      900,
+		BatchSize:          10,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config =\n%+v\nwant\n%+v", config, want)
+	}
+}
+
+func TestLoadConfigFileFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.yaml")
+	if err := os.WriteFile(path, []byte("interval: 30\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// -config wins over CLIENT_CONFIG.
+	config, err := loadTestConfig(t, "interval: 60\n", nil, []string{"-config", path}, false)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.ConfigPath != path || config.SubmissionInterval != 30 {
+		t.Errorf("loaded interval %d from %s, want 30 from %s", config.SubmissionInterval, config.ConfigPath, path)
+	}
+
+	// Without a file the defaults apply.
+	config, err = loadTestConfig(t, "", nil, nil, false)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.BackendURL != "http://localhost:8080" || config.SubmissionInterval != 300 || config.Sensors != "mock" {
+		t.Errorf("defaults are %s every %ds from %s", config.BackendURL, config.SubmissionInterval, config.Sensors)
+	}
+}
+
+func TestLoadConfigReportsAllProblems(t *testing.T) {
+	file := `
+backend:
+  url: ftp://weather.example.org
+interval: 0
+location:
+  name: Unknown
+  latitude: 91
+sensors:
+  - options:
+      seed: "1"
+queue:
+  retry_base_delay: 60
+  retry_max_delay: 30
+`
+	env := map[string]string{"BATCH_SIZE": "lots", "TLS_CERT_FILE": "/nonexistent/client.pem"}
+
+	_, err := loadTestConfig(t, file, env, nil, true)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("LoadConfig error = %v, want a ConfigError", err)
+	}
+
+	for _, want := range []string{
+		"sensors[0]: driver is required",
+		`BATCH_SIZE="lots" is not an integer`,
+		`backend URL "ftp://weather.example.org" must be an http or https URL`,
+		"interval must be greater than zero, got 0",
+		"retry max delay (30) is less than retry base delay (60)",
+		"location name is required",
+		"latitude and longitude must be set together",
+		"latitude 91 is outside -90..90",
+		"sensors: no sensors configured",
+		"TLS cert file and key file must be set together",
+		"TLS file: stat /nonexistent/client.pem",
+	} {
+		found := false
+		for _, problem := range configErr.Problems {
+			found = found || strings.Contains(problem, want)
+		}
+		if !found {
+			t.Errorf("problem %q not reported in %q", want, configErr.Problems)
+		}
+	}
+	if len(configErr.Problems) != 11 {
+		t.Errorf("reported %d problems, want 11: %q", len(configErr.Problems), configErr.Problems)
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	_, err := loadTestConfig(t, "intervall: 60\n", nil, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "field intervall not found") {
+		t.Errorf("LoadConfig error = %v, want the unknown key reported", err)
+	}
+}
+
+func TestLoadConfigLocation(t *testing.T) {
+	// Only commands that send the location need one.
+	if _, err := loadTestConfig(t, "", nil, nil, false); err != nil {
+		t.Errorf("LoadConfig without a location: %v", err)
+	}
+	if _, err := loadTestConfig(t, "", nil, nil, true); err == nil {
+		t.Error("LoadConfig accepted a missing location")
+	}
+	if _, err := loadTestConfig(t, "", nil, []string{"-location", "Garden"}, true); err != nil {
+		t.Errorf("LoadConfig with -location: %v", err)
+	}
+}
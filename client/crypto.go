@@ -10,34 +10,91 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+
+	"weather-protocol"
 )
 
 func (c *WeatherClient) generateAndSaveKeys() error {
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	privateKey, keys, err := newDeviceKeys()
 	if err != nil {
-		return fmt.Errorf("failed to generate private key: %v", err)
+		return err
 	}
 
 	c.PrivateKey = privateKey
 	c.PublicKey = &privateKey.PublicKey
 
-	publicKeyBytes := SerializePublicKey(c.PublicKey)
-	privateKeyBytes := SerializePrivateKey(c.PrivateKey)
-
-	hash := sha256.Sum256(publicKeyBytes)
+	hash := sha256.Sum256(SerializePublicKey(c.PublicKey))
 	c.DeviceID = hash[:16]
+	keys.DeviceID = hex.EncodeToString(c.DeviceID)
+
+	return c.saveKeys(c.Config.KeysPath, keys)
+}
+
+// newDeviceKeys generates a key pair. The caller fills in DeviceID, which only
+// a device's first key is derived from.
+func newDeviceKeys() (*ecdsa.PrivateKey, DeviceKeys, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, DeviceKeys{}, fmt.Errorf("failed to generate private key: %v", err)
+	}
 
 	keys := DeviceKeys{
-		PrivateKey: hex.EncodeToString(privateKeyBytes),
-		PublicKey:  hex.EncodeToString(publicKeyBytes),
-		DeviceID:   hex.EncodeToString(c.DeviceID),
+		PrivateKey: hex.EncodeToString(SerializePrivateKey(privateKey)),
+		PublicKey:  hex.EncodeToString(SerializePublicKey(&privateKey.PublicKey)),
+	}
+	return privateKey, keys, nil
+}
+
+// loadKeys returns the device keys with the private key in plaintext,
+// decrypting the key file if it is encrypted.
+func (c *WeatherClient) loadKeys(path string) (*DeviceKeys, error) {
+	keys, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := c.passphrase()
+	if err != nil {
+		return nil, err
 	}
 
-	return c.saveKeys(keys)
+	if keys.Crypto == nil {
+		if passphrase != "" {
+			fmt.Println("Warning: device keys are stored unencrypted; run `go run . encrypt-keys` to encrypt them")
+		}
+		return keys, nil
+	}
+
+	if passphrase == "" {
+		return nil, errKeysEncrypted
+	}
+
+	decrypted, err := decryptKeys(*keys, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
 }
 
-func (c *WeatherClient) loadKeys() (*DeviceKeys, error) {
-	data, err := os.ReadFile(c.Config.KeysPath)
+// saveKeys writes the device keys, encrypting them when a passphrase is
+// configured.
+func (c *WeatherClient) saveKeys(path string, keys DeviceKeys) error {
+	passphrase, err := c.passphrase()
+	if err != nil {
+		return err
+	}
+
+	if passphrase != "" {
+		if keys, err = encryptKeys(keys, passphrase); err != nil {
+			return err
+		}
+	}
+
+	return writeKeyFile(path, keys)
+}
+
+func readKeyFile(path string) (*DeviceKeys, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -51,24 +108,30 @@ func (c *WeatherClient) loadKeys() (*DeviceKeys, error) {
 	return &keys, nil
 }
 
-func (c *WeatherClient) saveKeys(keys DeviceKeys) error {
+func writeKeyFile(path string, keys DeviceKeys) error {
 	data, err := json.MarshalIndent(keys, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(c.Config.KeysPath, data, 0600)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (c *WeatherClient) signDigest(digest [32]byte) ([]byte, error) {
+	return signDigestWith(c.PrivateKey, digest)
 }
 
-func (c *WeatherClient) signData(data []byte) ([]byte, error) {
-	hash := sha256.Sum256(data)
-	r, s, err := ecdsa.Sign(rand.Reader, c.PrivateKey, hash[:])
+func signDigestWith(privateKey *ecdsa.PrivateKey, digest [32]byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
 	if err != nil {
 		return nil, err
 	}
 
-	signature := append(r.Bytes(), s.Bytes()...)
-	return signature, nil
+	return protocol.EncodeSignature(r, s), nil
 }
 
 func SerializePublicKey(pubKey *ecdsa.PublicKey) []byte {
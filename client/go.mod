@@ -1,3 +1,11 @@
 module weather-client
 
 go 1.24.3
+
+require (
+	golang.org/x/crypto v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
+	weather-protocol v0.0.0
+)
+
+replace weather-protocol => ../protocol
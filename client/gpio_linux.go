@@ -0,0 +1,122 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const gpioSysfsRoot = "/sys/class/gpio"
+
+type sysfsPulseCounter struct {
+	value    *os.File
+	epfd     int
+	debounce time.Duration
+	pulses   atomic.Uint64
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func OpenGPIOPulseCounter(pin int, edge string, debounce time.Duration) (PulseCounter, error) {
+	if edge != "rising" && edge != "falling" && edge != "both" {
+		return nil, fmt.Errorf("invalid edge %q: expected rising, falling or both", edge)
+	}
+
+	dir := filepath.Join(gpioSysfsRoot, "gpio"+strconv.Itoa(pin))
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(filepath.Join(gpioSysfsRoot, "export"), []byte(strconv.Itoa(pin)), 0); err != nil {
+			return nil, fmt.Errorf("failed to export GPIO %d: %v", pin, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "direction"), []byte("in"), 0); err != nil {
+		return nil, fmt.Errorf("failed to configure GPIO %d as input: %v", pin, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "edge"), []byte(edge), 0); err != nil {
+		return nil, fmt.Errorf("failed to set GPIO %d edge: %v", pin, err)
+	}
+
+	value, err := os.Open(filepath.Join(dir, "value"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GPIO %d: %v", pin, err)
+	}
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		value.Close()
+		return nil, fmt.Errorf("failed to create epoll instance: %v", err)
+	}
+
+	counter := &sysfsPulseCounter{
+		value:    value,
+		epfd:     epfd,
+		debounce: debounce,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	// sysfs reports the current level as an event until value has been read once.
+	counter.clear()
+
+	event := syscall.EpollEvent{Events: syscall.EPOLLPRI | syscall.EPOLLERR, Fd: int32(value.Fd())}
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, int(value.Fd()), &event); err != nil {
+		syscall.Close(epfd)
+		value.Close()
+		return nil, fmt.Errorf("failed to watch GPIO %d: %v", pin, err)
+	}
+
+	go counter.watch()
+	return counter, nil
+}
+
+func (c *sysfsPulseCounter) watch() {
+	defer close(c.done)
+
+	events := make([]syscall.EpollEvent, 1)
+	var last time.Time
+	for {
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+
+		n, err := syscall.EpollWait(c.epfd, events, 500)
+		if err != nil && err != syscall.EINTR {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		c.clear()
+		now := time.Now()
+		if now.Sub(last) >= c.debounce {
+			c.pulses.Add(1)
+			last = now
+		}
+	}
+}
+
+func (c *sysfsPulseCounter) clear() {
+	buf := make([]byte, 2)
+	c.value.ReadAt(buf, 0)
+}
+
+func (c *sysfsPulseCounter) Pulses() uint64 {
+	return c.pulses.Load()
+}
+
+func (c *sysfsPulseCounter) Close() error {
+	close(c.stop)
+	<-c.done
+	syscall.Close(c.epfd)
+	return c.value.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func OpenGPIOPulseCounter(pin int, edge string, debounce time.Duration) (PulseCounter, error) {
+	return nil, fmt.Errorf("GPIO is only supported on Linux")
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const i2cSlave = 0x0703
+
+type linuxI2CBus struct {
+	file *os.File
+}
+
+func OpenI2CBus(path string, address uint16) (I2CBus, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+
+	if err := ioctl(file, i2cSlave, uintptr(address)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to select I2C address 0x%02x on %s: %v", address, path, err)
+	}
+
+	return &linuxI2CBus{file: file}, nil
+}
+
+func (b *linuxI2CBus) ReadReg(reg byte, buf []byte) error {
+	if _, err := b.file.Write([]byte{reg}); err != nil {
+		return fmt.Errorf("failed to select register 0x%02x: %v", reg, err)
+	}
+	if _, err := b.file.Read(buf); err != nil {
+		return fmt.Errorf("failed to read register 0x%02x: %v", reg, err)
+	}
+	return nil
+}
+
+func (b *linuxI2CBus) WriteReg(reg, value byte) error {
+	if _, err := b.file.Write([]byte{reg, value}); err != nil {
+		return fmt.Errorf("failed to write register 0x%02x: %v", reg, err)
+	}
+	return nil
+}
+
+func (b *linuxI2CBus) Close() error {
+	return b.file.Close()
+}
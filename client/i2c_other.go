@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+func OpenI2CBus(path string, address uint16) (I2CBus, error) {
+	return nil, fmt.Errorf("I2C is only supported on Linux")
+}
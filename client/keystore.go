@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The scrypt cost is stored alongside each encrypted key, so it can be raised
+// later without breaking existing files. N=2^15 needs 32 MiB and well under a
+// second on a Raspberry Pi, unlike the 256 MiB of the go-ethereum default.
+const (
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKDF     = "scrypt"
+	keystoreScryptN = 1 << 15
+	keystoreScryptR = 8
+	keystoreScryptP = 1
+	keystoreKeyLen  = 32
+
+	// A key file is read before it can be authenticated, so its scrypt cost is
+	// capped at the go-ethereum default to stop a tampered file from exhausting
+	// memory or stalling startup.
+	keystoreMaxScryptMemory = 256 << 20
+	keystoreMaxScryptP      = 16
+)
+
+var (
+	errKeysEncrypted   = errors.New("device keys are encrypted; set KEYS_PASSPHRASE or KEYS_PASSPHRASE_FILE")
+	errWrongPassphrase = errors.New("failed to decrypt device keys: wrong passphrase or corrupted key file")
+	errNoPassphrase    = errors.New("no passphrase configured; set KEYS_PASSPHRASE or KEYS_PASSPHRASE_FILE")
+)
+
+// EncryptedKey holds a private key encrypted with AES-256-GCM under a key
+// derived from the passphrase with scrypt, in the spirit of the go-ethereum v3
+// keystore. The device ID and public key are authenticated as additional data
+// so a ciphertext cannot be moved to another key file unnoticed.
+type EncryptedKey struct {
+	Cipher     string       `json:"cipher"`
+	Ciphertext string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+}
+
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+func encryptKeys(keys DeviceKeys, passphrase string) (DeviceKeys, error) {
+	privateKeyBytes, err := hex.DecodeString(keys.PrivateKey)
+	if err != nil {
+		return keys, fmt.Errorf("failed to decode private key: %v", err)
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return keys, err
+	}
+
+	params := ScryptParams{
+		N:     keystoreScryptN,
+		R:     keystoreScryptR,
+		P:     keystoreScryptP,
+		DKLen: keystoreKeyLen,
+		Salt:  hex.EncodeToString(salt),
+	}
+
+	gcm, err := keystoreAEAD(passphrase, params)
+	if err != nil {
+		return keys, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return keys, err
+	}
+
+	ciphertext := gcm.Seal(nil, nonce, privateKeyBytes, keystoreAdditionalData(keys))
+
+	keys.PrivateKey = ""
+	keys.Crypto = &EncryptedKey{
+		Cipher:     keystoreCipher,
+		Ciphertext: hex.EncodeToString(ciphertext),
+		Nonce:      hex.EncodeToString(nonce),
+		KDF:        keystoreKDF,
+		KDFParams:  params,
+	}
+	return keys, nil
+}
+
+func decryptKeys(keys DeviceKeys, passphrase string) (DeviceKeys, error) {
+	crypto := keys.Crypto
+	if crypto.Cipher != keystoreCipher || crypto.KDF != keystoreKDF {
+		return keys, fmt.Errorf("unsupported key encryption %s/%s", crypto.KDF, crypto.Cipher)
+	}
+
+	ciphertext, err := hex.DecodeString(crypto.Ciphertext)
+	if err != nil {
+		return keys, fmt.Errorf("failed to decode encrypted key: %v", err)
+	}
+	nonce, err := hex.DecodeString(crypto.Nonce)
+	if err != nil {
+		return keys, fmt.Errorf("failed to decode nonce: %v", err)
+	}
+
+	gcm, err := keystoreAEAD(passphrase, crypto.KDFParams)
+	if err != nil {
+		return keys, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return keys, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	privateKeyBytes, err := gcm.Open(nil, nonce, ciphertext, keystoreAdditionalData(keys))
+	if err != nil {
+		return keys, errWrongPassphrase
+	}
+
+	keys.PrivateKey = hex.EncodeToString(privateKeyBytes)
+	keys.Crypto = nil
+	return keys, nil
+}
+
+func keystoreAEAD(passphrase string, params ScryptParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %v", err)
+	}
+	if params.DKLen != keystoreKeyLen {
+		return nil, fmt.Errorf("unsupported derived key length %d", params.DKLen)
+	}
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// check rejects scrypt parameters that are invalid or too expensive. scrypt
+// needs 128*N*r bytes of memory and p times the work.
+func (p ScryptParams) check() error {
+	if p.N < 2 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("invalid scrypt N %d: must be a power of two", p.N)
+	}
+	if p.R < 1 || p.P < 1 {
+		return fmt.Errorf("invalid scrypt r %d or p %d", p.R, p.P)
+	}
+	if p.N > keystoreMaxScryptMemory/128/p.R {
+		return fmt.Errorf("scrypt N %d and r %d need more than %d MiB", p.N, p.R, keystoreMaxScryptMemory>>20)
+	}
+	if p.P > keystoreMaxScryptP {
+		return fmt.Errorf("scrypt p %d exceeds %d", p.P, keystoreMaxScryptP)
+	}
+	return nil
+}
+
+func keystoreAdditionalData(keys DeviceKeys) []byte {
+	return []byte(keys.DeviceID + ":" + keys.PublicKey)
+}
+
+// passphrase returns the key file passphrase from KEYS_PASSPHRASE, or from the
+// first line of KEYS_PASSPHRASE_FILE so it can live on a separate mount or be
+// injected as a secret.
+func (c *WeatherClient) passphrase() (string, error) {
+	if c.Config.KeysPassphrase != "" {
+		return c.Config.KeysPassphrase, nil
+	}
+	if c.Config.KeysPassphraseFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(c.Config.KeysPassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase file: %v", err)
+	}
+
+	passphrase, _, _ := strings.Cut(string(data), "\n")
+	passphrase = strings.TrimSuffix(passphrase, "\r")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase file %s is empty", c.Config.KeysPassphraseFile)
+	}
+	return passphrase, nil
+}
+
+// EncryptKeyFile rewrites an existing plaintext key file in encrypted form.
+func (c *WeatherClient) EncryptKeyFile() error {
+	passphrase, err := c.passphrase()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return errNoPassphrase
+	}
+
+	keys, err := readKeyFile(c.Config.KeysPath)
+	if err != nil {
+		return err
+	}
+	if keys.Crypto != nil {
+		return fmt.Errorf("device keys in %s are already encrypted", c.Config.KeysPath)
+	}
+	if keys.PrivateKey == "" {
+		return fmt.Errorf("key file %s has no private key", c.Config.KeysPath)
+	}
+
+	encrypted, err := encryptKeys(*keys, passphrase)
+	if err != nil {
+		return err
+	}
+	if _, err := decryptKeys(encrypted, passphrase); err != nil {
+		return fmt.Errorf("failed to verify encrypted keys: %v", err)
+	}
+
+	return writeKeyFile(c.Config.KeysPath, encrypted)
+}
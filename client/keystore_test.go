@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device_keys.json")
+	client := &WeatherClient{Config: &Config{KeysPath: path, KeysPassphrase: "correct horse"}}
+	if err := client.generateAndSaveKeys(); err != nil {
+		t.Fatalf("generateAndSaveKeys: %v", err)
+	}
+
+	stored, err := readKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PrivateKey != "" || stored.Crypto == nil {
+		t.Fatalf("key file holds private key %q and crypto %v, want it encrypted", stored.PrivateKey, stored.Crypto)
+	}
+
+	loaded := &WeatherClient{Config: client.Config}
+	if err := loaded.LoadKeys(); err != nil {
+		t.Fatalf("LoadKeys: %v", err)
+	}
+	if !loaded.PrivateKey.Equal(client.PrivateKey) || !bytes.Equal(loaded.DeviceID, client.DeviceID) {
+		t.Errorf("loaded device %x, want %x with the same private key", loaded.DeviceID, client.DeviceID)
+	}
+
+	// Without the passphrase the file cannot be used at all.
+	locked := &WeatherClient{Config: &Config{KeysPath: path}}
+	if err := locked.LoadKeys(); err == nil || !strings.Contains(err.Error(), errKeysEncrypted.Error()) {
+		t.Errorf("LoadKeys without a passphrase error = %v", err)
+	}
+}
+
+func TestKeystoreRejects(t *testing.T) {
+	_, keys, err := newDeviceKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.DeviceID = "abcd"
+	encrypted, err := encryptKeys(keys, "correct horse")
+	if err != nil {
+		t.Fatalf("encryptKeys: %v", err)
+	}
+
+	// tamper returns a copy of the encrypted keys, changed by edit.
+	tamper := func(edit func(keys *DeviceKeys, crypto *EncryptedKey)) DeviceKeys {
+		crypto := *encrypted.Crypto
+		tampered := encrypted
+		tampered.Crypto = &crypto
+		edit(&tampered, &crypto)
+		return tampered
+	}
+	flipped := func(s string) string {
+		b, _ := hex.DecodeString(s)
+		b[0] ^= 1
+		return hex.EncodeToString(b)
+	}
+
+	tests := []struct {
+		name       string
+		keys       DeviceKeys
+		passphrase string
+		wantErr    string
+	}{
+		{"wrong passphrase", encrypted, "battery staple", errWrongPassphrase.Error()},
+		{"corrupted ciphertext", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.Ciphertext = flipped(c.Ciphertext) }), "correct horse", errWrongPassphrase.Error()},
+		{"corrupted salt", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.Salt = flipped(c.KDFParams.Salt) }), "correct horse", errWrongPassphrase.Error()},
+		{"moved to another device", tamper(func(k *DeviceKeys, _ *EncryptedKey) { k.DeviceID = "ef01" }), "correct horse", errWrongPassphrase.Error()},
+		{"N not a power of two", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.N = 1000 }), "correct horse", "must be a power of two"},
+		{"N too large", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.N = 1 << 30 }), "correct horse", "need more than 256 MiB"},
+		{"r too large", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.R = 1 << 20 }), "correct horse", "need more than 256 MiB"},
+		{"p too large", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.P = 1 << 20 }), "correct horse", "exceeds 16"},
+		{"r zero", tamper(func(_ *DeviceKeys, c *EncryptedKey) { c.KDFParams.R = 0 }), "correct horse", "invalid scrypt r"},
+	}
+
+	for _, tt := range tests {
+		if _, err := decryptKeys(tt.keys, tt.passphrase); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: decryptKeys error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if decrypted, err := decryptKeys(encrypted, "correct horse"); err != nil || decrypted.PrivateKey != keys.PrivateKey {
+		t.Errorf("decryptKeys = %q, %v, want the original private key", decrypted.PrivateKey, err)
+	}
+}
+
+func TestUseKeysChecksPublicKey(t *testing.T) {
+	_, keys, err := newDeviceKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := newDeviceKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.DeviceID = "abcd"
+
+	client := &WeatherClient{}
+	if err := client.useKeys(&keys); err != nil {
+		t.Fatalf("useKeys: %v", err)
+	}
+
+	// A key file whose private key belongs to another public key would sign
+	// readings the backend then rejects.
+	keys.PublicKey = other.PublicKey
+	if err := client.useKeys(&keys); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("useKeys with another public key error = %v", err)
+	}
+	if err := client.useKeys(&DeviceKeys{PrivateKey: keys.PrivateKey, DeviceID: "abcd"}); err == nil {
+		t.Error("useKeys accepted keys without a public key")
+	}
+}
@@ -1,54 +1,62 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"time"
+	"strings"
 )
 
 func main() {
-	fmt.Println("Starting Weather Data Client...")
+	name := "run"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	config, err := LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+	if name == "help" {
+		usage()
+		return
 	}
 
-	client, err := NewWeatherClient(config)
-	if err != nil {
-		log.Fatalf("Failed to create client: %v", err)
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "register" {
-		err = client.RegisterDevice()
-		if err != nil {
-			log.Fatalf("Failed to register device: %v", err)
-		}
-		fmt.Println("Device registered successfully!")
-		return
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: client %s [flags] %s\n\n%s\n\nFlags:\n", cmd.Name, cmd.Args, cmd.Summary)
+		flags.PrintDefaults()
 	}
 
-	err = client.LoadOrCreateKeys()
+	config, err := LoadConfig(flags, args, cmd.UsesLocation)
 	if err != nil {
-		log.Fatalf("Failed to load keys: %v", err)
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	ticker := time.NewTicker(time.Duration(config.SubmissionInterval) * time.Second)
-	defer ticker.Stop()
+	if flags.NArg() != cmd.NArgs {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, err := NewWeatherClient(config)
+	if err != nil {
+		log.Fatalf("Failed to create client: %v", err)
+	}
 
-	fmt.Printf("Client running with device ID: %x\n", client.DeviceID)
-	fmt.Printf("Submitting data every %d seconds\n", config.SubmissionInterval)
+	if err := cmd.Run(client, flags.Args()); err != nil {
+		log.Fatalf("%s failed: %v", cmd.Name, err)
+	}
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			err := client.SubmitWeatherData()
-			if err != nil {
-				log.Printf("Failed to submit weather data: %v", err)
-			} else {
-				fmt.Printf("Weather data submitted successfully at %s\n", time.Now().Format(time.RFC3339))
-			}
-		}
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: client <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.Name, cmd.Summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nWithout a command the client runs. Flags override the matching environment\nvariables; see `client <command> -h`.\n")
 }
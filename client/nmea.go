@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func parseNMEA(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "$") {
+		return "", nil, fmt.Errorf("not an NMEA sentence")
+	}
+
+	body := line[1:]
+	if star := strings.LastIndexByte(body, '*'); star >= 0 {
+		expected, err := strconv.ParseUint(body[star+1:], 16, 8)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid checksum field")
+		}
+		body = body[:star]
+
+		var checksum byte
+		for i := 0; i < len(body); i++ {
+			checksum ^= body[i]
+		}
+		if checksum != byte(expected) {
+			return "", nil, fmt.Errorf("checksum mismatch")
+		}
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields[0]) < 5 {
+		return "", nil, fmt.Errorf("invalid sentence address %q", fields[0])
+	}
+
+	// Drop the two character talker ID so $WIMWV and $IIMWV are treated alike.
+	return fields[0][2:], fields[1:], nil
+}
+
+func parseWindSentence(line string) (windSample, bool, error) {
+	kind, fields, err := parseNMEA(line)
+	if err != nil {
+		return windSample{}, false, err
+	}
+
+	switch kind {
+	case "MWV":
+		return parseMWV(fields)
+	case "MDA":
+		return parseMDA(fields)
+	default:
+		return windSample{}, false, nil
+	}
+}
+
+// $--MWV,angle,R|T,speed,K|M|N|S,A|V
+//
+// A relative (R) angle is measured from the sensor's reference mark rather
+// than true north, so the sample is marked Relative for the sensor to correct.
+func parseMWV(fields []string) (windSample, bool, error) {
+	if len(fields) < 5 {
+		return windSample{}, false, fmt.Errorf("MWV sentence has %d fields", len(fields))
+	}
+	if fields[4] != "A" {
+		return windSample{}, false, nil
+	}
+	if fields[1] != "R" && fields[1] != "T" {
+		return windSample{}, false, fmt.Errorf("unknown MWV reference %q", fields[1])
+	}
+
+	bearing, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return windSample{}, false, fmt.Errorf("invalid MWV wind angle %q", fields[0])
+	}
+
+	speed, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return windSample{}, false, fmt.Errorf("invalid MWV wind speed %q", fields[2])
+	}
+
+	switch fields[3] {
+	case "K":
+	case "M":
+		speed *= msToKmh
+	case "N":
+		speed *= knotsToKmh
+	case "S":
+		speed *= mphToKmh
+	default:
+		return windSample{}, false, fmt.Errorf("unknown MWV speed unit %q", fields[3])
+	}
+
+	return windSample{Speed: speed, Bearing: bearing, HasBearing: true, Relative: fields[1] == "R"}, true, nil
+}
+
+// $--MDA,baro,I,baro,B,air,C,water,C,rh,abs,dew,C,dirTrue,T,dirMag,M,knots,N,ms,M
+func parseMDA(fields []string) (windSample, bool, error) {
+	if len(fields) < 20 {
+		return windSample{}, false, fmt.Errorf("MDA sentence has %d fields", len(fields))
+	}
+
+	sample := windSample{}
+	switch {
+	case fields[18] != "":
+		speed, err := strconv.ParseFloat(fields[18], 64)
+		if err != nil {
+			return windSample{}, false, fmt.Errorf("invalid MDA wind speed %q", fields[18])
+		}
+		sample.Speed = speed * msToKmh
+	case fields[16] != "":
+		speed, err := strconv.ParseFloat(fields[16], 64)
+		if err != nil {
+			return windSample{}, false, fmt.Errorf("invalid MDA wind speed %q", fields[16])
+		}
+		sample.Speed = speed * knotsToKmh
+	default:
+		return windSample{}, false, nil
+	}
+
+	for _, i := range []int{12, 14} {
+		if fields[i] == "" {
+			continue
+		}
+		bearing, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return windSample{}, false, fmt.Errorf("invalid MDA wind direction %q", fields[i])
+		}
+		sample.Bearing = bearing
+		sample.HasBearing = true
+		break
+	}
+
+	return sample, true, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestParseWindSentence(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   windSample
+		wantOK bool
+	}{
+		{"MWV metres per second", "$WIMWV,090.0,T,10.0,M,A*1E", windSample{Speed: 36, Bearing: 90, HasBearing: true}, true},
+		{"MWV knots", "$WIMWV,270.0,T,20.0,N,A*12", windSample{Speed: 37.04, Bearing: 270, HasBearing: true}, true},
+		{"MWV miles per hour", "$IIMWV,180.0,T,10.0,S,A*1E", windSample{Speed: 16.09344, Bearing: 180, HasBearing: true}, true},
+		{"MWV kilometres per hour", "$WIMWV,045.0,T,12.5,K,A*17", windSample{Speed: 12.5, Bearing: 45, HasBearing: true}, true},
+		{"MWV relative angle", "$WIMWV,180.0,R,36.0,K,A*1A", windSample{Speed: 36, Bearing: 180, HasBearing: true, Relative: true}, true},
+		{"MWV without checksum", "$WIMWV,090.0,T,10.0,M,A", windSample{Speed: 36, Bearing: 90, HasBearing: true}, true},
+		{"MWV void", "$WIMWV,045.0,R,98.0,K,V*01", windSample{}, false},
+		{"MDA metres per second", "$IIMDA,30.01,I,1.0163,B,18.5,C,,C,65.0,,12.0,C,180.0,T,178.0,M,9.7,N,5.0,M*33", windSample{Speed: 18, Bearing: 180, HasBearing: true}, true},
+		{"MDA knots and magnetic", "$IIMDA,30.01,I,1.0163,B,18.5,C,,C,65.0,,12.0,C,,T,178.0,M,10.0,N,,M*00", windSample{Speed: 18.52, Bearing: 178, HasBearing: true}, true},
+		{"MDA without wind", "$IIMDA,30.01,I,1.0163,B,18.5,C,,C,65.0,,12.0,C,,T,,M,,N,,M*3F", windSample{}, false},
+		{"unrelated sentence", "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", windSample{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseWindSentence(tt.line)
+			if err != nil {
+				t.Fatalf("parseWindSentence(%q) returned error: %v", tt.line, err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("parseWindSentence(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if math.Abs(got.Speed-tt.want.Speed) > 1e-9 || got.Bearing != tt.want.Bearing ||
+				got.HasBearing != tt.want.HasBearing || got.Relative != tt.want.Relative {
+				t.Errorf("parseWindSentence(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWindSentenceRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"checksum mismatch", "$WIMWV,090.0,T,10.0,M,A*00"},
+		{"malformed checksum", "$WIMWV,090.0,T,10.0,M,A*ZZ"},
+		{"missing dollar", "WIMWV,090.0,T,10.0,M,A"},
+		{"unknown reference", "$WIMWV,090.0,X,10.0,M,A*12"},
+		{"unknown unit", "$WIMWV,090.0,T,10.0,Q,A*02"},
+		{"short MWV", "$WIMWV,090.0,T*21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parseWindSentence(tt.line); err == nil {
+				t.Errorf("parseWindSentence(%q) succeeded, want an error", tt.line)
+			}
+		})
+	}
+}
+
+func TestCompassPoint(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		want    string
+	}{
+		{0, "N"},
+		{22.4, "N"},
+		{22.5, "NE"},
+		{90, "E"},
+		{135, "SE"},
+		{180, "S"},
+		{225, "SW"},
+		{270, "W"},
+		{315, "NW"},
+		{337.4, "NW"},
+		{337.5, "N"},
+		{360, "N"},
+		{-90, "W"},
+		{450, "E"},
+	}
+
+	for _, tt := range tests {
+		if got := compassPoint(tt.degrees); got != tt.want {
+			t.Errorf("compassPoint(%v) = %s, want %s", tt.degrees, got, tt.want)
+		}
+	}
+}
+
+// testdata/wind.nmea holds a true 90° reading at 10 m/s and a relative 180°
+// reading at 36 km/h, among a bad checksum, a void reading, a sentence
+// without a leading $ and an unrelated GPS sentence.
+func TestNMEAWindSensorFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		heading     string
+		wantBearing float64
+		wantDir     string
+	}{
+		{"without heading", "", 90, "E"},
+		{"with heading", "0", 135, "SE"},
+		{"with negative heading", "-180", 45, "NE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := url.Values{"file": {"testdata/wind.nmea"}}
+			if tt.heading != "" {
+				options.Set("heading", tt.heading)
+			}
+
+			sensor, err := openNMEAWind(options)
+			if err != nil {
+				t.Fatalf("openNMEAWind: %v", err)
+			}
+			nmea := sensor.(*NMEAWindSensor)
+			<-nmea.done
+
+			var data WeatherData
+			if err := sensor.Read(&data); err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if err := sensor.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if math.Abs(data.WindSpeed-36) > 1e-9 {
+				t.Errorf("WindSpeed = %v, want 36", data.WindSpeed)
+			}
+			if data.WindBearing == nil || math.Abs(*data.WindBearing-tt.wantBearing) > 1e-9 {
+				t.Errorf("WindBearing = %v, want %v", data.WindBearing, tt.wantBearing)
+			}
+			if data.WindDir != tt.wantDir {
+				t.Errorf("WindDir = %s, want %s", data.WindDir, tt.wantDir)
+			}
+		})
+	}
+}
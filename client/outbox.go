@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Outbox is an on-disk FIFO of signed payloads waiting to be delivered. Each
+// payload is stored as its own file named after its sequence number, so
+// directory order is submission order.
+type Outbox struct {
+	dir      string
+	maxBytes int64
+}
+
+type outboxEntry struct {
+	name string
+	size int64
+}
+
+func NewOutbox(dir string, maxBytes int64) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %v", err)
+	}
+	return &Outbox{dir: dir, maxBytes: maxBytes}, nil
+}
+
+func (o *Outbox) Enqueue(payload SubmissionPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%020d.json", payload.WeatherData.Sequence)
+	tmpPath := filepath.Join(o.dir, name+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(o.dir, name)); err != nil {
+		return err
+	}
+
+	return o.evict()
+}
+
+func (o *Outbox) Pending() ([]string, error) {
+	entries, err := o.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.name
+	}
+	return names, nil
+}
+
+func (o *Outbox) Load(name string) (SubmissionPayload, error) {
+	var payload SubmissionPayload
+
+	data, err := os.ReadFile(filepath.Join(o.dir, name))
+	if err != nil {
+		return payload, err
+	}
+	err = json.Unmarshal(data, &payload)
+	return payload, err
+}
+
+func (o *Outbox) Remove(name string) error {
+	err := os.Remove(filepath.Join(o.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// evict drops the oldest readings once the outbox exceeds its size cap. The
+// newest reading is always kept.
+func (o *Outbox) evict() error {
+	if o.maxBytes <= 0 {
+		return nil
+	}
+
+	entries, err := o.entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+
+	evicted := 0
+	for len(entries) > 1 && total > o.maxBytes {
+		if err := o.Remove(entries[0].name); err != nil {
+			return err
+		}
+		total -= entries[0].size
+		entries = entries[1:]
+		evicted++
+	}
+
+	if evicted > 0 {
+		fmt.Printf("Outbox is over %d bytes, dropped %d oldest readings\n", o.maxBytes, evicted)
+	}
+	return nil
+}
+
+func (o *Outbox) entries() ([]outboxEntry, error) {
+	dirEntries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %v", err)
+	}
+
+	entries := make([]outboxEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, outboxEntry{name: dirEntry.Name(), size: info.Size()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries, nil
+}
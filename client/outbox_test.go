@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func queuedPayload(sequence uint64) SubmissionPayload {
+	return SubmissionPayload{WeatherData: WeatherData{DeviceID: "abcd", Sequence: sequence}}
+}
+
+func outboxName(sequence uint64) string {
+	return fmt.Sprintf("%020d.json", sequence)
+}
+
+func TestOutboxOrder(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Names are zero-padded, so sequence 10 sorts after 9.
+	for _, sequence := range []uint64{10, 9, 1} {
+		if err := outbox.Enqueue(queuedPayload(sequence)); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	// A write interrupted before its rename is not a queued reading.
+	if err := os.WriteFile(filepath.Join(outbox.dir, outboxName(2)+".tmp"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := outbox.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{outboxName(1), outboxName(9), outboxName(10)}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("Pending() = %v, want %v", pending, want)
+	}
+
+	payload, err := outbox.Load(pending[0])
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if payload.WeatherData.Sequence != 1 {
+		t.Errorf("loaded sequence %d, want 1", payload.WeatherData.Sequence)
+	}
+
+	if err := outbox.Remove(pending[0]); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := outbox.Remove(pending[0]); err != nil {
+		t.Errorf("removing a reading twice: %v", err)
+	}
+	if pending, _ := outbox.Pending(); len(pending) != 2 {
+		t.Errorf("%d readings pending after a removal, want 2", len(pending))
+	}
+}
+
+func TestOutboxEviction(t *testing.T) {
+	encoded, err := json.Marshal(queuedPayload(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(encoded))
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		want     []string
+	}{
+		{"oldest dropped first", 2*size + size/2, []string{outboxName(3), outboxName(4)}},
+		{"newest always kept", 1, []string{outboxName(4)}},
+		{"no cap", 0, []string{outboxName(1), outboxName(2), outboxName(3), outboxName(4)}},
+	}
+
+	for _, tt := range tests {
+		outbox, err := NewOutbox(t.TempDir(), tt.maxBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for sequence := uint64(1); sequence <= 4; sequence++ {
+			if err := outbox.Enqueue(queuedPayload(sequence)); err != nil {
+				t.Fatalf("%s: Enqueue: %v", tt.name, err)
+			}
+		}
+
+		pending, err := outbox.Pending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pending, tt.want) {
+			t.Errorf("%s: Pending() = %v, want %v", tt.name, pending, tt.want)
+		}
+	}
+}
+
+func TestScheduleRetry(t *testing.T) {
+	client := &WeatherClient{Config: &Config{RetryBaseDelay: 5, RetryMaxDelay: 60}}
+
+	if _, pending := client.RetryIn(); pending {
+		t.Fatal("retry pending before any failure")
+	}
+
+	// Full jitter: each delay is anywhere up to the doubled base, capped.
+	for failures, ceiling := range []time.Duration{5, 10, 20, 40, 60, 60} {
+		client.scheduleRetry()
+		wait, pending := client.RetryIn()
+		if !pending {
+			t.Fatalf("no retry pending after %d failures", failures+1)
+		}
+		if wait < -time.Second || wait > ceiling*time.Second {
+			t.Errorf("after %d failures the retry is in %s, want at most %ds", failures+1, wait, ceiling)
+		}
+	}
+}
+
+func TestFlushOutbox(t *testing.T) {
+	// The backend accepts the first reading, rejects the second for good and
+	// is overloaded for the rest until it recovers.
+	recovered := false
+	var batches [][]SubmissionPayload
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payloads []SubmissionPayload
+		if err := json.NewDecoder(r.Body).Decode(&payloads); err != nil {
+			t.Errorf("undecodable batch: %v", err)
+		}
+		batches = append(batches, payloads)
+
+		results := make([]map[string]int, len(payloads))
+		for i, payload := range payloads {
+			code := http.StatusOK
+			switch {
+			case recovered:
+			case payload.WeatherData.Sequence == 2:
+				code = http.StatusBadRequest
+			case payload.WeatherData.Sequence > 2:
+				code = http.StatusServiceUnavailable
+			}
+			results[i] = map[string]int{"code": code}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer backend.Close()
+
+	outbox, err := NewOutbox(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &WeatherClient{
+		Config: &Config{BackendURL: backend.URL, BatchSize: 2, RetryBaseDelay: 60, RetryMaxDelay: 60},
+		Outbox: outbox,
+		HTTP:   backend.Client(),
+	}
+	for sequence := uint64(1); sequence <= 5; sequence++ {
+		if err := outbox.Enqueue(queuedPayload(sequence)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := client.FlushOutbox(); err == nil {
+		t.Fatal("FlushOutbox succeeded while the backend was overloaded")
+	}
+	pending, err := outbox.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{outboxName(3), outboxName(4), outboxName(5)}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("after the failure Pending() = %v, want %v", pending, want)
+	}
+	if _, pending := client.RetryIn(); !pending {
+		t.Error("no retry scheduled after the failure")
+	}
+
+	// Nothing is sent until the backoff expires.
+	sent := len(batches)
+	if err := client.FlushOutbox(); err == nil || len(batches) != sent {
+		t.Errorf("FlushOutbox during backoff returned %v after %d requests, want an error and none", err, len(batches)-sent)
+	}
+
+	recovered = true
+	client.retryAt = time.Time{}
+	if err := client.FlushOutbox(); err != nil {
+		t.Fatalf("FlushOutbox: %v", err)
+	}
+	if pending, _ := outbox.Pending(); len(pending) != 0 {
+		t.Errorf("%d readings still queued", len(pending))
+	}
+	if _, pending := client.RetryIn(); pending {
+		t.Error("retry still pending after a successful flush")
+	}
+
+	// Two batches of BATCH_SIZE before the failure, then two for the rest.
+	var sizes []int
+	for _, batch := range batches {
+		sizes = append(sizes, len(batch))
+	}
+	if want := []int{2, 2, 2, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"weather-protocol"
+)
+
+type RotationRequest struct {
+	Rotation        protocol.KeyRotation `json:"rotation"`
+	Version         int                  `json:"version"`
+	Signature       string               `json:"signature"`
+	NewKeySignature string               `json:"new_key_signature"`
+}
+
+// RotateKeys replaces the device key while keeping the device ID. The new key
+// is staged in a pending file until the backend has accepted it, so a crash or
+// lost response can be recovered by running the rotation again.
+func (c *WeatherClient) RotateKeys() error {
+	if err := c.LoadKeys(); err != nil {
+		return err
+	}
+
+	// Queued readings are signed with the current key and would be rejected
+	// once it is retired.
+	if pending, err := c.Outbox.Pending(); err != nil {
+		return err
+	} else if len(pending) > 0 {
+		if err := c.FlushOutbox(); err != nil {
+			return fmt.Errorf("queued readings must be delivered before rotating keys: %v", err)
+		}
+	}
+
+	pendingPath := c.Config.KeysPath + ".pending"
+	newKeys, err := c.loadKeys(pendingPath)
+	if errors.Is(err, os.ErrNotExist) {
+		_, keys, err := newDeviceKeys()
+		if err != nil {
+			return err
+		}
+		keys.DeviceID = hex.EncodeToString(c.DeviceID)
+		if err := c.saveKeys(pendingPath, keys); err != nil {
+			return fmt.Errorf("failed to stage new keys: %v", err)
+		}
+		newKeys = &keys
+	} else if err != nil {
+		return fmt.Errorf("failed to load staged keys from %s: %v", pendingPath, err)
+	} else {
+		fmt.Printf("Resuming rotation with staged key from %s\n", pendingPath)
+	}
+
+	newClient := &WeatherClient{Config: c.Config}
+	if err := newClient.useKeys(newKeys); err != nil {
+		return err
+	}
+
+	err = c.sendRotation(newClient, newKeys.PublicKey)
+	if err != nil {
+		// An earlier attempt may have been applied without the response
+		// reaching us, in which case the old key is no longer accepted.
+		if current, lookupErr := c.registeredPublicKey(); lookupErr != nil || current != newKeys.PublicKey {
+			return err
+		}
+	}
+
+	if err := c.saveKeys(c.Config.KeysPath, *newKeys); err != nil {
+		return fmt.Errorf("backend accepted the new key but saving it failed, keep %s: %v", pendingPath, err)
+	}
+	if err := os.Remove(pendingPath); err != nil {
+		return err
+	}
+
+	c.PrivateKey = newClient.PrivateKey
+	c.PublicKey = newClient.PublicKey
+	return nil
+}
+
+func (c *WeatherClient) sendRotation(newClient *WeatherClient, newPublicKey string) error {
+	rotation := protocol.KeyRotation{
+		DeviceID:     hex.EncodeToString(c.DeviceID),
+		NewPublicKey: newPublicKey,
+		Timestamp:    time.Now().UTC(),
+	}
+
+	digest, err := protocol.RotationDigest(rotation, protocol.RotationVersionV1)
+	if err != nil {
+		return fmt.Errorf("failed to encode rotation: %v", err)
+	}
+
+	signature, err := c.signDigest(digest)
+	if err != nil {
+		return fmt.Errorf("failed to sign rotation: %v", err)
+	}
+	newKeySignature, err := newClient.signDigest(digest)
+	if err != nil {
+		return fmt.Errorf("failed to sign rotation with the new key: %v", err)
+	}
+
+	payloadBytes, err := json.Marshal(RotationRequest{
+		Rotation:        rotation,
+		Version:         protocol.RotationVersionV1,
+		Signature:       hex.EncodeToString(signature),
+		NewKeySignature: hex.EncodeToString(newKeySignature),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal rotation: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/devices/%x/rotate", c.Config.BackendURL, c.DeviceID)
+	resp, err := c.HTTP.Post(url, "application/json", bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("failed to send rotation request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("rotation failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (c *WeatherClient) registeredPublicKey() (string, error) {
+	resp, err := c.HTTP.Get(fmt.Sprintf("%s/devices/%x", c.Config.BackendURL, c.DeviceID))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("backend returned status %d", resp.StatusCode)
+	}
+
+	var device struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return "", err
+	}
+	return device.PublicKey, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Sensor interface {
+	Name() string
+	// Measures lists the required fields, by their JSON names, that Read
+	// fills in.
+	Measures() []string
+	Read(data *WeatherData) error
+	Close() error
+}
+
+// PushSensor is implemented by sensors that receive readings rather than
+// measure on demand. The client submits each reading as it arrives instead of
+// at the submission interval.
+type PushSensor interface {
+	Sensor
+	// Arrived signals when readings are waiting.
+	Arrived() <-chan struct{}
+	// Waiting reports whether a reading is waiting to be read.
+	Waiting() bool
+}
+
+func findPushSensor(sensors []Sensor) PushSensor {
+	for _, sensor := range sensors {
+		if push, ok := sensor.(PushSensor); ok {
+			return push
+		}
+	}
+	return nil
+}
+
+// requiredMeasurements are the fields the backend rejects a reading without.
+var requiredMeasurements = []string{"temperature", "humidity", "pressure", "wind_speed", "wind_direction"}
+
+// setOptional sets one of the protocol's optional measurements by name.
+func setOptional(data *WeatherData, field string, value float64) {
+	target := map[string]**float64{
+		"rain_rate":         &data.RainRate,
+		"rain_accumulation": &data.RainAccumulation,
+		"solar_radiation":   &data.SolarRadiation,
+		"uv_index":          &data.UVIndex,
+		"pm2_5":             &data.PM25,
+		"pm10":              &data.PM10,
+		"dew_point":         &data.DewPoint,
+		"wind_gust":         &data.WindGust,
+		"wind_bearing":      &data.WindBearing,
+		"snow_depth":        &data.SnowDepth,
+		"soil_moisture":     &data.SoilMoisture,
+	}[field]
+	if target == nil {
+		panic(fmt.Sprintf("unknown optional field %q", field))
+	}
+	*target = &value
+}
+
+type SensorFactory func(options url.Values) (Sensor, error)
+
+var sensorDrivers = make(map[string]SensorFactory)
+
+func RegisterSensor(driver string, factory SensorFactory) {
+	if _, exists := sensorDrivers[driver]; exists {
+		panic(fmt.Sprintf("sensor driver %q registered twice", driver))
+	}
+	sensorDrivers[driver] = factory
+}
+
+func SensorDrivers() []string {
+	drivers := make([]string, 0, len(sensorDrivers))
+	for driver := range sensorDrivers {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+type sensorEntry struct {
+	Driver  string
+	Options url.Values
+}
+
+// parseSensorSpec splits a comma-separated list of driver?option=value entries
+// and checks that every driver exists.
+func parseSensorSpec(spec string) ([]sensorEntry, error) {
+	entries := make([]sensorEntry, 0)
+	for _, raw := range strings.Split(spec, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		driver, rawOptions, _ := strings.Cut(raw, "?")
+		if _, ok := sensorDrivers[driver]; !ok {
+			return nil, fmt.Errorf("unknown sensor driver %q (available: %s)", driver, strings.Join(SensorDrivers(), ", "))
+		}
+
+		options, err := url.ParseQuery(rawOptions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options for sensor %q: %v", driver, err)
+		}
+		entries = append(entries, sensorEntry{Driver: driver, Options: options})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no sensors configured")
+	}
+	return entries, nil
+}
+
+func OpenSensors(spec string) ([]Sensor, error) {
+	entries, err := parseSensorSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]Sensor, 0, len(entries))
+	for _, entry := range entries {
+		sensor, err := sensorDrivers[entry.Driver](entry.Options)
+		if err != nil {
+			CloseSensors(sensors)
+			return nil, fmt.Errorf("failed to open sensor %q: %v", entry.Driver, err)
+		}
+		sensors = append(sensors, sensor)
+	}
+
+	// A field no sensor measures would go out as zero, which is either
+	// rejected by the backend or, for humidity, a fabricated reading.
+	if missing := missingMeasurements(sensors); len(missing) > 0 {
+		CloseSensors(sensors)
+		return nil, fmt.Errorf("no configured sensor measures %s", strings.Join(missing, ", "))
+	}
+	return sensors, nil
+}
+
+func missingMeasurements(sensors []Sensor) []string {
+	measured := make(map[string]bool)
+	for _, sensor := range sensors {
+		for _, field := range sensor.Measures() {
+			measured[field] = true
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, field := range requiredMeasurements {
+		if !measured[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+func CloseSensors(sensors []Sensor) {
+	for _, sensor := range sensors {
+		if err := sensor.Close(); err != nil {
+			fmt.Printf("Failed to close sensor %s: %v\n", sensor.Name(), err)
+		}
+	}
+}
+
+func (c *WeatherClient) readSensors() (WeatherData, error) {
+	data := WeatherData{
+		DeviceID:  hex.EncodeToString(c.DeviceID),
+		Location:  c.Config.DeviceLocation,
+		Timestamp: time.Now(),
+	}
+
+	for _, sensor := range c.Sensors {
+		if err := sensor.Read(&data); err != nil {
+			return data, fmt.Errorf("sensor %s: %v", sensor.Name(), err)
+		}
+	}
+
+	// A driver that measures direction still has none to report when, for
+	// example, an NMEA anemometer sends relative angles and no heading is
+	// configured. The backend rejects a reading without one.
+	if data.WindDir == "" {
+		return data, fmt.Errorf("no sensor reported a wind direction")
+	}
+	return data, nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	bme280ChipID = 0x60
+	bmp280ChipID = 0x58
+
+	bme280RegCalib00   = 0x88
+	bme280RegCalibH1   = 0xA1
+	bme280RegChipID    = 0xD0
+	bme280RegCalib26   = 0xE1
+	bme280RegCtrlHum   = 0xF2
+	bme280RegStatus    = 0xF3
+	bme280RegCtrlMeas  = 0xF4
+	bme280RegData      = 0xF7
+	bme280StatusBusy   = 0x08
+	bme280CtrlMeasOnce = 0x25 // temperature x1, pressure x1, forced mode
+	bme280CtrlHumX1    = 0x01
+)
+
+func init() {
+	RegisterSensor("bme280", openBME280)
+	RegisterSensor("bmp280", openBME280)
+}
+
+type bme280Calibration struct {
+	T1         uint16
+	T2, T3     int16
+	P1         uint16
+	P2, P3, P4 int16
+	P5, P6, P7 int16
+	P8, P9     int16
+	H1, H3     uint8
+	H2, H4, H5 int16
+	H6         int8
+}
+
+type BME280Sensor struct {
+	bus         I2CBus
+	chipID      byte
+	calibration bme280Calibration
+}
+
+func openBME280(options url.Values) (Sensor, error) {
+	var bus I2CBus
+	var err error
+
+	if dump := options.Get("dump"); dump != "" {
+		bus, err = NewDumpBus(dump)
+	} else {
+		path := options.Get("bus")
+		if path == "" {
+			path = "/dev/i2c-1"
+		}
+
+		address := uint64(0x76)
+		if value := options.Get("address"); value != "" {
+			address, err = strconv.ParseUint(value, 0, 7)
+			if err != nil {
+				return nil, fmt.Errorf("invalid I2C address %q", value)
+			}
+		}
+
+		bus, err = OpenI2CBus(path, uint16(address))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sensor, err := NewBME280(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return sensor, nil
+}
+
+func NewBME280(bus I2CBus) (*BME280Sensor, error) {
+	chipID := make([]byte, 1)
+	if err := bus.ReadReg(bme280RegChipID, chipID); err != nil {
+		return nil, err
+	}
+	if chipID[0] != bme280ChipID && chipID[0] != bmp280ChipID {
+		return nil, fmt.Errorf("unexpected chip ID 0x%02x", chipID[0])
+	}
+
+	sensor := &BME280Sensor{bus: bus, chipID: chipID[0]}
+	if err := sensor.readCalibration(); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+func (s *BME280Sensor) Name() string {
+	if s.chipID == bmp280ChipID {
+		return "bmp280"
+	}
+	return "bme280"
+}
+
+// Measures leaves out humidity for a BMP280, which has no humidity sensor.
+func (s *BME280Sensor) Measures() []string {
+	if s.chipID == bmp280ChipID {
+		return []string{"temperature", "pressure"}
+	}
+	return []string{"temperature", "humidity", "pressure"}
+}
+
+func (s *BME280Sensor) readCalibration() error {
+	raw := make([]byte, 24)
+	if err := s.bus.ReadReg(bme280RegCalib00, raw); err != nil {
+		return fmt.Errorf("failed to read calibration: %v", err)
+	}
+
+	le := binary.LittleEndian
+	c := &s.calibration
+	c.T1 = le.Uint16(raw[0:])
+	c.T2 = int16(le.Uint16(raw[2:]))
+	c.T3 = int16(le.Uint16(raw[4:]))
+	c.P1 = le.Uint16(raw[6:])
+	c.P2 = int16(le.Uint16(raw[8:]))
+	c.P3 = int16(le.Uint16(raw[10:]))
+	c.P4 = int16(le.Uint16(raw[12:]))
+	c.P5 = int16(le.Uint16(raw[14:]))
+	c.P6 = int16(le.Uint16(raw[16:]))
+	c.P7 = int16(le.Uint16(raw[18:]))
+	c.P8 = int16(le.Uint16(raw[20:]))
+	c.P9 = int16(le.Uint16(raw[22:]))
+
+	if s.chipID != bme280ChipID {
+		return nil
+	}
+
+	h1 := make([]byte, 1)
+	if err := s.bus.ReadReg(bme280RegCalibH1, h1); err != nil {
+		return fmt.Errorf("failed to read humidity calibration: %v", err)
+	}
+	c.H1 = h1[0]
+
+	hum := make([]byte, 7)
+	if err := s.bus.ReadReg(bme280RegCalib26, hum); err != nil {
+		return fmt.Errorf("failed to read humidity calibration: %v", err)
+	}
+	c.H2 = int16(le.Uint16(hum[0:]))
+	c.H3 = hum[2]
+	c.H4 = int16(int8(hum[3]))<<4 | int16(hum[4]&0x0F)
+	c.H5 = int16(int8(hum[5]))<<4 | int16(hum[4]>>4)
+	c.H6 = int8(hum[6])
+	return nil
+}
+
+func (s *BME280Sensor) Read(data *WeatherData) error {
+	if s.chipID == bme280ChipID {
+		if err := s.bus.WriteReg(bme280RegCtrlHum, bme280CtrlHumX1); err != nil {
+			return err
+		}
+	}
+	if err := s.bus.WriteReg(bme280RegCtrlMeas, bme280CtrlMeasOnce); err != nil {
+		return err
+	}
+
+	if err := s.waitForMeasurement(); err != nil {
+		return err
+	}
+
+	raw := make([]byte, 8)
+	if s.chipID != bme280ChipID {
+		raw = raw[:6]
+	}
+	if err := s.bus.ReadReg(bme280RegData, raw); err != nil {
+		return err
+	}
+
+	adcP := int32(raw[0])<<12 | int32(raw[1])<<4 | int32(raw[2])>>4
+	adcT := int32(raw[3])<<12 | int32(raw[4])<<4 | int32(raw[5])>>4
+
+	temperature, tFine := s.calibration.compensateTemperature(adcT)
+	data.Temperature = temperature
+	data.Pressure = s.calibration.compensatePressure(adcP, tFine) / 100
+
+	if s.chipID == bme280ChipID {
+		adcH := int32(raw[6])<<8 | int32(raw[7])
+		data.Humidity = s.calibration.compensateHumidity(adcH, tFine)
+	}
+	return nil
+}
+
+func (s *BME280Sensor) waitForMeasurement() error {
+	status := make([]byte, 1)
+	for i := 0; i < 20; i++ {
+		if err := s.bus.ReadReg(bme280RegStatus, status); err != nil {
+			return err
+		}
+		if status[0]&bme280StatusBusy == 0 {
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return fmt.Errorf("measurement did not complete")
+}
+
+func (s *BME280Sensor) Close() error {
+	return s.bus.Close()
+}
+
+// The compensation formulas are the floating point versions from section 8.1
+// of the BME280 datasheet. They return °C, Pa and %RH.
+
+func (c *bme280Calibration) compensateTemperature(adcT int32) (float64, float64) {
+	var1 := (float64(adcT)/16384.0 - float64(c.T1)/1024.0) * float64(c.T2)
+	var2 := float64(adcT)/131072.0 - float64(c.T1)/8192.0
+	var2 = var2 * var2 * float64(c.T3)
+	tFine := var1 + var2
+	return tFine / 5120.0, tFine
+}
+
+func (c *bme280Calibration) compensatePressure(adcP int32, tFine float64) float64 {
+	var1 := tFine/2.0 - 64000.0
+	var2 := var1 * var1 * float64(c.P6) / 32768.0
+	var2 = var2 + var1*float64(c.P5)*2.0
+	var2 = var2/4.0 + float64(c.P4)*65536.0
+	var1 = (float64(c.P3)*var1*var1/524288.0 + float64(c.P2)*var1) / 524288.0
+	var1 = (1.0 + var1/32768.0) * float64(c.P1)
+	if var1 == 0 {
+		return 0
+	}
+
+	p := 1048576.0 - float64(adcP)
+	p = (p - var2/4096.0) * 6250.0 / var1
+	var1 = float64(c.P9) * p * p / 2147483648.0
+	var2 = p * float64(c.P8) / 32768.0
+	return p + (var1+var2+float64(c.P7))/16.0
+}
+
+func (c *bme280Calibration) compensateHumidity(adcH int32, tFine float64) float64 {
+	h := tFine - 76800.0
+	h = (float64(adcH) - (float64(c.H4)*64.0 + float64(c.H5)/16384.0*h)) *
+		(float64(c.H2) / 65536.0 * (1.0 + float64(c.H6)/67108864.0*h*(1.0+float64(c.H3)/67108864.0*h)))
+	h = h * (1.0 - float64(c.H1)*h/524288.0)
+
+	if h > 100 {
+		return 100
+	}
+	if h < 0 {
+		return 0
+	}
+	return h
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+// testdata/bme280.dump is i2cdump output holding the calibration and ADC
+// values of the worked example in section 3.12 of the BMP280 datasheet
+// (adc_T 519888, adc_P 415148), which the BME280 shares, plus humidity
+// calibration and adc_H 27000.
+const bme280Dump = "testdata/bme280.dump"
+
+func TestBME280Dump(t *testing.T) {
+	sensor, err := openBME280(url.Values{"dump": {bme280Dump}})
+	if err != nil {
+		t.Fatalf("openBME280: %v", err)
+	}
+	defer sensor.Close()
+
+	if name := sensor.Name(); name != "bme280" {
+		t.Errorf("Name() = %s, want bme280", name)
+	}
+
+	var data WeatherData
+	if err := sensor.Read(&data); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	// The datasheet gives 25.08 °C and 100653.27 Pa. The humidity is what the
+	// datasheet's integer compensation (section 4.2.3) gives for the same
+	// registers, 44540/1024 %RH.
+	checkClose(t, "temperature", data.Temperature, 25.08, 0.005)
+	checkClose(t, "pressure", data.Pressure, 1006.5327, 0.0005)
+	checkClose(t, "humidity", data.Humidity, 44540.0/1024, 0.01)
+
+	bus := sensor.(*BME280Sensor).bus.(*DumpBus)
+	if got := bus.registers[bme280RegCtrlHum]; got != bme280CtrlHumX1 {
+		t.Errorf("ctrl_hum = 0x%02x, want 0x%02x", got, bme280CtrlHumX1)
+	}
+	if got := bus.registers[bme280RegCtrlMeas]; got != bme280CtrlMeasOnce {
+		t.Errorf("ctrl_meas = 0x%02x, want 0x%02x", got, bme280CtrlMeasOnce)
+	}
+}
+
+func TestBMP280Dump(t *testing.T) {
+	bus, err := NewDumpBus(bme280Dump)
+	if err != nil {
+		t.Fatalf("NewDumpBus: %v", err)
+	}
+	bus.WriteReg(bme280RegChipID, bmp280ChipID)
+
+	sensor, err := NewBME280(bus)
+	if err != nil {
+		t.Fatalf("NewBME280: %v", err)
+	}
+	if name := sensor.Name(); name != "bmp280" {
+		t.Errorf("Name() = %s, want bmp280", name)
+	}
+
+	data := WeatherData{Humidity: -1}
+	if err := sensor.Read(&data); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	checkClose(t, "temperature", data.Temperature, 25.08, 0.005)
+	checkClose(t, "pressure", data.Pressure, 1006.5327, 0.0005)
+	if data.Humidity != -1 {
+		t.Errorf("humidity = %v, want it left unset", data.Humidity)
+	}
+}
+
+func TestBME280RejectsUnknownChip(t *testing.T) {
+	bus, err := NewDumpBus(bme280Dump)
+	if err != nil {
+		t.Fatalf("NewDumpBus: %v", err)
+	}
+	bus.WriteReg(bme280RegChipID, 0x55)
+
+	if _, err := NewBME280(bus); err == nil {
+		t.Error("NewBME280 accepted chip ID 0x55")
+	}
+}
+
+func checkClose(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %v, want %v ± %v", name, got, want, tolerance)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func init() {
+	RegisterSensor("cup", openCupAnemometer)
+}
+
+type PulseCounter interface {
+	Pulses() uint64
+	Close() error
+}
+
+// CupAnemometer converts the pulse rate of a reed switch anemometer into wind
+// speed. It does not measure direction, so another driver has to.
+type CupAnemometer struct {
+	counter    PulseCounter
+	kmhPerHz   float64
+	lastPulses uint64
+	lastRead   time.Time
+}
+
+func openCupAnemometer(options url.Values) (Sensor, error) {
+	pin, err := strconv.Atoi(options.Get("pin"))
+	if err != nil {
+		return nil, fmt.Errorf("a numeric GPIO pin option is required")
+	}
+
+	kmhPerHz := 2.4
+	if value := options.Get("factor"); value != "" {
+		if kmhPerHz, err = strconv.ParseFloat(value, 64); err != nil || kmhPerHz <= 0 {
+			return nil, fmt.Errorf("invalid factor %q", value)
+		}
+	}
+
+	edge := options.Get("edge")
+	if edge == "" {
+		edge = "falling"
+	}
+
+	debounce := time.Millisecond
+	if value := options.Get("debounce"); value != "" {
+		if debounce, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid debounce %q", value)
+		}
+	}
+
+	counter, err := OpenGPIOPulseCounter(pin, edge, debounce)
+	if err != nil {
+		return nil, err
+	}
+	return NewCupAnemometer(counter, kmhPerHz), nil
+}
+
+func NewCupAnemometer(counter PulseCounter, kmhPerHz float64) *CupAnemometer {
+	return &CupAnemometer{
+		counter:    counter,
+		kmhPerHz:   kmhPerHz,
+		lastPulses: counter.Pulses(),
+		lastRead:   time.Now(),
+	}
+}
+
+func (a *CupAnemometer) Name() string {
+	return "cup"
+}
+
+func (a *CupAnemometer) Measures() []string {
+	return []string{"wind_speed"}
+}
+
+func (a *CupAnemometer) Read(data *WeatherData) error {
+	now := time.Now()
+	pulses := a.counter.Pulses()
+
+	elapsed := now.Sub(a.lastRead).Seconds()
+	if elapsed <= 0 {
+		return fmt.Errorf("no time has passed since the last read")
+	}
+
+	data.WindSpeed = float64(pulses-a.lastPulses) / elapsed * a.kmhPerHz
+	a.lastPulses = pulses
+	a.lastRead = now
+	return nil
+}
+
+func (a *CupAnemometer) Close() error {
+	return a.counter.Close()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"net/url"
+	"time"
+)
+
+func init() {
+	RegisterSensor("mock", func(options url.Values) (Sensor, error) {
+		return &MockSensor{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}, nil
+	})
+}
+
+type MockSensor struct {
+	rng *rand.Rand
+}
+
+func (s *MockSensor) Name() string {
+	return "mock"
+}
+
+func (s *MockSensor) Measures() []string {
+	return requiredMeasurements
+}
+
+// Read follows daily and multi-day cycles with a little noise, so
+// consecutive readings, even from separate runs, change as gradually as a
+// real station's would.
+func (s *MockSensor) Read(data *WeatherData) error {
+	now := float64(time.Now().Unix())
+	cycle := func(period time.Duration) float64 {
+		return math.Sin(2 * math.Pi * now / period.Seconds())
+	}
+
+	data.Temperature = 20.0 + 6.0*cycle(24*time.Hour) + s.rng.NormFloat64()*0.1
+	data.Humidity = 55.0 - 15.0*cycle(24*time.Hour) + s.rng.NormFloat64()*0.5
+	data.Pressure = 1013.0 + 8.0*cycle(72*time.Hour) + s.rng.NormFloat64()*0.1
+	data.WindSpeed = math.Max(0, 12.0+6.0*cycle(6*time.Hour)+s.rng.NormFloat64()*2.0)
+	data.WindDir = compassPoint(180 + 150*cycle(36*time.Hour) + s.rng.NormFloat64()*10)
+	return nil
+}
+
+func (s *MockSensor) Close() error {
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+func init() {
+	RegisterSensor("nmea", openNMEAWind)
+}
+
+// NMEAWindSensor averages wind sentences from a serial port or file. heading
+// is the true bearing of the sensor's reference mark, used to turn relative
+// angles into true bearings; without it relative angles are dropped and only
+// the speed is reported, which leaves the reading without a direction unless
+// a true angle arrived in the same interval.
+type NMEAWindSensor struct {
+	source  io.ReadCloser
+	maxAge  time.Duration
+	heading *float64
+	average windAverager
+	done    chan struct{}
+}
+
+func openNMEAWind(options url.Values) (Sensor, error) {
+	maxAge := 60 * time.Second
+
+	var source io.ReadCloser
+	if path := options.Get("file"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sentence file: %v", err)
+		}
+		source = file
+		maxAge = 0
+	} else {
+		port := options.Get("port")
+		if port == "" {
+			port = "/dev/ttyUSB0"
+		}
+
+		baud := 4800
+		if value := options.Get("baud"); value != "" {
+			var err error
+			if baud, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid baud rate %q", value)
+			}
+		}
+
+		serial, err := OpenSerialPort(port, baud)
+		if err != nil {
+			return nil, err
+		}
+		source = serial
+	}
+
+	if value := options.Get("max_age"); value != "" {
+		age, err := time.ParseDuration(value)
+		if err != nil {
+			source.Close()
+			return nil, fmt.Errorf("invalid max_age %q", value)
+		}
+		maxAge = age
+	}
+
+	var heading *float64
+	if value := options.Get("heading"); value != "" {
+		degrees, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			source.Close()
+			return nil, fmt.Errorf("invalid heading %q", value)
+		}
+		heading = &degrees
+	}
+
+	return NewNMEAWindSensor(source, maxAge, heading), nil
+}
+
+func NewNMEAWindSensor(source io.ReadCloser, maxAge time.Duration, heading *float64) *NMEAWindSensor {
+	sensor := &NMEAWindSensor{source: source, maxAge: maxAge, heading: heading, done: make(chan struct{})}
+	go sensor.listen()
+	return sensor
+}
+
+func (s *NMEAWindSensor) listen() {
+	defer close(s.done)
+
+	scanner := bufio.NewScanner(s.source)
+	for scanner.Scan() {
+		sample, ok, err := parseWindSentence(scanner.Text())
+		if err != nil {
+			log.Printf("Ignoring NMEA sentence %q: %v", scanner.Text(), err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if sample.Relative {
+			if s.heading != nil {
+				sample.Bearing = math.Mod(math.Mod(sample.Bearing+*s.heading, 360)+360, 360)
+			} else {
+				sample.HasBearing = false
+			}
+			sample.Relative = false
+		}
+		s.average.add(sample)
+	}
+}
+
+func (s *NMEAWindSensor) Name() string {
+	return "nmea"
+}
+
+func (s *NMEAWindSensor) Measures() []string {
+	return []string{"wind_speed", "wind_direction"}
+}
+
+func (s *NMEAWindSensor) Read(data *WeatherData) error {
+	sample, ok := s.average.take(s.maxAge)
+	if !ok {
+		return fmt.Errorf("no recent wind sentences received")
+	}
+
+	data.WindSpeed = sample.Speed
+	if sample.HasBearing {
+		data.WindDir = compassPoint(sample.Bearing)
+		bearing := sample.Bearing
+		data.WindBearing = &bearing
+	}
+	return nil
+}
+
+func (s *NMEAWindSensor) Close() error {
+	err := s.source.Close()
+	<-s.done
+	return err
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	inHgToHPa = 33.8639
+	inchToMM  = 25.4
+
+	// pushMaxPending bounds the uploads waiting to be read if the client
+	// cannot keep up; the oldest are dropped first.
+	pushMaxPending = 1000
+
+	// pushDateLayout is the dateutc format of both upload protocols.
+	pushDateLayout = "2006-01-02 15:04:05"
+)
+
+func init() {
+	RegisterSensor("push", openPushListener)
+}
+
+// PushListener accepts readings that consumer stations upload to a "custom
+// server": the Weather Underground updateweatherstation.php GET format and the
+// Ecowitt / Ambient Weather form format. Every accepted upload is read once,
+// in the order it arrived.
+type PushListener struct {
+	server  *http.Server
+	station string
+	maxAge  time.Duration
+	arrived chan struct{}
+
+	pending []pushReading
+	mu      sync.Mutex
+}
+
+type pushReading struct {
+	// Timestamp is the station's dateutc, or when the upload arrived if the
+	// station did not send one.
+	Timestamp time.Time
+	Received  time.Time
+
+	Temperature float64
+	Humidity    float64
+	Pressure    float64
+	WindSpeed   *float64
+	WindDir     string
+
+	// Optional measurements, already converted to the protocol's units.
+	Optional map[string]float64
+}
+
+// pushOptionalFields maps upload parameters to optional protocol fields. The
+// first parameter present wins, and scale converts it to the protocol's unit.
+var pushOptionalFields = []struct {
+	field  string
+	params []string
+	scale  float64
+}{
+	{"rain_rate", []string{"rainratein", "hourlyrainin", "rainin"}, inchToMM},
+	{"rain_accumulation", []string{"dailyrainin"}, inchToMM},
+	{"solar_radiation", []string{"solarradiation"}, 1},
+	{"uv_index", []string{"uv", "UV"}, 1},
+	{"pm2_5", []string{"pm25_ch1", "pm25", "AqPM2.5"}, 1},
+	{"pm10", []string{"pm10_co2", "pm10", "AqPM10"}, 1},
+	{"wind_gust", []string{"windgustmph"}, mphToKmh},
+	{"wind_bearing", []string{"winddir", "winddir_avg2m", "winddir_avg10m"}, 1},
+	{"soil_moisture", []string{"soilmoisture1", "soilhum1"}, 1},
+}
+
+func openPushListener(options url.Values) (Sensor, error) {
+	address := options.Get("listen")
+	if address == "" {
+		address = ":8081"
+	}
+
+	maxAge := 5 * time.Minute
+	if value := options.Get("max_age"); value != "" {
+		var err error
+		if maxAge, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid max_age %q", value)
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+
+	push := NewPushListener(options.Get("station"), maxAge)
+	push.server = &http.Server{Handler: push, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := push.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Push listener stopped: %v", err)
+		}
+	}()
+
+	fmt.Printf("Listening for station uploads on %s\n", listener.Addr())
+	return push, nil
+}
+
+func NewPushListener(station string, maxAge time.Duration) *PushListener {
+	return &PushListener{station: station, maxAge: maxAge, arrived: make(chan struct{}, 1)}
+}
+
+func (p *PushListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	if p.station != "" && r.Form.Get("ID") != p.station && r.Form.Get("PASSKEY") != p.station {
+		http.Error(w, "unknown station", http.StatusForbidden)
+		return
+	}
+
+	reading, err := parsePushReading(r.Form, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	if len(p.pending) >= pushMaxPending {
+		log.Printf("Dropping station upload from %s: too many waiting", p.pending[0].Timestamp.Format(time.RFC3339))
+		p.pending = p.pending[1:]
+	}
+	p.pending = append(p.pending, reading)
+	p.mu.Unlock()
+
+	select {
+	case p.arrived <- struct{}{}:
+	default:
+	}
+
+	// Weather Underground firmware checks for this exact body.
+	fmt.Fprintln(w, "success")
+}
+
+// parsePushReading converts an upload received at the given time. Uploads
+// without temperature, humidity or pressure are rejected, since the backend
+// requires all three.
+func parsePushReading(form url.Values, received time.Time) (pushReading, error) {
+	reading := pushReading{Timestamp: received, Received: received}
+
+	// Weather Underground stations may send "now" instead of a time.
+	if raw := form.Get("dateutc"); raw != "" && raw != "now" {
+		timestamp, err := time.Parse(pushDateLayout, raw)
+		if err != nil {
+			return reading, fmt.Errorf("invalid dateutc %q", raw)
+		}
+		reading.Timestamp = timestamp
+	}
+
+	required := []struct {
+		name   string
+		target *float64
+		fields []string
+	}{
+		{"tempf", &reading.Temperature, []string{"tempf"}},
+		{"humidity", &reading.Humidity, []string{"humidity"}},
+		{"baromin", &reading.Pressure, []string{"baromrelin", "baromin", "baromabsin"}},
+	}
+	for _, field := range required {
+		value, err := formFloat(form, field.fields...)
+		if err != nil {
+			return reading, err
+		}
+		if value == nil {
+			return reading, fmt.Errorf("missing %s", field.name)
+		}
+		*field.target = *value
+	}
+	reading.Temperature = (reading.Temperature - 32) * 5 / 9
+	reading.Pressure *= inHgToHPa
+
+	var err error
+
+	if reading.WindSpeed, err = formFloat(form, "windspeedmph", "windspdmph_avg2m", "windspdmph_avg10m"); err != nil {
+		return reading, err
+	}
+	if reading.WindSpeed != nil {
+		kmh := *reading.WindSpeed * mphToKmh
+		reading.WindSpeed = &kmh
+	}
+
+	direction, err := formFloat(form, "winddir", "winddir_avg2m", "winddir_avg10m")
+	if err != nil {
+		return reading, err
+	}
+	if direction != nil {
+		reading.WindDir = compassPoint(*direction)
+	}
+
+	reading.Optional = make(map[string]float64)
+	for _, optional := range pushOptionalFields {
+		value, err := formFloat(form, optional.params...)
+		if err != nil {
+			return reading, err
+		}
+		if value != nil {
+			reading.Optional[optional.field] = *value * optional.scale
+		}
+	}
+
+	dewPoint, err := formFloat(form, "dewptf")
+	if err != nil {
+		return reading, err
+	}
+	if dewPoint != nil {
+		reading.Optional["dew_point"] = (*dewPoint - 32) * 5 / 9
+	}
+	if bearing, ok := reading.Optional["wind_bearing"]; ok && bearing >= 360 {
+		reading.Optional["wind_bearing"] = math.Mod(bearing, 360)
+	}
+
+	return reading, nil
+}
+
+// formFloat returns the first of the given fields that is present. Stations
+// send -9999 for sensors that are not connected, which is treated as absent.
+func formFloat(form url.Values, fields ...string) (*float64, error) {
+	for _, field := range fields {
+		raw := form.Get(field)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", field, raw)
+		}
+		if value <= -9999 {
+			continue
+		}
+		return &value, nil
+	}
+	return nil, nil
+}
+
+func (p *PushListener) Name() string {
+	return "push"
+}
+
+func (p *PushListener) Measures() []string {
+	return requiredMeasurements
+}
+
+// Arrived signals when uploads are waiting to be read.
+func (p *PushListener) Arrived() <-chan struct{} {
+	return p.arrived
+}
+
+// Waiting reports whether an upload is waiting to be read.
+func (p *PushListener) Waiting() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending) > 0
+}
+
+// Read takes the oldest upload not yet read. Uploads that waited longer than
+// max_age are dropped.
+func (p *PushListener) Read(data *WeatherData) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.pending) > 0 && time.Since(p.pending[0].Received) > p.maxAge {
+		log.Printf("Dropping station upload from %s: not read within %s", p.pending[0].Timestamp.Format(time.RFC3339), p.maxAge)
+		p.pending = p.pending[1:]
+	}
+	if len(p.pending) == 0 {
+		return fmt.Errorf("no station upload waiting")
+	}
+
+	reading := p.pending[0]
+	p.pending = p.pending[1:]
+
+	data.Timestamp = reading.Timestamp
+	data.Temperature = reading.Temperature
+	data.Humidity = reading.Humidity
+	data.Pressure = reading.Pressure
+	if reading.WindSpeed != nil {
+		data.WindSpeed = *reading.WindSpeed
+	}
+	if reading.WindDir != "" {
+		data.WindDir = reading.WindDir
+	}
+	for field, value := range reading.Optional {
+		setOptional(data, field, value)
+	}
+	return nil
+}
+
+func (p *PushListener) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePushReading(t *testing.T) {
+	received := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := "tempf=68&humidity=50&baromin=29.92"
+
+	tests := []struct {
+		name     string
+		query    string
+		check    func(pushReading) bool
+		describe string
+	}{
+		{"°F to °C", base, func(r pushReading) bool { return near(r.Temperature, 20) }, "temperature 20 °C"},
+		{"inHg to hPa", base, func(r pushReading) bool { return near(r.Pressure, 1013.207888) }, "pressure 1013.207888 hPa"},
+		{"humidity as sent", base, func(r pushReading) bool { return r.Humidity == 50 }, "humidity 50 %"},
+		{"relative pressure preferred", "tempf=68&humidity=50&baromin=29&baromrelin=30",
+			func(r pushReading) bool { return near(r.Pressure, 30*inHgToHPa) }, "pressure from baromrelin"},
+		{"mph to km/h", base + "&windspeedmph=10&windgustmph=20", func(r pushReading) bool {
+			return r.WindSpeed != nil && near(*r.WindSpeed, 16.09344) && near(r.Optional["wind_gust"], 32.18688)
+		}, "wind 16.09344 km/h gusting 32.18688"},
+		{"in to mm", base + "&rainratein=0.1&dailyrainin=0.5", func(r pushReading) bool {
+			return near(r.Optional["rain_rate"], 2.54) && near(r.Optional["rain_accumulation"], 12.7)
+		}, "rain 2.54 mm/h, 12.7 mm today"},
+		{"dew point °F to °C", base + "&dewptf=50", func(r pushReading) bool { return near(r.Optional["dew_point"], 10) }, "dew point 10 °C"},
+		{"bearing to compass point", base + "&winddir=225", func(r pushReading) bool {
+			return r.WindDir == "SW" && r.Optional["wind_bearing"] == 225
+		}, "wind from SW at 225°"},
+		{"bearing wrapped", base + "&winddir=370", func(r pushReading) bool {
+			return r.WindDir == "N" && near(r.Optional["wind_bearing"], 10)
+		}, "wind from N at 10°"},
+		{"-9999 is absent", base + "&windspeedmph=-9999&solarradiation=-9999&uv=3", func(r pushReading) bool {
+			_, solar := r.Optional["solar_radiation"]
+			return r.WindSpeed == nil && !solar && r.Optional["uv_index"] == 3
+		}, "no wind speed or solar radiation"},
+		{"dateutc", base + "&dateutc=2024-06-01+12:30:00", func(r pushReading) bool {
+			return r.Timestamp.Equal(time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)) && r.Received.Equal(received)
+		}, "timestamp 2024-06-01 12:30:00 UTC"},
+		{"dateutc now", base + "&dateutc=now", func(r pushReading) bool { return r.Timestamp.Equal(received) }, "timestamp when received"},
+		{"no dateutc", base, func(r pushReading) bool { return r.Timestamp.Equal(received) }, "timestamp when received"},
+	}
+
+	for _, tt := range tests {
+		form, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reading, err := parsePushReading(form, received)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !tt.check(reading) {
+			t.Errorf("%s: got %+v, want %s", tt.name, reading, tt.describe)
+		}
+	}
+}
+
+func TestParsePushReadingRejects(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{"humidity=50&baromin=29.92", "missing tempf"},
+		{"tempf=-9999&humidity=50&baromin=29.92", "missing tempf"},
+		{"tempf=68&baromin=29.92", "missing humidity"},
+		{"tempf=68&humidity=50", "missing baromin"},
+		{"tempf=warm&humidity=50&baromin=29.92", `invalid tempf "warm"`},
+		{"tempf=68&humidity=50&baromin=29.92&dateutc=yesterday", `invalid dateutc "yesterday"`},
+	}
+
+	for _, tt := range tests {
+		form, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parsePushReading(form, time.Now()); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("parsePushReading(%s) error = %v, want %q", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPushListener(t *testing.T) {
+	push := NewPushListener("KSTATION1", time.Minute)
+
+	upload := func(request *http.Request) int {
+		recorder := httptest.NewRecorder()
+		push.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// A Weather Underground GET upload, then an Ecowitt POST.
+	wunderground := httptest.NewRequest(http.MethodGet,
+		"/weatherstation/updateweatherstation.php?ID=KSTATION1&dateutc=2025-01-02+03:04:05&tempf=68&humidity=50&baromin=29.92&windspeedmph=10&winddir=90", nil)
+	if code := upload(wunderground); code != http.StatusOK {
+		t.Fatalf("Weather Underground upload returned %d", code)
+	}
+
+	select {
+	case <-push.Arrived():
+	default:
+		t.Fatal("Arrived did not fire for an accepted upload")
+	}
+
+	ecowitt := httptest.NewRequest(http.MethodPost, "/data/report/",
+		strings.NewReader("PASSKEY=KSTATION1&dateutc=2025-01-02+03:05:05&tempf=50&humidity=60&baromrelin=30&windspeedmph=5&winddir=180"))
+	ecowitt.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if code := upload(ecowitt); code != http.StatusOK {
+		t.Fatalf("Ecowitt upload returned %d", code)
+	}
+
+	rejected := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"another station", "/?ID=KOTHER&tempf=68&humidity=50&baromin=29.92", http.StatusForbidden},
+		{"missing humidity", "/?ID=KSTATION1&tempf=68&baromin=29.92", http.StatusBadRequest},
+	}
+	for _, tt := range rejected {
+		if code := upload(httptest.NewRequest(http.MethodGet, tt.target, nil)); code != tt.want {
+			t.Errorf("%s: upload returned %d, want %d", tt.name, code, tt.want)
+		}
+	}
+
+	// Each accepted upload is read once, in order, at the station's time.
+	for _, want := range []struct {
+		timestamp   time.Time
+		temperature float64
+		windDir     string
+	}{
+		{time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), 20, "E"},
+		{time.Date(2025, 1, 2, 3, 5, 5, 0, time.UTC), 10, "S"},
+	} {
+		if !push.Waiting() {
+			t.Fatal("no upload waiting")
+		}
+		var data WeatherData
+		if err := push.Read(&data); err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if !data.Timestamp.Equal(want.timestamp) || !near(data.Temperature, want.temperature) || data.WindDir != want.windDir {
+			t.Errorf("read %v °C from %s at %v, want %v °C from %s at %v",
+				data.Temperature, data.WindDir, data.Timestamp, want.temperature, want.windDir, want.timestamp)
+		}
+	}
+
+	if push.Waiting() {
+		t.Error("upload still waiting after both were read")
+	}
+	if err := push.Read(&WeatherData{}); err == nil {
+		t.Error("Read succeeded with no upload waiting")
+	}
+}
+
+func TestPushListenerDropsStaleUploads(t *testing.T) {
+	push := NewPushListener("", time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	push.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?tempf=68&humidity=50&baromin=29.92", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("upload returned %d", recorder.Code)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if err := push.Read(&WeatherData{}); err == nil {
+		t.Error("Read returned an upload older than max_age")
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSensor sets the temperature and, if it has one, the wind direction to
+// its values, or fails, and records whether it was closed.
+type fakeSensor struct {
+	name        string
+	temperature float64
+	windDir     string
+	err         error
+	closed      bool
+}
+
+func (s *fakeSensor) Name() string { return s.name }
+
+func (s *fakeSensor) Measures() []string { return requiredMeasurements }
+
+func (s *fakeSensor) Read(data *WeatherData) error {
+	if s.err != nil {
+		return s.err
+	}
+	data.Temperature = s.temperature
+	if s.windDir != "" {
+		data.WindDir = s.windDir
+	}
+	return nil
+}
+
+func (s *fakeSensor) Close() error {
+	s.closed = true
+	return nil
+}
+
+// registerTestSensor adds a driver for the duration of the test.
+func registerTestSensor(t *testing.T, driver string, factory SensorFactory) {
+	t.Helper()
+	RegisterSensor(driver, factory)
+	t.Cleanup(func() { delete(sensorDrivers, driver) })
+}
+
+func TestParseSensorSpec(t *testing.T) {
+	entries, err := parseSensorSpec(" mock , mock?seed=1&unit=c,")
+	if err != nil {
+		t.Fatalf("parseSensorSpec: %v", err)
+	}
+	want := []sensorEntry{
+		{Driver: "mock", Options: url.Values{}},
+		{Driver: "mock", Options: url.Values{"seed": {"1"}, "unit": {"c"}}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+
+	for spec, wantErr := range map[string]string{
+		"":              "no sensors configured",
+		" , ":           "no sensors configured",
+		"thermocouple":  `unknown sensor driver "thermocouple"`,
+		"mock?seed=%zz": `invalid options for sensor "mock"`,
+	} {
+		if _, err := parseSensorSpec(spec); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseSensorSpec(%q) error = %v, want %q", spec, err, wantErr)
+		}
+	}
+}
+
+func TestOpenSensorsClosesOnFailure(t *testing.T) {
+	var opened []*fakeSensor
+	registerTestSensor(t, "fake", func(options url.Values) (Sensor, error) {
+		sensor := &fakeSensor{name: "fake"}
+		opened = append(opened, sensor)
+		return sensor, nil
+	})
+	registerTestSensor(t, "broken", func(options url.Values) (Sensor, error) {
+		return nil, errors.New("no such device")
+	})
+
+	if _, err := OpenSensors("fake,fake,broken"); err == nil || !strings.Contains(err.Error(), `failed to open sensor "broken"`) {
+		t.Fatalf("OpenSensors error = %v, want the broken driver's", err)
+	}
+	if len(opened) != 2 {
+		t.Fatalf("opened %d sensors before the failure, want 2", len(opened))
+	}
+	for i, sensor := range opened {
+		if !sensor.closed {
+			t.Errorf("sensor %d was left open", i)
+		}
+	}
+}
+
+func TestReadSensors(t *testing.T) {
+	client := &WeatherClient{
+		Config:   &Config{DeviceLocation: "Test Station"},
+		DeviceID: []byte{0xab, 0xcd},
+		Sensors: []Sensor{
+			&fakeSensor{name: "first", temperature: 10, windDir: "N"},
+			&fakeSensor{name: "second", temperature: 20},
+		},
+	}
+
+	// Sensors are read in order, so a later driver overrides an earlier one.
+	data, err := client.readSensors()
+	if err != nil {
+		t.Fatalf("readSensors: %v", err)
+	}
+	if data.DeviceID != "abcd" || data.Location != "Test Station" || data.Timestamp.IsZero() {
+		t.Errorf("reading is from %q at %q at %v, want abcd at Test Station now", data.DeviceID, data.Location, data.Timestamp)
+	}
+	if data.Temperature != 20 {
+		t.Errorf("temperature = %v, want the last sensor's 20", data.Temperature)
+	}
+
+	client.Sensors = append(client.Sensors, &fakeSensor{name: "flaky", err: errors.New("checksum mismatch")})
+	if _, err := client.readSensors(); err == nil || !strings.Contains(err.Error(), "sensor flaky: checksum mismatch") {
+		t.Errorf("readSensors error = %v, want the failing sensor's", err)
+	}
+}
+
+func TestMockSensor(t *testing.T) {
+	sensors, err := OpenSensors("mock")
+	if err != nil {
+		t.Fatalf("OpenSensors: %v", err)
+	}
+	defer CloseSensors(sensors)
+
+	for i := 0; i < 100; i++ {
+		var data WeatherData
+		if err := sensors[0].Read(&data); err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if data.Temperature < 10 || data.Temperature > 30 || data.Humidity < 35 || data.Humidity > 75 ||
+			data.Pressure < 1000 || data.Pressure > 1026 || data.WindSpeed < 0 || data.WindDir == "" {
+			t.Fatalf("implausible mock reading %+v", data)
+		}
+	}
+}
+
+func TestOpenSensorsRequiresMeasurements(t *testing.T) {
+	// The recorded dump is from a BME280. Changing its chip ID register makes
+	// it read as a BMP280, which has no humidity sensor.
+	registerTestSensor(t, "bmp280-dump", func(options url.Values) (Sensor, error) {
+		bus, err := NewDumpBus(bme280Dump)
+		if err != nil {
+			return nil, err
+		}
+		bus.WriteReg(bme280RegChipID, bmp280ChipID)
+		return NewBME280(bus)
+	})
+
+	registerTestSensor(t, "cup-counter", func(options url.Values) (Sensor, error) {
+		return NewCupAnemometer(&fakePulseCounter{}, 2.4), nil
+	})
+
+	bme280 := "bme280?dump=" + bme280Dump
+	wind := "nmea?file=testdata/wind.nmea"
+	tests := []struct {
+		spec    string
+		missing string
+	}{
+		{bme280, "wind_speed, wind_direction"},
+		{"bmp280-dump," + wind, "humidity"},
+		{bme280 + "," + wind, ""},
+		{"bmp280-dump," + wind + ",mock", ""},
+		{bme280 + ",cup-counter", "wind_direction"},
+	}
+
+	for _, tt := range tests {
+		sensors, err := OpenSensors(tt.spec)
+		if tt.missing == "" {
+			if err != nil {
+				t.Errorf("OpenSensors(%q): %v", tt.spec, err)
+			}
+			CloseSensors(sensors)
+			continue
+		}
+		if err == nil || !strings.HasSuffix(err.Error(), "no configured sensor measures "+tt.missing) {
+			t.Errorf("OpenSensors(%q) error = %v, want %s missing", tt.spec, err, tt.missing)
+		}
+	}
+}
+
+// fakePulseCounter counts the pulses it is given.
+type fakePulseCounter struct {
+	pulses uint64
+}
+
+func (c *fakePulseCounter) Pulses() uint64 { return atomic.LoadUint64(&c.pulses) }
+func (c *fakePulseCounter) Close() error   { return nil }
+
+func TestCupAnemometer(t *testing.T) {
+	counter := &fakePulseCounter{pulses: 1000}
+	cup := NewCupAnemometer(counter, 2.4)
+
+	time.Sleep(100 * time.Millisecond)
+	atomic.AddUint64(&counter.pulses, 50)
+
+	var data WeatherData
+	if err := cup.Read(&data); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// 50 pulses in just over 0.1 s is just under 500 Hz, or 1200 km/h.
+	if data.WindSpeed <= 1000 || data.WindSpeed > 1200 {
+		t.Errorf("WindSpeed = %v, want just under 1200", data.WindSpeed)
+	}
+	if data.WindDir != "" {
+		t.Errorf("WindDir = %q, want it left to another driver", data.WindDir)
+	}
+}
+
+func TestReadSensorsWithoutDirection(t *testing.T) {
+	// Only a relative angle arrives, and without a heading the anemometer
+	// cannot turn it into a bearing.
+	nmea := NewNMEAWindSensor(io.NopCloser(strings.NewReader("$WIMWV,180.0,R,36.0,K,A*1A\n")), 0, nil)
+	<-nmea.done
+
+	client := &WeatherClient{
+		Config:  &Config{},
+		Sensors: []Sensor{&fakeSensor{name: "thermometer", temperature: 20}, nmea},
+	}
+	if _, err := client.readSensors(); err == nil || !strings.Contains(err.Error(), "no sensor reported a wind direction") {
+		t.Errorf("readSensors error = %v, want the missing direction reported", err)
+	}
+
+	// An earlier driver's direction is kept.
+	client.Sensors[0] = &fakeSensor{name: "vane", temperature: 20, windDir: "SW"}
+	nmea.average.add(windSample{Speed: 10})
+	data, err := client.readSensors()
+	if err != nil {
+		t.Fatalf("readSensors: %v", err)
+	}
+	if data.WindDir != "SW" || data.WindSpeed != 10 {
+		t.Errorf("reading has %v km/h from %s, want 10 from SW", data.WindSpeed, data.WindDir)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+}
+
+func OpenSerialPort(path string, baud int) (*os.File, error) {
+	rate, ok := baudRates[baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baud)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+
+	// Raw 8N1 with the receiver enabled; reads block until a byte arrives.
+	termios := syscall.Termios{
+		Cflag:  rate | syscall.CS8 | syscall.CREAD | syscall.CLOCAL,
+		Ispeed: rate,
+		Ospeed: rate,
+	}
+	termios.Cc[syscall.VMIN] = 1
+
+	if err := ioctl(file, syscall.TCSETS, uintptr(unsafe.Pointer(&termios))); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to configure %s: %v", path, err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func OpenSerialPort(path string, baud int) (*os.File, error) {
+	return nil, fmt.Errorf("serial ports are only supported on Linux")
+}
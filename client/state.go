@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type DeviceState struct {
+	DeviceID string `json:"device_id"`
+	Sequence uint64 `json:"sequence"`
+}
+
+func (c *WeatherClient) nextSequence() (uint64, error) {
+	state, err := c.loadState()
+	if err != nil {
+		return 0, err
+	}
+
+	state.Sequence++
+	if err := c.saveState(state); err != nil {
+		return 0, fmt.Errorf("failed to persist sequence number: %v", err)
+	}
+	return state.Sequence, nil
+}
+
+func (c *WeatherClient) advanceSequence(sequence uint64) error {
+	state, err := c.loadState()
+	if err != nil {
+		return err
+	}
+	if sequence <= state.Sequence {
+		return nil
+	}
+
+	state.Sequence = sequence
+	return c.saveState(state)
+}
+
+func (c *WeatherClient) loadState() (*DeviceState, error) {
+	deviceID := hex.EncodeToString(c.DeviceID)
+
+	data, err := os.ReadFile(c.Config.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &DeviceState{DeviceID: deviceID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device state: %v", err)
+	}
+
+	var state DeviceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse device state: %v", err)
+	}
+
+	if state.DeviceID != deviceID {
+		return &DeviceState{DeviceID: deviceID}, nil
+	}
+	return &state, nil
+}
+
+func (c *WeatherClient) saveState(state *DeviceState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := c.Config.StatePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, c.Config.StatePath)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -11,21 +12,109 @@ import (
 	"time"
 )
 
-func (c *WeatherClient) generateMockWeatherData() WeatherData {
-	rand.Seed(time.Now().UnixNano())
+type BackendError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("backend returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Permanent reports whether resending the same payload can never succeed.
+func (e *BackendError) Permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+func (c *WeatherClient) FlushOutbox() error {
+	if time.Now().Before(c.retryAt) {
+		return fmt.Errorf("backend unavailable, retrying in %s", time.Until(c.retryAt).Round(time.Second))
+	}
+
+	pending, err := c.Outbox.Pending()
+	if err != nil {
+		return err
+	}
+
+	batchSize := c.Config.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	rejected := 0
+	for start := 0; start < len(pending); start += batchSize {
+		names := pending[start:min(start+batchSize, len(pending))]
 
-	windDirections := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+		var loaded []string
+		var payloads []SubmissionPayload
+		for _, name := range names {
+			payload, err := c.Outbox.Load(name)
+			if err != nil {
+				fmt.Printf("Dropping unreadable queued reading %s: %v\n", name, err)
+				if err := c.Outbox.Remove(name); err != nil {
+					return err
+				}
+				continue
+			}
+			loaded = append(loaded, name)
+			payloads = append(payloads, payload)
+		}
+		if len(payloads) == 0 {
+			continue
+		}
+
+		// Queued readings always go through the batch endpoint, even one at a
+		// time, because /submit rejects anything older than the live window.
+		results, err := c.sendBatchToBackend(payloads)
+		if err != nil {
+			results = []error{err}
+		}
+
+		for i, err := range results {
+			var backendErr *BackendError
+			if err != nil && !(errors.As(err, &backendErr) && backendErr.Permanent()) {
+				c.scheduleRetry()
+				return fmt.Errorf("%v (%d readings queued, retrying in %s)", err, len(pending)-start-i, time.Until(c.retryAt).Round(time.Second))
+			}
+			if err != nil {
+				fmt.Printf("Backend rejected reading %d, dropping it: %v\n", payloads[i].WeatherData.Sequence, err)
+				rejected++
+			}
+
+			if err := c.Outbox.Remove(loaded[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.retryFailures = 0
+	if rejected > 0 {
+		return fmt.Errorf("backend rejected %d of %d readings", rejected, len(pending))
+	}
+	return nil
+}
+
+// scheduleRetry applies exponential backoff with full jitter so a fleet of
+// stations does not reconnect in lockstep after an outage.
+func (c *WeatherClient) scheduleRetry() {
+	base := time.Duration(c.Config.RetryBaseDelay) * time.Second
+	maxDelay := time.Duration(c.Config.RetryMaxDelay) * time.Second
+
+	delay := maxDelay
+	if c.retryFailures < 32 && base<<c.retryFailures < maxDelay {
+		delay = base << c.retryFailures
+	}
+	c.retryFailures++
+
+	c.retryAt = time.Now().Add(time.Duration(rand.Int63n(int64(delay) + 1)))
+}
 
-	return WeatherData{
-		DeviceID:    hex.EncodeToString(c.DeviceID),
-		Location:    c.Config.DeviceLocation,
-		Temperature: 15.0 + rand.Float64()*20.0,
-		Humidity:    30.0 + rand.Float64()*40.0,
-		Pressure:    980.0 + rand.Float64()*40.0,
-		WindSpeed:   rand.Float64() * 30.0,
-		WindDir:     windDirections[rand.Intn(len(windDirections))],
-		Timestamp:   time.Now(),
+func (c *WeatherClient) RetryIn() (time.Duration, bool) {
+	if c.retryAt.IsZero() || c.retryFailures == 0 {
+		return 0, false
 	}
+	return time.Until(c.retryAt), true
 }
 
 func (c *WeatherClient) sendToBackend(payload SubmissionPayload) error {
@@ -34,7 +123,7 @@ func (c *WeatherClient) sendToBackend(payload SubmissionPayload) error {
 		return fmt.Errorf("failed to marshal payload: %v", err)
 	}
 
-	resp, err := http.Post(c.Config.BackendURL+"/submit", "application/json", bytes.NewBuffer(payloadBytes))
+	resp, err := c.HTTP.Post(c.Config.BackendURL+"/submit", "application/json", bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to send request: %v", err)
 	}
@@ -42,7 +131,10 @@ func (c *WeatherClient) sendToBackend(payload SubmissionPayload) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("backend returned status %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode == http.StatusConflict && payload.WeatherData.DeviceID == hex.EncodeToString(c.DeviceID) {
+			c.resyncSequence(body)
+		}
+		return &BackendError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response map[string]interface{}
@@ -54,3 +146,69 @@ func (c *WeatherClient) sendToBackend(payload SubmissionPayload) error {
 	fmt.Printf("Backend response: %v\n", response)
 	return nil
 }
+
+// sendBatchToBackend delivers a backlog through the batch endpoint and returns
+// one result per payload, in order. An error is returned only when the batch as
+// a whole was not processed.
+func (c *WeatherClient) sendBatchToBackend(payloads []SubmissionPayload) ([]error, error) {
+	payloadBytes, err := json.Marshal(payloads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %v", err)
+	}
+
+	resp, err := c.HTTP.Post(c.Config.BackendURL+"/submit/batch", "application/json", bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send batch: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("backend returned status %d for batch: %s", resp.StatusCode, body)
+	}
+
+	var response struct {
+		Results  []json.RawMessage `json:"results"`
+		Accepted int               `json:"accepted"`
+		Rejected int               `json:"rejected"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode batch response: %v", err)
+	}
+	if len(response.Results) != len(payloads) {
+		return nil, fmt.Errorf("batch response has %d results for %d readings", len(response.Results), len(payloads))
+	}
+
+	results := make([]error, len(payloads))
+	for i, raw := range response.Results {
+		var result struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode batch result: %v", err)
+		}
+		if result.Code == http.StatusOK {
+			continue
+		}
+		if result.Code == http.StatusConflict {
+			c.resyncSequence(raw)
+		}
+		results[i] = &BackendError{StatusCode: result.Code, Body: string(raw)}
+	}
+
+	fmt.Printf("Backend accepted %d of %d queued readings\n", response.Accepted, len(payloads))
+	return results, nil
+}
+
+func (c *WeatherClient) resyncSequence(body []byte) {
+	var conflict struct {
+		LastSequence uint64 `json:"last_sequence"`
+	}
+	if err := json.Unmarshal(body, &conflict); err != nil || conflict.LastSequence == 0 {
+		return
+	}
+
+	if err := c.advanceSequence(conflict.LastSequence); err != nil {
+		fmt.Printf("Failed to resync sequence number: %v\n", err)
+	}
+}
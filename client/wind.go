@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	knotsToKmh = 1.852
+	msToKmh    = 3.6
+	mphToKmh   = 1.609344
+)
+
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+func compassPoint(degrees float64) string {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return compassPoints[int((degrees+22.5)/45)%len(compassPoints)]
+}
+
+// windAverager accumulates samples between reads so a submission reports the
+// mean speed and the vector-mean direction over the whole interval.
+type windAverager struct {
+	speedSum   float64
+	sinSum     float64
+	cosSum     float64
+	samples    int
+	hasBearing bool
+	last       windSample
+	lastAt     time.Time
+	mu         sync.Mutex
+}
+
+type windSample struct {
+	Speed      float64
+	Bearing    float64
+	HasBearing bool
+	Relative   bool
+}
+
+func (a *windAverager) add(sample windSample) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.speedSum += sample.Speed
+	if sample.HasBearing {
+		radians := sample.Bearing * math.Pi / 180
+		a.sinSum += math.Sin(radians)
+		a.cosSum += math.Cos(radians)
+		a.hasBearing = true
+	}
+	a.samples++
+	a.last = sample
+	a.lastAt = time.Now()
+}
+
+func (a *windAverager) take(maxAge time.Duration) (windSample, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.samples == 0 {
+		if a.lastAt.IsZero() || (maxAge > 0 && time.Since(a.lastAt) > maxAge) {
+			return windSample{}, false
+		}
+		return a.last, true
+	}
+
+	mean := windSample{Speed: a.speedSum / float64(a.samples), HasBearing: a.hasBearing}
+	if a.hasBearing {
+		mean.Bearing = math.Mod(math.Atan2(a.sinSum, a.cosSum)*180/math.Pi+360, 360)
+	}
+
+	a.last = mean
+	a.speedSum, a.sinSum, a.cosSum, a.samples, a.hasBearing = 0, 0, 0, 0, false
+	return mean, true
+}
@@ -0,0 +1,3 @@
+module weather-protocol
+
+go 1.24.3
@@ -0,0 +1,197 @@
+// Package protocol defines the canonical encoding that weather stations sign
+// and the backend verifies. See README.md for the byte-level specification.
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+const (
+	SigVersionV1 = 1
+	// SigVersionV2 adds the optional measurements. Readings without any of
+	// them are still signed with v1 so older backends keep accepting them.
+	SigVersionV2 = 2
+)
+
+const SignatureSize = 64
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported signature version")
+	ErrInvalidSignature   = errors.New("signature must be 64 bytes (r || s)")
+	ErrOptionalFieldsV1   = errors.New("optional measurements require signature version 2")
+)
+
+type WeatherData struct {
+	DeviceID    string    `json:"device_id"`
+	Location    string    `json:"location"`
+	Temperature float64   `json:"temperature"`
+	Humidity    float64   `json:"humidity"`
+	Pressure    float64   `json:"pressure"`
+	WindSpeed   float64   `json:"wind_speed"`
+	WindDir     string    `json:"wind_direction"`
+	Timestamp   time.Time `json:"timestamp"`
+	Sequence    uint64    `json:"sequence"`
+
+	RainRate         *float64 `json:"rain_rate,omitempty"`
+	RainAccumulation *float64 `json:"rain_accumulation,omitempty"`
+	SolarRadiation   *float64 `json:"solar_radiation,omitempty"`
+	UVIndex          *float64 `json:"uv_index,omitempty"`
+	PM25             *float64 `json:"pm2_5,omitempty"`
+	PM10             *float64 `json:"pm10,omitempty"`
+	DewPoint         *float64 `json:"dew_point,omitempty"`
+	WindGust         *float64 `json:"wind_gust,omitempty"`
+	WindBearing      *float64 `json:"wind_bearing,omitempty"`
+	SnowDepth        *float64 `json:"snow_depth,omitempty"`
+	SoilMoisture     *float64 `json:"soil_moisture,omitempty"`
+}
+
+// OptionalField describes one of the optional measurements. ID is its tag in
+// the v2 encoding and must never be reused.
+type OptionalField struct {
+	ID   byte
+	Name string
+	Unit string
+	Get  func(*WeatherData) *float64
+}
+
+// OptionalFields lists the optional measurements in ascending ID order.
+var OptionalFields = []OptionalField{
+	{1, "rain_rate", "mm/h", func(d *WeatherData) *float64 { return d.RainRate }},
+	{2, "rain_accumulation", "mm", func(d *WeatherData) *float64 { return d.RainAccumulation }},
+	{3, "solar_radiation", "W/m²", func(d *WeatherData) *float64 { return d.SolarRadiation }},
+	{4, "uv_index", "", func(d *WeatherData) *float64 { return d.UVIndex }},
+	{5, "pm2_5", "µg/m³", func(d *WeatherData) *float64 { return d.PM25 }},
+	{6, "pm10", "µg/m³", func(d *WeatherData) *float64 { return d.PM10 }},
+	{7, "dew_point", "°C", func(d *WeatherData) *float64 { return d.DewPoint }},
+	{8, "wind_gust", "km/h", func(d *WeatherData) *float64 { return d.WindGust }},
+	{9, "wind_bearing", "°", func(d *WeatherData) *float64 { return d.WindBearing }},
+	{10, "snow_depth", "cm", func(d *WeatherData) *float64 { return d.SnowDepth }},
+	{11, "soil_moisture", "%", func(d *WeatherData) *float64 { return d.SoilMoisture }},
+}
+
+// HasOptionalFields reports whether any optional measurement is set.
+func (d *WeatherData) HasOptionalFields() bool {
+	for _, field := range OptionalFields {
+		if field.Get(d) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionFor returns the oldest signature version that can encode data.
+func VersionFor(data WeatherData) int {
+	if data.HasOptionalFields() {
+		return SigVersionV2
+	}
+	return SigVersionV1
+}
+
+func Encode(data WeatherData, version int) ([]byte, error) {
+	switch version {
+	case SigVersionV1:
+		if data.HasOptionalFields() {
+			return nil, ErrOptionalFieldsV1
+		}
+	case SigVersionV2:
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	e := &encoder{buf: []byte{byte(version)}}
+	e.string("device_id", data.DeviceID)
+	e.string("location", data.Location)
+	e.float("temperature", data.Temperature)
+	e.float("humidity", data.Humidity)
+	e.float("pressure", data.Pressure)
+	e.float("wind_speed", data.WindSpeed)
+	e.string("wind_direction", data.WindDir)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(data.Timestamp.UnixMilli()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, data.Sequence)
+
+	if version >= SigVersionV2 {
+		e.optional(&data)
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+func Digest(data WeatherData, version int) ([32]byte, error) {
+	encoded, err := Encode(data, version)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(encoded), nil
+}
+
+func EncodeSignature(r, s *big.Int) []byte {
+	signature := make([]byte, SignatureSize)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature
+}
+
+func DecodeSignature(signature []byte) (r, s *big.Int, err error) {
+	if len(signature) != SignatureSize {
+		return nil, nil, ErrInvalidSignature
+	}
+	return new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]), nil
+}
+
+type encoder struct {
+	buf []byte
+	err error
+}
+
+func (e *encoder) string(field, value string) {
+	if len(value) > math.MaxUint16 {
+		e.fail(fmt.Errorf("%s is longer than %d bytes", field, math.MaxUint16))
+		return
+	}
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(len(value)))
+	e.buf = append(e.buf, value...)
+}
+
+func (e *encoder) float(field string, value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		e.fail(fmt.Errorf("%s must be a finite number", field))
+		return
+	}
+	if value == 0 {
+		// Fold -0 into +0 so equal readings always encode identically.
+		value = 0
+	}
+	e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(value))
+}
+
+// optional writes the number of optional measurements present, then each one
+// as its ID byte followed by its value.
+func (e *encoder) optional(data *WeatherData) {
+	countAt := len(e.buf)
+	e.buf = append(e.buf, 0)
+
+	for _, field := range OptionalFields {
+		value := field.Get(data)
+		if value == nil {
+			continue
+		}
+		e.buf[countAt]++
+		e.buf = append(e.buf, field.ID)
+		e.float(field.Name, *value)
+	}
+}
+
+func (e *encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
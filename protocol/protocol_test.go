@@ -0,0 +1,170 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// goldenVector is one entry of testdata/golden.json, the vectors published in
+// README.md. The client and backend tests read the same file.
+type goldenVector struct {
+	Name        string          `json:"name"`
+	SigVersion  int             `json:"sig_version"`
+	WeatherData json.RawMessage `json:"weather_data"`
+	Encoding    string          `json:"encoding"`
+	Digest      string          `json:"digest"`
+}
+
+func loadGoldenVectors(t *testing.T) []goldenVector {
+	t.Helper()
+
+	raw, err := os.ReadFile("testdata/golden.json")
+	if err != nil {
+		t.Fatalf("failed to read golden vectors: %v", err)
+	}
+
+	var vectors []goldenVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatalf("failed to parse golden vectors: %v", err)
+	}
+	return vectors
+}
+
+func (v goldenVector) data(t *testing.T) WeatherData {
+	t.Helper()
+
+	var data WeatherData
+	if err := json.Unmarshal(v.WeatherData, &data); err != nil {
+		t.Fatalf("%s: failed to parse weather_data: %v", v.Name, err)
+	}
+	return data
+}
+
+func TestGoldenVectors(t *testing.T) {
+	for _, vector := range loadGoldenVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			data := vector.data(t)
+
+			if version := VersionFor(data); version != vector.SigVersion {
+				t.Errorf("VersionFor = %d, want %d", version, vector.SigVersion)
+			}
+
+			encoded, err := Encode(data, vector.SigVersion)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if got := hex.EncodeToString(encoded); got != vector.Encoding {
+				t.Errorf("Encode =\n%s\nwant\n%s", got, vector.Encoding)
+			}
+
+			digest, err := Digest(data, vector.SigVersion)
+			if err != nil {
+				t.Fatalf("Digest: %v", err)
+			}
+			if got := hex.EncodeToString(digest[:]); got != vector.Digest {
+				t.Errorf("Digest = %s, want %s", got, vector.Digest)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsVersions(t *testing.T) {
+	vectors := loadGoldenVectors(t)
+	v1, v2 := vectors[0].data(t), vectors[1].data(t)
+
+	if _, err := Encode(v2, SigVersionV1); !errors.Is(err, ErrOptionalFieldsV1) {
+		t.Errorf("Encode(v2 reading, v1) error = %v, want ErrOptionalFieldsV1", err)
+	}
+	for _, version := range []int{0, 3} {
+		if _, err := Encode(v1, version); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("Encode(version %d) error = %v, want ErrUnsupportedVersion", version, err)
+		}
+	}
+
+	// A v2 encoding of a reading without optional fields is valid, but it is
+	// not the v1 encoding.
+	encoded, err := Encode(v1, SigVersionV2)
+	if err != nil {
+		t.Fatalf("Encode(v1 reading, v2): %v", err)
+	}
+	if encoded[0] != SigVersionV2 || encoded[len(encoded)-1] != 0 {
+		t.Errorf("Encode(v1 reading, v2) = %x, want version 2 with no optional fields", encoded)
+	}
+}
+
+func TestEncodeNormalizes(t *testing.T) {
+	data := loadGoldenVectors(t)[0].data(t)
+	want, err := Encode(data, SigVersionV1)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	// Sub-millisecond digits are ignored.
+	shifted := data
+	shifted.Timestamp = data.Timestamp.Add(999 * time.Microsecond)
+	if got, _ := Encode(shifted, SigVersionV1); !bytes.Equal(got, want) {
+		t.Error("sub-millisecond timestamp digits changed the encoding")
+	}
+
+	// -0 encodes as +0.
+	zero, negativeZero := data, data
+	zero.WindSpeed = 0
+	negativeZero.WindSpeed = math.Copysign(0, -1)
+	a, _ := Encode(zero, SigVersionV1)
+	b, _ := Encode(negativeZero, SigVersionV1)
+	if !bytes.Equal(a, b) {
+		t.Error("-0 and +0 encode differently")
+	}
+}
+
+func TestEncodeRejectsUnencodable(t *testing.T) {
+	data := loadGoldenVectors(t)[1].data(t)
+
+	tests := []struct {
+		name   string
+		mutate func(d *WeatherData)
+	}{
+		{"NaN temperature", func(d *WeatherData) { d.Temperature = math.NaN() }},
+		{"infinite pressure", func(d *WeatherData) { d.Pressure = math.Inf(1) }},
+		{"NaN optional field", func(d *WeatherData) { nan := math.NaN(); d.RainRate = &nan }},
+		{"oversized location", func(d *WeatherData) { d.Location = string(make([]byte, math.MaxUint16+1)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutated := data
+			tt.mutate(&mutated)
+			if _, err := Encode(mutated, SigVersionV2); err == nil {
+				t.Error("Encode succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestSignatureEncoding(t *testing.T) {
+	r, s := big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 255)
+
+	signature := EncodeSignature(r, s)
+	if len(signature) != SignatureSize || signature[31] != 1 || signature[32] != 0x80 {
+		t.Fatalf("EncodeSignature = %x, want r and s left-padded to 32 bytes", signature)
+	}
+
+	gotR, gotS, err := DecodeSignature(signature)
+	if err != nil {
+		t.Fatalf("DecodeSignature: %v", err)
+	}
+	if gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 {
+		t.Errorf("DecodeSignature = (%v, %v), want (%v, %v)", gotR, gotS, r, s)
+	}
+
+	if _, _, err := DecodeSignature(signature[:63]); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("DecodeSignature(63 bytes) error = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const RotationVersionV1 = 1
+
+// rotationDomain starts every rotation encoding. Its leading length byte is
+// 0x00, which is never a valid weather data version, so a rotation signature
+// can never be replayed as a reading signature or the other way round.
+const rotationDomain = "weather-key-rotation"
+
+// KeyRotation asks the backend to re-bind DeviceID to NewPublicKey. It is
+// signed by both the current key and the new key.
+type KeyRotation struct {
+	DeviceID     string    `json:"device_id"`
+	NewPublicKey string    `json:"new_public_key"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func EncodeRotation(rotation KeyRotation, version int) ([]byte, error) {
+	if version != RotationVersionV1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	e := &encoder{}
+	e.string("domain", rotationDomain)
+	e.buf = append(e.buf, byte(version))
+	e.string("device_id", rotation.DeviceID)
+	e.string("new_public_key", rotation.NewPublicKey)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(rotation.Timestamp.UnixMilli()))
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+func RotationDigest(rotation KeyRotation, version int) ([32]byte, error) {
+	encoded, err := EncodeRotation(rotation, version)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(encoded), nil
+}
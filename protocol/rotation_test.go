@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// rotationVector is one entry of testdata/rotation.json, the vector published
+// in README.md.
+type rotationVector struct {
+	Name     string      `json:"name"`
+	Version  int         `json:"version"`
+	Rotation KeyRotation `json:"rotation"`
+	Encoding string      `json:"encoding"`
+	Digest   string      `json:"digest"`
+}
+
+func loadRotationVectors(t *testing.T) []rotationVector {
+	t.Helper()
+
+	raw, err := os.ReadFile("testdata/rotation.json")
+	if err != nil {
+		t.Fatalf("failed to read rotation vectors: %v", err)
+	}
+
+	var vectors []rotationVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatalf("failed to parse rotation vectors: %v", err)
+	}
+	return vectors
+}
+
+func TestRotationGoldenVectors(t *testing.T) {
+	for _, vector := range loadRotationVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			encoded, err := EncodeRotation(vector.Rotation, vector.Version)
+			if err != nil {
+				t.Fatalf("EncodeRotation: %v", err)
+			}
+			if got := hex.EncodeToString(encoded); got != vector.Encoding {
+				t.Errorf("EncodeRotation =\n%s\nwant\n%s", got, vector.Encoding)
+			}
+
+			digest, err := RotationDigest(vector.Rotation, vector.Version)
+			if err != nil {
+				t.Fatalf("RotationDigest: %v", err)
+			}
+			if got := hex.EncodeToString(digest[:]); got != vector.Digest {
+				t.Errorf("RotationDigest = %s, want %s", got, vector.Digest)
+			}
+		})
+	}
+}
+
+func TestEncodeRotationRejectsVersions(t *testing.T) {
+	rotation := loadRotationVectors(t)[0].Rotation
+	for _, version := range []int{0, 2} {
+		if _, err := EncodeRotation(rotation, version); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("EncodeRotation(version %d) error = %v, want ErrUnsupportedVersion", version, err)
+		}
+	}
+}
+
+func TestRotationDomainSeparation(t *testing.T) {
+	rotation, err := EncodeRotation(loadRotationVectors(t)[0].Rotation, RotationVersionV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation[0] != 0 {
+		t.Fatalf("rotation encoding starts with %#x, want 0x00", rotation[0])
+	}
+
+	// Every reading encoding starts with its version, which is never 0.
+	for _, vector := range loadGoldenVectors(t) {
+		encoded, err := Encode(vector.data(t), vector.SigVersion)
+		if err != nil {
+			t.Fatalf("%s: Encode: %v", vector.Name, err)
+		}
+		if encoded[0] == rotation[0] {
+			t.Errorf("%s: reading encoding starts with %#x like a rotation", vector.Name, encoded[0])
+		}
+	}
+}